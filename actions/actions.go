@@ -7,6 +7,7 @@ package actions
 
 import (
 	"math/rand"
+	"strings"
 
 	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel"
 	"github.com/hyperledger/fabric-sdk-go/pkg/client/event"
@@ -19,6 +20,8 @@ import (
 	contextImpl "github.com/hyperledger/fabric-sdk-go/pkg/context"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/config"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fab/orderer"
+	peerImpl "github.com/hyperledger/fabric-sdk-go/pkg/fab/peer"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/txn"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fabsdk"
 	"github.com/pkg/errors"
 	"github.com/zhcppy/fabricli/logger"
@@ -98,6 +101,37 @@ func (action *Action) GetPeers() (peers map[string]fab.Peer) {
 	return
 }
 
+// FilterPeers returns the peers grouped by org ID, only the peers that belong to one of the
+// given orgs and match one of the given URLs (or peer config keys) are returned.
+// An empty orgIDs or peerURLs list matches all
+func (action *Action) FilterPeers(orgIDs, peerURLs []string) (map[string][]fab.Peer, error) {
+	peersByOrg := map[string][]fab.Peer{}
+	for orgID, peers := range action.PeersByOrg {
+		if len(orgIDs) > 0 && !containsFold(orgIDs, orgID) {
+			continue
+		}
+		for key, peer := range peers {
+			if len(peerURLs) > 0 && !containsFold(peerURLs, key) && !containsFold(peerURLs, peer.URL()) {
+				continue
+			}
+			peersByOrg[orgID] = append(peersByOrg[orgID], peer)
+		}
+	}
+	if len(peersByOrg) == 0 {
+		return nil, errors.Errorf("no peers found for orgs %v and peers %v", orgIDs, peerURLs)
+	}
+	return peersByOrg, nil
+}
+
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
+
 func (action *Action) FilterOrderers(ordererURLs ...string) (err error) {
 	ordererConfigs := action.client.EndpointConfig().OrderersConfig()
 
@@ -292,6 +326,76 @@ func (action *Action) ResourceMgmtClient(user mspImpl.SigningIdentity) (*resmgmt
 	return c, nil
 }
 
+// ====== Proposal ====== //
+
+// SendProposal signs the chaincode invoke request as the user and sends it to the targets,
+// it is used to call the system chaincodes that are not covered by the SDK clients
+func (action *Action) SendProposal(channelID string, user mspImpl.SigningIdentity, request fab.ChaincodeInvokeRequest, targets []fab.Peer) ([]*fab.TransactionProposalResponse, error) {
+	client, proposal, err := action.newProposal(channelID, user, request)
+	if err != nil {
+		return nil, err
+	}
+	reqCtx, cancel := contextImpl.NewRequest(client, contextImpl.WithTimeoutType(fab.ResMgmt))
+	defer cancel()
+	return txn.SendProposal(reqCtx, proposal, peerImpl.PeersToTxnProcessors(targets))
+}
+
+// PeerProposalResponse is the response of a single peer to a proposal, or the error it returned
+type PeerProposalResponse struct {
+	Peer     fab.Peer
+	Response *fab.TransactionProposalResponse
+	Err      error
+}
+
+// SendProposalToEach signs a single proposal as the user and sends it to the targets one at a time,
+// so that the response or the error of every peer is returned, not only the first error.
+// Each peer is given the whole execute timeout, a slow peer does not use up the time of the next ones
+func (action *Action) SendProposalToEach(channelID string, user mspImpl.SigningIdentity, request fab.ChaincodeInvokeRequest, targets []fab.Peer) (fab.TransactionID, []PeerProposalResponse, error) {
+	client, proposal, err := action.newProposal(channelID, user, request)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var responses []PeerProposalResponse
+	for _, target := range targets {
+		response := PeerProposalResponse{Peer: target}
+		reqCtx, cancel := contextImpl.NewRequest(client, contextImpl.WithTimeoutType(fab.Execute))
+		resps, err := txn.SendProposal(reqCtx, proposal, peerImpl.PeersToTxnProcessors([]fab.Peer{target}))
+		cancel()
+		if err != nil {
+			response.Err = err
+		} else if len(resps) == 0 {
+			response.Err = errors.Errorf("no response from %s", target.URL())
+		} else {
+			response.Response = resps[0]
+		}
+		responses = append(responses, response)
+	}
+	return proposal.TxnID, responses, nil
+}
+
+func (action *Action) newProposal(channelID string, user mspImpl.SigningIdentity, request fab.ChaincodeInvokeRequest) (context.Client, *fab.TransactionProposal, error) {
+	logger.L().Debugf("sending proposal %s.%s for user [%s] in org [%s]...", request.ChaincodeID, request.Fcn, user.Identifier().ID, user.Identifier().MSPID)
+	cp, err := action.ClientProvider(user)
+	if err != nil {
+		return nil, nil, errors.Errorf("error getting context for user [%s,%s]: %v", user.Identifier().MSPID, user.Identifier().ID, err)
+	}
+	client, err := cp()
+	if err != nil {
+		return nil, nil, errors.Errorf("error creating client for user [%s,%s]: %v", user.Identifier().MSPID, user.Identifier().ID, err)
+	}
+
+	txh, err := txn.NewHeader(client, channelID)
+	if err != nil {
+		return nil, nil, errors.WithMessage(err, "create transaction ID failed")
+	}
+	proposal, err := txn.CreateChaincodeInvokeProposal(txh, request)
+	if err != nil {
+		return nil, nil, errors.WithMessage(err, "creating proposal failed")
+	}
+	return client, proposal, nil
+}
+
 // ====== Context Local ====== //
 
 func (action *Action) LocalContext(user mspImpl.SigningIdentity) (context.Local, error) {
@@ -6,36 +6,45 @@
 package chaincode
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"math"
 	"net/http"
+	"os"
+	"os/signal"
 	"strconv"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/hyperledger/fabric-protos-go/common"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
 	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel"
 	"github.com/hyperledger/fabric-sdk-go/pkg/client/resmgmt"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/retry"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
-	"github.com/hyperledger/fabric-sdk-go/pkg/fab/ccpackager/gopackager"
 	"github.com/pkg/errors"
 	"github.com/zhcppy/fabricli/actions"
 	"github.com/zhcppy/fabricli/api"
 	"github.com/zhcppy/fabricli/api/chaincode/cauthdsl"
+	"github.com/zhcppy/fabricli/api/chaincode/ccpackager"
 	"github.com/zhcppy/fabricli/api/chaincode/task"
 	"github.com/zhcppy/fabricli/executor"
+	"github.com/zhcppy/fabricli/executor/worker"
 	"github.com/zhcppy/fabricli/logger"
+	"github.com/zhcppy/fabricli/printer"
+	"github.com/zhcppy/fabricli/stats"
 )
 
-const concurrency = 10
-
 type CCAction struct {
 	action        *actions.Action
 	user          msp.SigningIdentity
+	username      string
+	orgID         string
 	mgmtClient    *resmgmt.Client
 	channelClient *channel.Client
 	channelId     string
@@ -47,7 +56,7 @@ func NewCCAction(c *api.Config) (*CCAction, error) {
 	if err != nil {
 		return nil, err
 	}
-	user, err := action.User(c.OrgID, c.PeerUrl, c.Username)
+	user, err := action.User(c.DefaultOrgID(), c.DefaultPeerURL(), c.Username)
 	if err != nil {
 		return nil, err
 	}
@@ -65,6 +74,8 @@ func NewCCAction(c *api.Config) (*CCAction, error) {
 	return &CCAction{
 		action:        action,
 		user:          user,
+		username:      c.Username,
+		orgID:         c.DefaultOrgID(),
 		mgmtClient:    mgmtClient,
 		channelClient: channelClient,
 		channelId:     c.ChannelID,
@@ -72,14 +83,25 @@ func NewCCAction(c *api.Config) (*CCAction, error) {
 	}, nil
 }
 
-func (cc *CCAction) Install(info api.CCodeInfo, peers ...fab.Peer) error {
-	if len(peers) <= 0 {
-		peers = cc.action.Peers
+// PeerResult is the result of a chaincode operation on a single peer
+type PeerResult struct {
+	OrgID  string
+	Peer   string
+	Status string
+	Info   string
+}
+
+// Install installs the chaincode on the peers of the given orgs, filtered by the given peer URLs.
+// Each org installs on its own peers with its own user since the peer only accepts an install from its org admin
+func (cc *CCAction) Install(info api.CCodeInfo, orgIDs, peerURLs []string) ([]PeerResult, error) {
+	peersByOrg, err := cc.action.FilterPeers(orgIDs, peerURLs)
+	if err != nil {
+		return nil, err
 	}
 
-	ccPkg, err := gopackager.NewCCPackage(info.ChaincodePath, info.GoPath)
+	ccPkg, err := ccpackager.NewCCPackage(info.ChaincodeLang, info.ChaincodePath, info.GoPath)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	req := resmgmt.InstallCCRequest{
 		Name:    info.ChaincodeID,
@@ -87,77 +109,158 @@ func (cc *CCAction) Install(info api.CCodeInfo, peers ...fab.Peer) error {
 		Version: info.ChaincodeVersion,
 		Package: ccPkg,
 	}
-	responses, err := cc.mgmtClient.InstallCC(req, resmgmt.WithTargets(peers...))
-	if err != nil {
-		return errors.Errorf("InstallChaincode returned error: %v", err)
-	}
 
 	ccIDVersion := info.ChaincodeID + "." + info.ChaincodeVersion
+	var results []PeerResult
 	var errs []error
-	for _, resp := range responses {
-		if resp.Info == "already installed" {
-			fmt.Printf("Chaincode %s already installed on peer: %s.\n", ccIDVersion, resp.Target)
-		} else if resp.Status != http.StatusOK {
-			errs = append(errs, errors.Errorf("installCC returned error from peer %s: %s", resp.Target, resp.Info))
-		} else {
-			fmt.Printf("...successfuly installed chaincode %s on peer %s.\n", ccIDVersion, resp.Target)
+	for orgID, peers := range peersByOrg {
+		logger.L().Infof("Installing chaincode %s on org [%s] peers ...", ccIDVersion, orgID)
+		mgmtClient, err := cc.orgMgmtClient(orgID)
+		if err != nil {
+			errs = append(errs, err)
+			results = append(results, failedResults(orgID, peers, err)...)
+			continue
+		}
+		responses, err := mgmtClient.InstallCC(req, resmgmt.WithTargets(peers...))
+		if err != nil {
+			errs = append(errs, errors.Errorf("InstallChaincode returned error: %v", err))
+		}
+		for _, peer := range peers {
+			result := PeerResult{OrgID: orgID, Peer: peer.URL(), Status: "failed"}
+			if err != nil {
+				result.Info = err.Error()
+			}
+			for _, resp := range responses {
+				if resp.Target != peer.URL() {
+					continue
+				}
+				if resp.Info == "already installed" {
+					result.Status, result.Info = "installed", resp.Info
+				} else if resp.Status != http.StatusOK {
+					result.Info = resp.Info
+					errs = append(errs, errors.Errorf("installCC returned error from peer %s: %s", resp.Target, resp.Info))
+				} else {
+					result.Status, result.Info = "installed", fmt.Sprintf("successfully installed %s", ccIDVersion)
+				}
+			}
+			results = append(results, result)
 		}
 	}
 
 	if len(errs) > 0 {
 		logger.L().Warnf("Errors returned from InstallCC: %v\n", errs)
-		return errs[0]
+		return results, errs[0]
 	}
-	return nil
+	return results, nil
 }
 
-func (cc *CCAction) Upgrade(ccInfo api.CCodeInfo, peers ...fab.Peer) error {
-	var args task.ArgStruct
-	if err := json.Unmarshal([]byte(ccInfo.ChaincodeArgs), &args); err != nil {
-		return errors.Errorf("Error unmarshalling JSON arg string: %v", err)
+// Upgrade upgrades the chaincode on the channel, the upgrade proposal is endorsed by the peers
+// of the given orgs filtered by the given peer URLs
+func (cc *CCAction) Upgrade(ccInfo api.CCodeInfo, orgIDs, peerURLs []string) ([]PeerResult, error) {
+	args, err := newInitArgs(ccInfo.ChaincodeArgs)
+	if err != nil {
+		return nil, err
+	}
+	ccType, err := ccpackager.ChaincodeType(ccInfo.ChaincodeLang)
+	if err != nil {
+		return nil, err
+	}
+	peersByOrg, err := cc.action.FilterPeers(orgIDs, peerURLs)
+	if err != nil {
+		return nil, err
 	}
 	logger.L().Infof("Sending upgrade %s ...\n", ccInfo.ChaincodeID)
 
 	chaincodePolicy, err := newChaincodePolicy(ccInfo.ChaincodePolicy, []string{})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Private Data Collection Configuration
 	// - see fixtures/config/pvtdatacollection.json for sample config file
 	collConfig, err := getCollectionConfigFromFile(ccInfo.CollectionConfigFile)
 	if err != nil {
-		return errors.Wrapf(err, "error getting private data collection configuration from file [%s]", ccInfo.CollectionConfigFile)
+		return nil, errors.Wrapf(err, "error getting private data collection configuration from file [%s]", ccInfo.CollectionConfigFile)
+	}
+
+	ccArgs, err := task.ArgToBytes(task.NewContext(), args.Args)
+	if err != nil {
+		return nil, err
 	}
 
 	req := resmgmt.UpgradeCCRequest{
 		Name:       ccInfo.ChaincodeID,
 		Path:       ccInfo.ChaincodePath,
 		Version:    ccInfo.ChaincodeVersion,
-		Args:       task.ArgToBytes(task.NewContext(), args.Args),
+		Args:       ccArgs,
 		Policy:     chaincodePolicy,
 		CollConfig: collConfig,
 	}
 
-	_, err = cc.mgmtClient.UpgradeCC(cc.channelId, req, resmgmt.WithTargets(peers...))
+	var peers []fab.Peer
+	for _, orgPeers := range peersByOrg {
+		peers = append(peers, orgPeers...)
+	}
+	var txID fab.TransactionID
+	if ccType == pb.ChaincodeSpec_GOLANG {
+		var resp resmgmt.UpgradeCCResponse
+		resp, err = cc.mgmtClient.UpgradeCC(cc.channelId, req, resmgmt.WithTargets(peers...))
+		txID = resp.TransactionID
+	} else {
+		txID, err = cc.deployLSCC(lsccUpgrade, ccType, resmgmt.InstantiateCCRequest(req), peers)
+	}
 	if err != nil {
-		if strings.Contains(err.Error(), "chaincode exists "+ccInfo.ChaincodeID) {
-			// Ignore
-			logger.L().Infof("Chaincode %s already instantiated.", ccInfo.ChaincodeID)
-			fmt.Printf("...chaincode %s already instantiated.\n", ccInfo.ChaincodeID)
-			return nil
+		err = errors.Errorf("error upgrading chaincode: %v", err)
+		var results []PeerResult
+		for orgID, orgPeers := range peersByOrg {
+			results = append(results, failedResults(orgID, orgPeers, err)...)
 		}
-		return errors.Errorf("error instantiating chaincode: %v", err)
+		return results, err
 	}
 
-	fmt.Printf("...successfuly upgraded chaincode %s on channel %s.\n", ccInfo.ChaincodeID, cc.channelId)
-	return nil
+	var results []PeerResult
+	for orgID, orgPeers := range peersByOrg {
+		for _, peer := range orgPeers {
+			results = append(results, PeerResult{OrgID: orgID, Peer: peer.URL(), Status: "upgraded", Info: string(txID)})
+		}
+	}
+	fmt.Printf("...successfuly upgraded chaincode %s to %s on channel %s.\n", ccInfo.ChaincodeID, ccInfo.ChaincodeVersion, cc.channelId)
+	return results, nil
+}
+
+func (cc *CCAction) orgMgmtClient(orgID string) (*resmgmt.Client, error) {
+	user, err := cc.action.UserByOrg(orgID, cc.username)
+	if err != nil {
+		return nil, err
+	}
+	return cc.action.ResourceMgmtClient(user)
+}
+
+func failedResults(orgID string, peers []fab.Peer, err error) (results []PeerResult) {
+	for _, peer := range peers {
+		results = append(results, PeerResult{OrgID: orgID, Peer: peer.URL(), Status: "failed", Info: err.Error()})
+	}
+	return results
+}
+
+func newInitArgs(args string) (argStruct task.ArgStruct, err error) {
+	if args == "" {
+		return argStruct, nil
+	}
+	if err = json.Unmarshal([]byte(args), &argStruct); err != nil {
+		return argStruct, errors.Errorf("Error unmarshalling JSON arg string: %v", err)
+	}
+	return argStruct, nil
 }
 
 func (cc *CCAction) Instantiate(ccInfo api.CCodeInfo, peers ...fab.Peer) error {
-	var args task.ArgStruct
-	if err := json.Unmarshal([]byte(ccInfo.ChaincodeArgs), args); err != nil {
-		return errors.Errorf("Error unmarshalling JSON arg string: %v", err)
+	args, err := newInitArgs(ccInfo.ChaincodeArgs)
+	if err != nil {
+		return err
+	}
+	ccType, err := ccpackager.ChaincodeType(ccInfo.ChaincodeLang)
+	if err != nil {
+		return err
 	}
 	logger.L().Infof("Sending instantiate %s ...\n", ccInfo.ChaincodeID)
 
@@ -173,16 +276,25 @@ func (cc *CCAction) Instantiate(ccInfo api.CCodeInfo, peers ...fab.Peer) error {
 		return errors.Wrapf(err, "error getting private data collection configuration from file [%s]", ccInfo.CollectionConfigFile)
 	}
 
+	ccArgs, err := task.ArgToBytes(task.NewContext(), args.Args)
+	if err != nil {
+		return err
+	}
+
 	req := resmgmt.InstantiateCCRequest{
 		Name:       ccInfo.ChaincodeID,
 		Path:       ccInfo.ChaincodePath,
 		Version:    ccInfo.ChaincodeVersion,
-		Args:       task.ArgToBytes(task.NewContext(), args.Args),
+		Args:       ccArgs,
 		Policy:     chaincodePolicy,
 		CollConfig: collConfig,
 	}
 
-	_, err = cc.mgmtClient.InstantiateCC(cc.channelId, req, resmgmt.WithTargets(peers...))
+	if ccType == pb.ChaincodeSpec_GOLANG {
+		_, err = cc.mgmtClient.InstantiateCC(cc.channelId, req, resmgmt.WithTargets(peers...))
+	} else {
+		_, err = cc.deployLSCC(lsccDeploy, ccType, req, peers)
+	}
 	if err != nil {
 		if strings.Contains(err.Error(), "chaincode exists "+ccInfo.ChaincodeID) {
 			// Ignore
@@ -197,140 +309,359 @@ func (cc *CCAction) Instantiate(ccInfo api.CCodeInfo, peers ...fab.Peer) error {
 	return nil
 }
 
-func (cc *CCAction) QueryInfo(chaincodeID string, args string) error {
-	return cc.doHandler(chaincodeID, args, true)
+func (cc *CCAction) Close() {
+	cc.action.Close()
 }
 
-func (cc *CCAction) Invoke(chaincodeID string, args string) error {
-	return cc.doHandler(chaincodeID, args, false)
+// InvokeOptions controls how many times and how concurrently the chaincode is invoked
+type InvokeOptions struct {
+	// Iterations is the number of times the whole args array is invoked
+	Iterations int
+	// Concurrency is the number of workers invoking the chaincode concurrently
+	Concurrency int
+	// Retry is the retry options of each invocation
+	Retry retry.Opts
+	// TPS is the target rate of iterations per second, zero submits them as fast as the workers take them
+	TPS float64
+	// Duration is the duration of an open-loop run at the target rate, zero runs until all the iterations are submitted
+	Duration time.Duration
+	// RampSteps is the number of steps the rate is ramped up in before reaching the target rate
+	RampSteps int
+	// RampDuration is the total duration of the ramp-up steps
+	RampDuration time.Duration
+	// TaskTimeout is the time an iteration is given to complete including its retries, zero means no timeout
+	TaskTimeout time.Duration
+	// Seed is the seed of the random values and the sequence of the args, zero picks a random seed
+	Seed int64
+	// Async submits the transactions without waiting for them to be committed, their status is tracked separately
+	Async bool
+	// CommitTimeout is the time the status of a transaction submitted asynchronously is waited for
+	CommitTimeout time.Duration
+	// Report is the format of the report of the results, json or csv, no report is written when empty
+	Report string
+	// ReportFile is the file the report is written to, defaults to invoke-report.<format>
+	ReportFile string
 }
 
-func (cc *CCAction) doHandler(chaincodeID string, args string, isQuery bool) error {
+// DefaultInvokeOptions invokes the args array once, retrying each invocation up to 3 times
+func DefaultInvokeOptions() InvokeOptions {
+	return InvokeOptions{
+		Iterations:    1,
+		Concurrency:   1,
+		CommitTimeout: 30 * time.Second,
+		Retry: retry.Opts{
+			Attempts:       3,
+			InitialBackoff: time.Second,
+			MaxBackoff:     5 * time.Second,
+			BackoffFactor:  2,
+			RetryableCodes: retry.ChannelClientRetryableCodes,
+		},
+	}
+}
 
+// check checks that the options are valid for an invoke, or a query
+func (opts InvokeOptions) check(isQuery bool) error {
+	if opts.Iterations < 1 {
+		return errors.Errorf("invalid iterations %d", opts.Iterations)
+	}
+	if opts.Concurrency < 1 || opts.Concurrency > math.MaxUint16 {
+		return errors.Errorf("invalid concurrency %d", opts.Concurrency)
+	}
+	if opts.TPS < 0 || opts.Duration < 0 || opts.RampSteps < 0 || opts.RampDuration < 0 || opts.TaskTimeout < 0 {
+		return errors.New("the rate, duration, ramp-up and timeout options cannot be negative")
+	}
+	if opts.Async && (isQuery || opts.CommitTimeout <= 0) {
+		return errors.New("async submission requires an invoke and a positive commit timeout")
+	}
+	if opts.Duration > 0 && opts.TPS == 0 {
+		return errors.New("a test duration requires a target rate")
+	}
+	if opts.Report != "" {
+		return stats.CheckFormat(opts.Report)
+	}
+	return nil
+}
+
+func (cc *CCAction) QueryInfo(chaincodeID string, args string, opts InvokeOptions) error {
+	return cc.doHandler(chaincodeID, args, opts, true)
+}
+
+func (cc *CCAction) Invoke(chaincodeID string, args string, opts InvokeOptions) error {
+	return cc.doHandler(chaincodeID, args, opts, false)
+}
+
+// newInvokeArgs parses the invoke args, a single JSON object or an array of them
+func newInvokeArgs(args string) ([]task.ArgStruct, error) {
+	if strings.TrimSpace(args) == "" {
+		return nil, errors.New("no chaincode args given")
+	}
 	var argsArray []task.ArgStruct
-	if err := json.Unmarshal([]byte(args), &argsArray); err != nil {
-		return errors.Errorf("Error unmarshalling JSON arg string: %v", err)
+	if strings.HasPrefix(strings.TrimSpace(args), "[") {
+		if err := json.Unmarshal([]byte(args), &argsArray); err != nil {
+			return nil, errors.Errorf("Error unmarshalling JSON arg string: %v", err)
+		}
+	} else {
+		argStruct, err := newInitArgs(args)
+		if err != nil {
+			return nil, err
+		}
+		argsArray = append(argsArray, argStruct)
 	}
+	if len(argsArray) == 0 {
+		return nil, errors.New("no chaincode args given")
+	}
+	return argsArray, nil
+}
 
-	exec := executor.NewConcurrent("Invoke Chaincode", concurrency)
-	exec.Start()
-	defer exec.Stop(true)
+// doHandler invokes the args array opts.Iterations times. Each iteration is a MultiTask invoking the args
+// in order, the iterations are fanned out across the workers of the executor. With a target rate the
+// iterations are submitted at that rate (open loop) for opts.Duration, or until opts.Iterations are submitted
+func (cc *CCAction) doHandler(chaincodeID string, args string, opts InvokeOptions, isQuery bool) error {
+	argsArray, err := newInvokeArgs(args)
+	if err != nil {
+		return err
+	}
+	if err := opts.check(isQuery); err != nil {
+		return err
+	}
+
+	// Ctrl-C stops the run, the tasks in progress are interrupted and the partial results reported
+	ctx, stop := interruptible()
+	defer stop()
 
 	var targets []fab.Peer
 	for _, peer := range cc.action.GetPeers() {
 		targets = append(targets, peer)
 	}
-	var wg sync.WaitGroup
-	var mutex sync.RWMutex
-	var tasks []task.Task
-	var errs []error
-	var taskID int
-	var success int
-	var successDurations []time.Duration
-	var failDurations []time.Duration
-	var opts = retry.Opts{Attempts: 3, InitialBackoff: 1000, MaxBackoff: 5000, BackoffFactor: 2, RetryableCodes: retry.ChannelClientRetryableCodes}
-	var completedCB = func(startTime time.Time) func(err error) {
-		return func(err error) {
-			duration := time.Since(startTime)
-			mutex.Lock()
-			defer mutex.Unlock()
-			if err != nil {
-				errs = append(errs, err)
-				failDurations = append(failDurations, duration)
-			} else {
-				success++
-				successDurations = append(successDurations, duration)
+	recorder := stats.NewRecorder()
+	// only the transient errors of the completed iterations are kept, not the iterations themselves
+	var transientErrs []error
+	var mutex sync.Mutex
+
+	var tracker *task.TxTracker
+	if opts.Async {
+		eventClient, err := cc.action.EventClient(cc.channelId, cc.user)
+		if err != nil {
+			return errors.WithMessage(err, "error creating event client to track the transactions")
+		}
+		tracker = task.NewTxTracker(ctx, eventClient, opts.CommitTimeout, recorder.Add)
+	}
+
+	// The args of the tasks are evaluated in turn as they are created, so that a run with the
+	// same seed invokes the same args whatever the concurrency
+	seed := opts.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	fmt.Printf("*** Seed: %d\n", seed)
+	ctxt := task.NewContextWithSeed(seed)
+	var n, taskID int
+	next := func(done func()) worker.Task {
+		if opts.Duration == 0 && n >= opts.Iterations {
+			return nil
+		}
+		n++
+		var multiTask *task.MultiTask
+		multiTask = task.NewMultiTask(func() {
+			if err := multiTask.LastError(); err != nil {
+				mutex.Lock()
+				transientErrs = append(transientErrs, err)
+				mutex.Unlock()
 			}
+			done()
+		})
+		for _, args := range argsArray {
+			taskID++
+			ccTask := task.NewCCTask(ctxt, strconv.Itoa(taskID), cc.channelClient, targets, chaincodeID,
+				args, opts.Retry, func() {}, recorder.Add, isQuery)
+			if tracker != nil {
+				ccTask.SetTxTracker(tracker)
+			}
+			ccTask.Prepare()
+			multiTask.Add(ccTask)
 		}
+		return multiTask
 	}
 
-	ctxt := task.NewContext()
-	multiTask := task.NewMultiTask(wg.Done)
-	for _, args := range argsArray {
-		taskID++
-		var startTime time.Time
-		newTask := task.NewCCTask(ctxt, strconv.Itoa(taskID), cc.channelClient, targets, chaincodeID,
-			args, opts, func() { startTime = time.Now() }, completedCB(startTime), isQuery)
-		multiTask.Add(newTask)
+	iterations, stepResults, err := runIterations(ctx, "Invoke Chaincode", opts, recorder, tracker, next)
+	if err != nil {
+		return err
+	}
+	interrupted := ctx.Err() != nil
+	numInvocations := iterations * len(argsArray)
+
+	summary := recorder.Summary()
+	if summary.Failed == 0 && len(transientErrs) > 0 {
+		fmt.Printf("\n*** %d transient errors invoking chaincode:\n", len(transientErrs))
+		for _, err := range transientErrs {
+			fmt.Printf("%s\n", err)
+		}
 	}
-	tasks = append(tasks, multiTask)
-	wg.Add(len(tasks))
 
-	numInvocations := len(tasks) * len(argsArray)
+	if numInvocations > 1 || summary.Failed > 0 || interrupted {
+		fmt.Printf("\n*** Invoked %d set(s) of args %d time(s) with concurrency %d\n", len(argsArray), iterations, opts.Concurrency)
+		summary.Print()
+	}
+	if len(stepResults) > 0 {
+		fmt.Printf("\n")
+		printStepResults(stepResults)
+	}
+
+	if err := writeReport(recorder, opts); err != nil {
+		return err
+	}
+	if interrupted {
+		return errors.Errorf("interrupted after %d of %d invocation(s)", summary.Invocations, numInvocations)
+	}
+	return nil
+}
+
+// interruptible returns a context that is canceled on Ctrl-C, stop releases the signal handler
+func interruptible() (ctx context.Context, stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		select {
+		case <-interrupt:
+			fmt.Printf("\n*** Interrupted, stopping ...\n")
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, func() {
+		signal.Stop(interrupt)
+		cancel()
+	}
+}
+
+// runIterations runs the iterations returned by next on opts.Concurrency workers until next returns nil,
+// or the context is done. next is given the function the iteration must call once completed, the results
+// are recorded by the tasks of the iterations. With a tracker, the run waits for the transactions
+// submitted asynchronously to be committed
+func runIterations(ctx context.Context, name string, opts InvokeOptions, recorder *stats.Recorder, tracker *task.TxTracker,
+	next func(done func()) worker.Task) (iterations int, stepResults []executor.StepResult, err error) {
+	exec := executor.NewConcurrent(name, uint16(opts.Concurrency))
+	exec.SetTaskTimeout(opts.TaskTimeout)
+	metrics := worker.NewMetrics(opts.Concurrency)
+	exec.SetEvents(metrics)
+	exec.StartContext(ctx)
+	defer exec.Stop(true)
+
+	var wg sync.WaitGroup
+	newIteration := func() worker.Task {
+		t := next(wg.Done)
+		if t == nil {
+			return nil
+		}
+		wg.Add(1)
+		iterations++
+		return t
+	}
 
 	done := make(chan bool)
 	go func() {
 		ticker := time.NewTicker(10 * time.Second)
+		defer ticker.Stop()
 		for {
 			select {
 			case <-ticker.C:
-				mutex.RLock()
-				if len(errs) > 0 {
-					fmt.Printf("*** %d failed invocation(s) out of %d\n", len(errs), numInvocations)
+				success, failed := recorder.Counts()
+				fmt.Printf("*** %d successfull and %d failed invocation(s), %d iteration(s) in flight, %d queued, %.0f%% worker utilization\n",
+					success, failed, metrics.InFlight(), exec.Queued(), metrics.Utilization()*100)
+				if tracker != nil {
+					fmt.Printf("*** %d transaction(s) waiting to be committed\n", tracker.Pending())
 				}
-				fmt.Printf("*** %d successfull invocation(s) out of %d\n", success, numInvocations)
-				mutex.RUnlock()
 			case <-done:
 				return
 			}
 		}
 	}()
 
-	startTime := time.Now()
-	for _, t := range tasks {
-		if err := exec.Submit(t); err != nil {
-			return errors.Errorf("error submitting task: %s", err)
-		}
-		time.Sleep(time.Second)
+	recorder.Start()
+	stepResults, err = submitIterations(ctx, exec, opts, newIteration)
+	if err != nil {
+		return iterations, nil, err
 	}
-
-	// Wait for all tasks to complete
 	wg.Wait()
+	if tracker != nil {
+		if pending := tracker.Pending(); pending > 0 {
+			fmt.Printf("*** Waiting for %d transaction(s) to be committed ...\n", pending)
+		}
+		tracker.Wait()
+	}
 	done <- true
+	recorder.Stop()
 
-	duration := time.Now().Sub(startTime)
+	if panicked := metrics.Panicked(); panicked > 0 {
+		fmt.Printf("\n*** %d iteration(s) panicked and were not completed, see the log for the stack traces\n", panicked)
+	}
+	return iterations, stepResults, nil
+}
 
-	var allErrs []error
-	var attempts int
-	for _, t := range tasks {
-		attempts = attempts + t.Attempts()
-		if t.LastError() != nil {
-			allErrs = append(allErrs, t.LastError())
+// submitIterations submits the iterations returned by newIteration until it returns nil or the context is done.
+// With a target rate the iterations are submitted at that rate, otherwise as fast as the workers take them
+func submitIterations(ctx context.Context, exec *executor.Executor, opts InvokeOptions, newIteration func() worker.Task) ([]executor.StepResult, error) {
+	if opts.TPS > 0 {
+		duration := opts.Duration
+		if duration == 0 {
+			// run until all the iterations are submitted
+			duration = math.MaxInt64
 		}
+		steps := executor.RampSteps(opts.TPS, duration, opts.RampSteps, opts.RampDuration)
+		stepResults, err := executor.NewScheduler(exec, steps...).Run(newIteration)
+		if err != nil {
+			return nil, errors.Errorf("error submitting task: %s", err)
+		}
+		return stepResults, nil
 	}
-
-	if len(errs) > 0 {
-		fmt.Printf("\n*** %d errors invoking chaincode:\n", len(errs))
-		for _, err := range errs {
-			fmt.Printf("%s\n", err)
+	for ctx.Err() == nil {
+		t := newIteration()
+		if t == nil {
+			break
 		}
-	} else if len(allErrs) > 0 {
-		fmt.Printf("\n*** %d transient errors invoking chaincode:\n", len(allErrs))
-		for _, err := range allErrs {
-			fmt.Printf("%s\n", err)
+		if err := exec.Submit(t); err != nil {
+			return nil, errors.Errorf("error submitting task: %s", err)
 		}
 	}
+	return nil, nil
+}
 
-	if numInvocations/len(argsArray) > 1 {
-		fmt.Printf("\n")
-		fmt.Printf("*** ---------- Summary: ----------\n")
-		fmt.Printf("***   - Invocations:     %d\n", numInvocations)
-		fmt.Printf("***   - Concurrency:     %d\n", concurrency)
-		fmt.Printf("***   - Successfull:     %d\n", success)
-		fmt.Printf("***   - Total attempts:  %d\n", attempts)
-		fmt.Printf("***   - Duration:        %2.2fs\n", duration.Seconds())
-		fmt.Printf("***   - Rate:            %2.2f/s\n", float64(numInvocations)/duration.Seconds())
-		fmt.Printf("***   - Average:         %2.2fs\n", average(append(successDurations, failDurations...)))
-		fmt.Printf("***   - Average Success: %2.2fs\n", average(successDurations))
-		fmt.Printf("***   - Average Fail:    %2.2fs\n", average(failDurations))
-		fmt.Printf("***   - Min Success:     %2.2fs\n", min(successDurations))
-		fmt.Printf("***   - Max Success:     %2.2fs\n", max(successDurations))
-		fmt.Printf("*** ------------------------------\n")
+// writeReport writes the report of the results if the options ask for one
+func writeReport(recorder *stats.Recorder, opts InvokeOptions) error {
+	if opts.Report == "" {
+		return nil
 	}
-
+	reportFile := opts.ReportFile
+	if reportFile == "" {
+		reportFile = "invoke-report." + opts.Report
+	}
+	if err := recorder.WriteReportFile(reportFile, opts.Report); err != nil {
+		return err
+	}
+	fmt.Printf("...report written to %s\n", reportFile)
 	return nil
 }
 
+// printStepResults prints the offered and achieved rate of each step of an open-loop run,
+// a saturated step is one the network could not absorb
+func printStepResults(results []executor.StepResult) {
+	var rows [][]string
+	for i, r := range results {
+		rows = append(rows, []string{
+			strconv.Itoa(i),
+			fmt.Sprintf("%.2f/s", r.TPS),
+			fmt.Sprintf("%.2f/s", r.AchievedTPS()),
+			fmt.Sprintf("%2.2fs", r.Duration.Seconds()),
+			strconv.Itoa(r.Offered),
+			strconv.Itoa(r.Dropped),
+			strconv.Itoa(r.MaxQueued),
+			strconv.FormatBool(r.Saturated),
+		})
+	}
+	printer.Table([]string{"STEP", "TARGET", "ACHIEVED", "DURATION", "OFFERED", "DROPPED", "MAX QUEUED", "SATURATED"}, rows)
+}
+
 func newChaincodePolicy(policy string, mspIDs []string) (*common.SignaturePolicyEnvelope, error) {
 	if policy != "" {
 		ccPolicy, err := cauthdsl.FromString(policy)
@@ -390,37 +721,3 @@ func getCollectionConfigFromFile(collConfigFile string) ([]*common.CollectionCon
 	}
 	return res, nil
 }
-
-func average(durations []time.Duration) float64 {
-	if len(durations) == 0 {
-		return 0
-	}
-
-	var total float64
-	for _, duration := range durations {
-		total += duration.Seconds()
-	}
-	return total / float64(len(durations))
-}
-
-func min(durations []time.Duration) float64 {
-	min, _ := minMax(durations)
-	return min
-}
-
-func max(durations []time.Duration) float64 {
-	_, max := minMax(durations)
-	return max
-}
-
-func minMax(durations []time.Duration) (min float64, max float64) {
-	for _, duration := range durations {
-		if min == 0 || min > duration.Seconds() {
-			min = duration.Seconds()
-		}
-		if max == 0 || max < duration.Seconds() {
-			max = duration.Seconds()
-		}
-	}
-	return
-}
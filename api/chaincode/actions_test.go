@@ -0,0 +1,46 @@
+package chaincode
+
+import "testing"
+
+func TestNewInvokeArgs(t *testing.T) {
+	argsArray, err := newInvokeArgs(`{"Func":"move","Args":["A","B","1"]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(argsArray) != 1 || argsArray[0].Func != "move" || len(argsArray[0].Args) != 3 {
+		t.Fatalf("unexpected args %+v", argsArray)
+	}
+
+	argsArray, err = newInvokeArgs(` [{"Func":"move","Args":["A","B","1"]},{"Func":"move","Args":["B","A","2"]}]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(argsArray) != 2 || argsArray[1].Args[2] != "2" {
+		t.Fatalf("unexpected args %+v", argsArray)
+	}
+
+	argsArray, err = newInvokeArgs(`{"Func":"initMarble","Args":[],"Transient":{"marble":"{\"name\":\"m1\"}"}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(argsArray) != 1 || argsArray[0].Transient["marble"] != `{"name":"m1"}` {
+		t.Fatalf("unexpected args %+v", argsArray)
+	}
+
+	for _, args := range []string{"", "[]", "{invalid"} {
+		if _, err := newInvokeArgs(args); err == nil {
+			t.Fatalf("expecting error for args [%s]", args)
+		}
+	}
+}
+
+func TestPrivateDataQuery_Request(t *testing.T) {
+	query := PrivateDataQuery{ChaincodeID: "marbles", Func: "readPrivate", Collection: "collectionMarbles", CollectionArg: true}
+	if req := query.request("marble1"); len(req.Args) != 2 || string(req.Args[0]) != "collectionMarbles" || string(req.Args[1]) != "marble1" {
+		t.Fatalf("unexpected request %+v", req)
+	}
+	query.CollectionArg = false
+	if req := query.request("marble1"); len(req.Args) != 1 || string(req.Args[0]) != "marble1" {
+		t.Fatalf("unexpected request %+v", req)
+	}
+}
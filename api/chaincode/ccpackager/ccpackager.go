@@ -0,0 +1,160 @@
+package ccpackager
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/ccpackager/gopackager"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/resource"
+	"github.com/pkg/errors"
+	"github.com/zhcppy/fabricli/logger"
+)
+
+const (
+	Golang = "golang"
+	Node   = "node"
+	Java   = "java"
+)
+
+// metaInfDir holds the chaincode metadata, e.g. META-INF/statedb/couchdb/indexes/indexOwner.json
+const metaInfDir = "META-INF"
+
+// ChaincodeType returns the chaincode spec type of the chaincode language, empty is golang
+func ChaincodeType(lang string) (pb.ChaincodeSpec_Type, error) {
+	switch strings.ToLower(lang) {
+	case "", Golang:
+		return pb.ChaincodeSpec_GOLANG, nil
+	case Node:
+		return pb.ChaincodeSpec_NODE, nil
+	case Java:
+		return pb.ChaincodeSpec_JAVA, nil
+	}
+	return pb.ChaincodeSpec_UNDEFINED, errors.Errorf("unsupported chaincode language [%s], expecting one of [%s, %s, %s]", lang, Golang, Node, Java)
+}
+
+// NewCCPackage packages the chaincode source of the given language. Golang chaincode is looked up
+// in the GOPATH, node and java chaincode path is the local directory of the project
+func NewCCPackage(lang, path, goPath string) (*resource.CCPackage, error) {
+	ccType, err := ChaincodeType(lang)
+	if err != nil {
+		return nil, err
+	}
+	switch ccType {
+	case pb.ChaincodeSpec_NODE:
+		// the same rules as the node platform of the peer
+		code, err := packageFolder(path, []string{"node_modules"}, nil)
+		if err != nil {
+			return nil, err
+		}
+		return &resource.CCPackage{Type: ccType, Code: code}, nil
+	case pb.ChaincodeSpec_JAVA:
+		// the same rules as the java platform of the peer
+		code, err := packageFolder(path, []string{"target", "build", "out"}, map[string]bool{".class": true})
+		if err != nil {
+			return nil, err
+		}
+		return &resource.CCPackage{Type: ccType, Code: code}, nil
+	default:
+		return gopackager.NewCCPackage(path, goPath)
+	}
+}
+
+// packageFolder writes the folder into a tar.gz the way the peer CLI does: source files go under src/,
+// META-INF is kept at the root, .git and the excluded top level directories and file types are skipped
+func packageFolder(folder string, excludeDirs []string, excludeFileTypes map[string]bool) ([]byte, error) {
+	if folder == "" {
+		return nil, errors.New("chaincode path cannot be empty")
+	}
+	rootDir := strings.TrimSuffix(folder, "/")
+	logger.L().Debugf("Packaging chaincode project from path %s", rootDir)
+
+	payload := bytes.NewBuffer(nil)
+	gw := gzip.NewWriter(payload)
+	tw := tar.NewWriter(gw)
+
+	var fileCount int
+	walkFn := func(localPath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if strings.Contains(localPath, ".git") || info.Mode().IsDir() {
+			return nil
+		}
+		relPath, err := filepath.Rel(rootDir, localPath)
+		if err != nil {
+			return err
+		}
+		relPath = filepath.ToSlash(relPath)
+		for _, excludeDir := range excludeDirs {
+			if strings.HasPrefix(relPath, excludeDir+"/") {
+				return nil
+			}
+		}
+		if excludeFileTypes[filepath.Ext(localPath)] {
+			return nil
+		}
+
+		packagePath := "src/" + relPath
+		if strings.HasPrefix(relPath, metaInfDir+"/") {
+			// hidden files are not supported as metadata
+			if strings.HasPrefix(filepath.Base(relPath), ".") {
+				logger.L().Warnf("Ignoring hidden file in metadata directory: %s", relPath)
+				return nil
+			}
+			packagePath = relPath
+		}
+		if err := writeFile(tw, localPath, packagePath, info); err != nil {
+			return errors.WithMessage(err, "error writing file to package")
+		}
+		fileCount++
+		return nil
+	}
+	if err := filepath.Walk(rootDir, walkFn); err != nil {
+		return nil, errors.WithMessage(err, "error writing chaincode package contents")
+	}
+	if fileCount == 0 {
+		return nil, errors.Errorf("no source files found in '%s'", folder)
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gw.Close(); err != nil {
+		return nil, err
+	}
+	return payload.Bytes(), nil
+}
+
+func writeFile(tw *tar.Writer, localPath, packagePath string, info os.FileInfo) error {
+	file, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	header, err := tar.FileInfoHeader(info, localPath)
+	if err != nil {
+		return errors.Wrapf(err, "error getting file info header of %s", localPath)
+	}
+	// take the variance out of the tar, as the peer does
+	var zeroTime time.Time
+	header.AccessTime = zeroTime
+	header.ModTime = zeroTime
+	header.ChangeTime = zeroTime
+	header.Name = packagePath
+	header.Mode = 0100644
+	header.Uid = 500
+	header.Gid = 500
+	if err = tw.WriteHeader(header); err != nil {
+		return errors.Wrapf(err, "error writing header of %s", localPath)
+	}
+	_, err = io.Copy(tw, file)
+	return err
+}
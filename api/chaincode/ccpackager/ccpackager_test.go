@@ -0,0 +1,86 @@
+package ccpackager
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+)
+
+func writeFiles(t *testing.T, root string, files ...string) {
+	for _, file := range files {
+		path := filepath.Join(root, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(file), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func packageFiles(t *testing.T, code []byte) []string {
+	gr, err := gzip.NewReader(bytes.NewReader(code))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gr)
+	var names []string
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, header.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestNewCCPackage(t *testing.T) {
+	root, err := ioutil.TempDir("", "ccpackager")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	writeFiles(t, root, "index.js", "lib/cc.js", "node_modules/x/index.js", ".git/HEAD",
+		"Main.java", "Main.class", "build/out.jar", "META-INF/statedb/couchdb/indexes/index.json", "META-INF/.hidden")
+
+	tests := []struct {
+		lang     string
+		ccType   pb.ChaincodeSpec_Type
+		expected []string
+	}{
+		{Node, pb.ChaincodeSpec_NODE, []string{"META-INF/statedb/couchdb/indexes/index.json", "src/Main.class", "src/Main.java",
+			"src/build/out.jar", "src/index.js", "src/lib/cc.js"}},
+		{Java, pb.ChaincodeSpec_JAVA, []string{"META-INF/statedb/couchdb/indexes/index.json", "src/Main.java",
+			"src/index.js", "src/lib/cc.js", "src/node_modules/x/index.js"}},
+	}
+	for _, test := range tests {
+		ccPkg, err := NewCCPackage(test.lang, root, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ccPkg.Type != test.ccType {
+			t.Fatalf("%s: unexpected type %s", test.lang, ccPkg.Type)
+		}
+		if names := packageFiles(t, ccPkg.Code); strings.Join(names, ",") != strings.Join(test.expected, ",") {
+			t.Fatalf("%s: unexpected package files %v", test.lang, names)
+		}
+	}
+
+	if _, err := NewCCPackage("rust", root, ""); err == nil {
+		t.Fatal("expecting unsupported language error")
+	}
+}
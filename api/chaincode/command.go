@@ -1,9 +1,18 @@
 package chaincode
 
 import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	"github.com/zhcppy/fabricli/actions"
 	"github.com/zhcppy/fabricli/api"
+	"github.com/zhcppy/fabricli/api/query"
 	"github.com/zhcppy/fabricli/cmd"
+	"github.com/zhcppy/fabricli/printer"
 )
 
 func NewCmd() *cobra.Command {
@@ -23,45 +32,124 @@ func NewCmd() *cobra.Command {
 	cmd.InitChaincodePolicy(flags)
 	cmd.InitChaincodeVersion(flags)
 	cmd.InitGoPath(flags)
+	cmd.InitChaincodeLang(flags)
 
+	chaincodeCmd.AddCommand(newCCPackageCmd())
 	chaincodeCmd.AddCommand(newCCInstallCmd())
 	chaincodeCmd.AddCommand(newCCInfoCmd())
 	chaincodeCmd.AddCommand(newCCInstantiateCmd())
 	chaincodeCmd.AddCommand(newCCUpgradeCmd())
 	chaincodeCmd.AddCommand(newCCInvokeCmd())
+	chaincodeCmd.AddCommand(newCCPrivateCmd())
+	chaincodeCmd.AddCommand(newCCCompareCmd())
+	chaincodeCmd.AddCommand(newCCScenarioCmd())
+	chaincodeCmd.AddCommand(newCCRecordCmd())
+	chaincodeCmd.AddCommand(newCCReplayCmd())
+	chaincodeCmd.AddCommand(newLifecycleCmd())
 	return chaincodeCmd
 }
 
+func newCCPackageCmd() *cobra.Command {
+	packageCmd := &cobra.Command{
+		Use:     "package <file>",
+		Short:   "Package chaincode into a deployment spec file.",
+		Long:    "Package chaincode into a ChaincodeDeploymentSpec file that can be installed with 'install --package', with --sign the package is endorsed by the user",
+		Example: "chaincode package mycc.cds --ccid mycc --v v0 --ccp github.com/example_cc --sign",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(c *cobra.Command, args []string) error {
+			cfg := api.ConfigFlags(c.Flags())
+			var owner msp.SigningIdentity
+			if sign, _ := c.Flags().GetBool(cmd.SignPackageFlag); sign {
+				// only the identity of the user is loaded, signing needs no connection to the network
+				action, err := actions.New(cfg.ConfigFile, cfg.SelectionProvider)
+				if err != nil {
+					return err
+				}
+				defer action.Close()
+				if owner, err = action.User(cfg.DefaultOrgID(), cfg.DefaultPeerURL(), cfg.Username); err != nil {
+					return err
+				}
+			}
+			policy, _ := c.Flags().GetString(cmd.InstantiatePolicyFlag)
+			pkgBytes, err := Package(cfg.CCodeInfo, owner, policy)
+			if err != nil {
+				return err
+			}
+			if err = ioutil.WriteFile(args[0], pkgBytes, 0644); err != nil {
+				return errors.Wrapf(err, "failed writing chaincode package to file [%s]", args[0])
+			}
+			fmt.Printf("...successfuly packaged chaincode %s.%s into %s (%d bytes).\n",
+				cfg.CCodeInfo.ChaincodeID, cfg.CCodeInfo.ChaincodeVersion, args[0], len(pkgBytes))
+			return nil
+		},
+	}
+	cmd.InitSignPackage(packageCmd.Flags())
+	cmd.InitInstantiatePolicy(packageCmd.Flags())
+	return packageCmd
+}
+
 func newCCInstallCmd() *cobra.Command {
-	return &cobra.Command{
+	installCmd := &cobra.Command{
 		Use:   "install",
 		Short: "Install chaincode.",
-		Run: func(cmd *cobra.Command, args []string) {
-			//action, err := NewCCAction(api.GetConfig())
-			//if err != nil {
-			//	panic(err)
-			//}
-			//action.Install(config.CCodeInfo)
-
+		Long:  "Install chaincode on the peers of the orgs given by --orgid, optionally limited to the peers given by --peer",
+		RunE: func(c *cobra.Command, args []string) error {
+			cfg := api.ConfigFlags(c.Flags())
+			action, err := NewCCAction(cfg)
+			if err != nil {
+				return err
+			}
+			defer action.Close()
+			var results []PeerResult
+			if pkgFile, _ := c.Flags().GetString(cmd.ChaincodePackageFlag); pkgFile != "" {
+				results, err = action.InstallPackage(pkgFile, cfg.OrgIDs(), cfg.PeerURLs())
+			} else {
+				results, err = action.Install(cfg.CCodeInfo, cfg.OrgIDs(), cfg.PeerURLs())
+			}
+			printPeerResults(results)
+			return err
 		},
 	}
+	cmd.InitChaincodePackage(installCmd.Flags())
+	return installCmd
 }
 
 func newCCUpgradeCmd() *cobra.Command {
 	return &cobra.Command{
 		Use:   "upgrade",
 		Short: "Upgrade chaincode.",
-		Run: func(cmd *cobra.Command, args []string) {
+		Long:  "Upgrade chaincode on the channel, endorsed by the peers of the orgs given by --orgid, optionally limited to the peers given by --peer",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg := api.ConfigFlags(cmd.Flags())
+			action, err := NewCCAction(cfg)
+			if err != nil {
+				return err
+			}
+			defer action.Close()
+			results, err := action.Upgrade(cfg.CCodeInfo, cfg.OrgIDs(), cfg.PeerURLs())
+			printPeerResults(results)
+			return err
 		},
 	}
 }
 
+func printPeerResults(results []PeerResult) {
+	if len(results) == 0 {
+		return
+	}
+	var rows [][]string
+	for _, r := range results {
+		rows = append(rows, []string{r.OrgID, r.Peer, r.Status, r.Info})
+	}
+	printer.Table([]string{"ORG", "PEER", "STATUS", "INFO"}, rows)
+}
+
 func newCCInstantiateCmd() *cobra.Command {
 	return &cobra.Command{
 		Use:   "instantiate",
 		Short: "Instantiate chaincode.",
 		Run: func(cmd *cobra.Command, args []string) {
-			cfg := api.GetConfig()
+			cfg := api.ConfigFlags(cmd.Flags())
 			action, err := NewCCAction(cfg)
 			if err != nil {
 				panic(err)
@@ -75,37 +163,280 @@ func newCCInstantiateCmd() *cobra.Command {
 }
 
 func newCCInfoCmd() *cobra.Command {
-	return &cobra.Command{
+	infoCmd := &cobra.Command{
 		Use:   "info",
 		Short: "Get chaincode info,Retrieves details about the chaincode",
-		Run: func(cmd *cobra.Command, args []string) {
-			cfg := api.GetConfig()
+		RunE: func(c *cobra.Command, args []string) error {
+			cfg := api.ConfigFlags(c.Flags())
 			action, err := NewCCAction(cfg)
 			if err != nil {
-				panic(err)
-			}
-			err = action.QueryInfo(cfg.CCodeInfo.ChaincodeID, cfg.CCodeInfo.ChaincodeArgs)
-			if err != nil {
-				panic(err)
+				return err
 			}
+			defer action.Close()
+			return action.QueryInfo(cfg.CCodeInfo.ChaincodeID, cfg.CCodeInfo.ChaincodeArgs, invokeOptions(c))
 		},
 	}
+	initInvokeFlags(infoCmd)
+	return infoCmd
 }
 
 func newCCInvokeCmd() *cobra.Command {
-	return &cobra.Command{
+	invokeCmd := &cobra.Command{
 		Use:   "invoke",
 		Short: "invoke chaincode.",
-		Run: func(cmd *cobra.Command, args []string) {
-			cfg := api.GetConfig()
+		Long:  "Invoke chaincode --iterations times with the args, a JSON object or an array of them, using --concurrency workers",
+		Example: `chaincode invoke --ccid mycc --args '{"Func":"move","Args":["A","B","1"]}' --iterations 100 --concurrency 8 --attempts 3
+chaincode invoke --ccid mycc --args '{"Func":"move","Args":["A","B","1"]}' --concurrency 64 --tps 200 --duration 5m --rampup-steps 4 --rampup-duration 1m
+chaincode invoke --ccid mycc --args '{"Func":"move","Args":["A","B","1"]}' --concurrency 64 --tps 500 --duration 5m --async
+chaincode invoke --ccid mycc --args '{"Func":"move","Args":["A$zipf(100,1.2)","B$hotspot(100,80,5)","1"]}' --iterations 1000 --concurrency 16 --seed 42
+chaincode invoke --ccid mycc --args '{"Func":"move","Args":["A","B","1"]}' --dry-run
+chaincode invoke --ccid marbles --args '{"Func":"initMarble","Args":[],"Transient":{"marble":"{\"name\":\"marble$seq()\",\"owner\":\"tom\"}"}}'`,
+		RunE: func(c *cobra.Command, args []string) error {
+			cfg := api.ConfigFlags(c.Flags())
 			action, err := NewCCAction(cfg)
 			if err != nil {
-				panic(err)
+				return err
 			}
-			err = action.Invoke(cfg.CCodeInfo.ChaincodeID, cfg.CCodeInfo.ChaincodeArgs)
+			defer action.Close()
+			if dryRun, _ := c.Flags().GetBool(cmd.DryRunFlag); dryRun {
+				return action.DryRun(cfg.CCodeInfo.ChaincodeID, cfg.CCodeInfo.ChaincodeArgs)
+			}
+			return action.Invoke(cfg.CCodeInfo.ChaincodeID, cfg.CCodeInfo.ChaincodeArgs, invokeOptions(c))
+		},
+	}
+	initInvokeFlags(invokeCmd)
+	cmd.InitDryRun(invokeCmd.Flags())
+	cmd.InitAsync(invokeCmd.Flags())
+	cmd.InitCommitTimeout(invokeCmd.Flags())
+	return invokeCmd
+}
+
+func newCCPrivateCmd() *cobra.Command {
+	privateCmd := &cobra.Command{
+		Use:   "private <collection> <key>...",
+		Short: "Read keys from a private data collection.",
+		Long: "Read the keys from a private data collection on each peer of the orgs given by --orgid, optionally limited to the peers given by --peer, " +
+			"by querying the chaincode function given by --func with the collection and the key",
+		Example: `chaincode private collectionMarbles marble1 marble2 --ccid marbles --func readPrivate
+chaincode private collectionMarblePrivateDetails marble1 --ccid marbles --func readMarblePrivateDetails --collection-arg=false`,
+		Args: cobra.MinimumNArgs(2),
+		RunE: func(c *cobra.Command, args []string) error {
+			cfg := api.ConfigFlags(c.Flags())
+			query := PrivateDataQuery{ChaincodeID: cfg.CCodeInfo.ChaincodeID, Collection: args[0]}
+			query.Func, _ = c.Flags().GetString(cmd.PrivateFuncFlag)
+			query.CollectionArg, _ = c.Flags().GetBool(cmd.CollectionArgFlag)
+			if transient, _ := c.Flags().GetString(cmd.TransientFlag); transient != "" {
+				var transientMap map[string]string
+				if err := json.Unmarshal([]byte(transient), &transientMap); err != nil {
+					return errors.Wrap(err, "invalid transient data")
+				}
+				query.Transient = make(map[string][]byte, len(transientMap))
+				for k, v := range transientMap {
+					query.Transient[k] = []byte(v)
+				}
+			}
+			action, err := NewCCAction(cfg)
 			if err != nil {
-				panic(err)
+				return err
+			}
+			defer action.Close()
+			results, err := action.QueryPrivateData(query, args[1:], cfg.OrgIDs(), cfg.PeerURLs())
+			if err != nil {
+				return err
+			}
+			var rows [][]string
+			for _, r := range results {
+				value := string(r.Value)
+				if r.Err != nil {
+					value = "error: " + r.Err.Error()
+				}
+				rows = append(rows, []string{r.OrgID, r.Peer, r.Key, value})
 			}
+			printer.Table([]string{"ORG", "PEER", "KEY", "VALUE"}, rows)
+			return nil
 		},
 	}
+	cmd.InitPrivateFunc(privateCmd.Flags())
+	cmd.InitCollectionArg(privateCmd.Flags())
+	cmd.InitTransient(privateCmd.Flags())
+	return privateCmd
+}
+
+func newCCCompareCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "compare",
+		Short: "Compare the endorsements of the same proposal by each peer.",
+		Long: "Send the same proposal of the args to each peer of the orgs given by --orgid, optionally limited to the peers given by --peer, one at a time " +
+			"and report the differences between their responses, e.g. nondeterministic chaincode or peers running another version of the chaincode. " +
+			"The transaction is not submitted",
+		Example: `chaincode compare --ccid mycc --args '{"Func":"move","Args":["A","B","1"]}'`,
+		RunE: func(c *cobra.Command, args []string) error {
+			cfg := api.ConfigFlags(c.Flags())
+			argsArray, err := newInvokeArgs(cfg.CCodeInfo.ChaincodeArgs)
+			if err != nil {
+				return err
+			}
+			action, err := NewCCAction(cfg)
+			if err != nil {
+				return err
+			}
+			defer action.Close()
+			consistent := true
+			for _, arg := range argsArray {
+				comparison, err := action.Compare(cfg.CCodeInfo.ChaincodeID, arg, cfg.OrgIDs(), cfg.PeerURLs())
+				if err != nil {
+					return err
+				}
+				fmt.Printf("\n*** Compared the endorsements of %s\n", arg.Func)
+				comparison.Print()
+				consistent = consistent && comparison.Consistent()
+			}
+			if !consistent {
+				return errDiverged
+			}
+			return nil
+		},
+	}
+}
+
+func newCCScenarioCmd() *cobra.Command {
+	scenarioCmd := &cobra.Command{
+		Use:   "scenario <file.yaml>",
+		Short: "Run a scenario of weighted operations.",
+		Long: "Run --iterations iterations of the scenario file using --concurrency workers, each iteration invokes one of the operations " +
+			"picked at random according to their weights. The operations may invoke or query several chaincodes as several users",
+		Example: `chaincode scenario marbles.yaml --iterations 10000 --concurrency 32
+chaincode scenario marbles.yaml --concurrency 64 --tps 200 --duration 5m --seed 42 --report csv`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(c *cobra.Command, args []string) error {
+			scenario, err := LoadScenario(args[0])
+			if err != nil {
+				return err
+			}
+			action, err := NewCCAction(api.ConfigFlags(c.Flags()))
+			if err != nil {
+				return err
+			}
+			defer action.Close()
+			return action.RunScenario(scenario, invokeOptions(c))
+		},
+	}
+	initInvokeFlags(scenarioCmd)
+	return scenarioCmd
+}
+
+func newCCRecordCmd() *cobra.Command {
+	recordCmd := &cobra.Command{
+		Use:   "record",
+		Short: "Record the chaincode calls of a block range into a replay file.",
+		Long: "Scan the blocks from --from to --to and write the chaincode, function and args of each endorser transaction to the replay file " +
+			"given by --out, one JSON call per line. Only the calls of --ccid are recorded if given, the system chaincodes are skipped",
+		Example: `chaincode record --from 1200 --to 1500 --out calls.jsonl
+chaincode record --ccid mycc --from 1200 --out calls.jsonl`,
+		RunE: func(c *cobra.Command, args []string) error {
+			flags := c.Flags()
+			from, _ := flags.GetUint64(cmd.FromBlockFlag)
+			to, _ := flags.GetInt64(cmd.ToBlockFlag)
+			out, _ := flags.GetString(cmd.OutputFileFlag)
+			if out == "" {
+				return errors.New("no replay file given")
+			}
+			cfg := api.ConfigFlags(flags)
+			q, err := query.NewQueryAction(cfg)
+			if err != nil {
+				return err
+			}
+			defer q.Close()
+			last := uint64(to)
+			if to < 0 {
+				height, err := q.BlockHeight()
+				if err != nil {
+					return err
+				}
+				last = height - 1
+			}
+			recorded, err := Record(q, from, last, cfg.CCodeInfo.ChaincodeID, out)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Recorded %d call(s) of blocks %d to %d to %s\n", recorded, from, last, out)
+			return nil
+		},
+	}
+	cmd.InitFromBlock(recordCmd.Flags())
+	cmd.InitToBlock(recordCmd.Flags())
+	cmd.InitOutputFile(recordCmd.Flags(), "", "The path of the replay file")
+	return recordCmd
+}
+
+func newCCReplayCmd() *cobra.Command {
+	replayCmd := &cobra.Command{
+		Use:   "replay <file>",
+		Short: "Replay the chaincode calls of a replay file.",
+		Long: "Invoke the calls recorded by the record command in turn --iterations times using --concurrency workers, on the channel of the config. " +
+			"The calls are invoked on the chaincode they were recorded on unless --ccid is given",
+		Example: `chaincode replay calls.jsonl --concurrency 32
+chaincode replay calls.jsonl --cid staging --concurrency 64 --original-timing
+chaincode replay calls.jsonl --ccid mycc2 --concurrency 64 --tps 300 --duration 10m`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(c *cobra.Command, args []string) error {
+			calls, err := ReadReplayFile(args[0])
+			if err != nil {
+				return err
+			}
+			cfg := api.ConfigFlags(c.Flags())
+			action, err := NewCCAction(cfg)
+			if err != nil {
+				return err
+			}
+			defer action.Close()
+			originalTiming, _ := c.Flags().GetBool(cmd.OriginalTimingFlag)
+			return action.Replay(calls, cfg.CCodeInfo.ChaincodeID, originalTiming, invokeOptions(c))
+		},
+	}
+	initInvokeFlags(replayCmd)
+	cmd.InitOriginalTiming(replayCmd.Flags())
+	return replayCmd
+}
+
+func initInvokeFlags(c *cobra.Command) {
+	flags := c.Flags()
+	cmd.InitIterations(flags)
+	cmd.InitConcurrency(flags)
+	cmd.InitAttempts(flags)
+	cmd.InitInitialBackoff(flags)
+	cmd.InitMaxBackoff(flags)
+	cmd.InitBackoffFactor(flags)
+	cmd.InitTPS(flags)
+	cmd.InitDuration(flags)
+	cmd.InitRampSteps(flags)
+	cmd.InitRampDuration(flags)
+	cmd.InitTaskTimeout(flags)
+	cmd.InitSeed(flags)
+	cmd.InitReport(flags)
+	cmd.InitReportFile(flags)
+}
+
+func invokeOptions(c *cobra.Command) InvokeOptions {
+	opts := DefaultInvokeOptions()
+	flags := c.Flags()
+	opts.Iterations, _ = flags.GetInt(cmd.IterationsFlag)
+	opts.Concurrency, _ = flags.GetInt(cmd.ConcurrencyFlag)
+	opts.Retry.Attempts, _ = flags.GetInt(cmd.AttemptsFlag)
+	opts.Retry.InitialBackoff, _ = flags.GetDuration(cmd.InitialBackoffFlag)
+	opts.Retry.MaxBackoff, _ = flags.GetDuration(cmd.MaxBackoffFlag)
+	opts.Retry.BackoffFactor, _ = flags.GetFloat64(cmd.BackoffFactorFlag)
+	opts.TPS, _ = flags.GetFloat64(cmd.TPSFlag)
+	opts.Duration, _ = flags.GetDuration(cmd.DurationFlag)
+	opts.RampSteps, _ = flags.GetInt(cmd.RampStepsFlag)
+	opts.RampDuration, _ = flags.GetDuration(cmd.RampDurationFlag)
+	opts.TaskTimeout, _ = flags.GetDuration(cmd.TaskTimeoutFlag)
+	opts.Seed, _ = flags.GetInt64(cmd.SeedFlag)
+	if flags.Lookup(cmd.AsyncFlag) != nil {
+		opts.Async, _ = flags.GetBool(cmd.AsyncFlag)
+		opts.CommitTimeout, _ = flags.GetDuration(cmd.CommitTimeoutFlag)
+	}
+	opts.Report, _ = flags.GetString(cmd.ReportFlag)
+	opts.ReportFile, _ = flags.GetString(cmd.ReportFileFlag)
+	return opts
 }
@@ -0,0 +1,254 @@
+package chaincode
+
+import (
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/multi"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/pkg/errors"
+	"github.com/zhcppy/fabricli/api/chaincode/task"
+	"github.com/zhcppy/fabricli/printer"
+)
+
+// maxValueLength is the length values are truncated to in the differences
+const maxValueLength = 64
+
+// PeerEndorsement is the endorsement of a proposal by a single peer, or the error it returned
+type PeerEndorsement struct {
+	OrgID       string
+	Peer        string
+	Endorsement *Endorsement
+	Err         error
+	// Group is the index of the group of peers that returned the same response
+	Group int
+}
+
+// Comparison is the outcome of sending the same proposal to several peers
+type Comparison struct {
+	TxID      string
+	Endorsers []PeerEndorsement
+	// Differences are the differences of each group from group 0, the group most peers belong to
+	Differences map[int][]string
+}
+
+// Consistent returns whether all the peers returned the same response
+func (c *Comparison) Consistent() bool {
+	return len(c.Differences) == 0
+}
+
+// Compare sends the same proposal of the args to each peer of the given orgs, filtered by the given
+// peer URLs, one at a time and compares their responses. Peers returning the same status, payload,
+// events and read/write sets are grouped together and the differences between the groups reported,
+// e.g. nondeterministic chaincode or peers running another version of the chaincode
+func (cc *CCAction) Compare(chaincodeID string, args task.ArgStruct, orgIDs, peerURLs []string) (*Comparison, error) {
+	peersByOrg, err := cc.action.FilterPeers(orgIDs, peerURLs)
+	if err != nil {
+		return nil, err
+	}
+	orgs := make([]string, 0, len(peersByOrg))
+	for orgID := range peersByOrg {
+		orgs = append(orgs, orgID)
+	}
+	sort.Strings(orgs)
+	var targets []fab.Peer
+	targetOrgs := map[string]string{}
+	for _, orgID := range orgs {
+		for _, peer := range peersByOrg[orgID] {
+			targets = append(targets, peer)
+			targetOrgs[peer.URL()] = orgID
+		}
+	}
+
+	ccArgs, transient, err := args.Evaluate(task.NewContext())
+	if err != nil {
+		return nil, err
+	}
+	request := fab.ChaincodeInvokeRequest{
+		ChaincodeID:  chaincodeID,
+		Fcn:          args.Func,
+		Args:         ccArgs,
+		TransientMap: transient,
+	}
+	txID, responses, err := cc.action.SendProposalToEach(cc.channelId, cc.user, request, targets)
+	if err != nil {
+		return nil, err
+	}
+
+	var endorsers []PeerEndorsement
+	for _, resp := range responses {
+		endorser := PeerEndorsement{OrgID: targetOrgs[resp.Peer.URL()], Peer: resp.Peer.URL(), Err: resp.Err}
+		if resp.Err == nil {
+			endorser.Endorsement, endorser.Err = DecodeEndorsement(resp.Peer.URL(), resp.Response.ProposalResponse)
+		}
+		endorsers = append(endorsers, endorser)
+	}
+	return compareEndorsements(string(txID), endorsers), nil
+}
+
+// compareEndorsements groups the endorsers returning the same response, the largest group first
+func compareEndorsements(txID string, endorsers []PeerEndorsement) *Comparison {
+	var keys []string
+	items := map[string]map[string]string{}
+	count := map[string]int{}
+	endorserKeys := make([]string, len(endorsers))
+	for i, e := range endorsers {
+		item := endorsementItems(e)
+		key := fmt.Sprintf("%q", item)
+		if _, ok := items[key]; !ok {
+			keys = append(keys, key)
+			items[key] = item
+		}
+		count[key]++
+		endorserKeys[i] = key
+	}
+	sort.SliceStable(keys, func(i, j int) bool {
+		return count[keys[i]] > count[keys[j]]
+	})
+
+	comparison := &Comparison{TxID: txID, Differences: map[int][]string{}}
+	group := map[string]int{}
+	for i, key := range keys {
+		group[key] = i
+		if i > 0 {
+			comparison.Differences[i] = diffItems(items[keys[0]], items[key])
+		}
+	}
+	for i, e := range endorsers {
+		e.Group = group[endorserKeys[i]]
+		comparison.Endorsers = append(comparison.Endorsers, e)
+	}
+	return comparison
+}
+
+// endorsementItems flattens the response of a peer into the items that are compared
+func endorsementItems(e PeerEndorsement) map[string]string {
+	if e.Err != nil {
+		return map[string]string{"error": errorItem(e.Err)}
+	}
+	endorsement := e.Endorsement
+	items := map[string]string{
+		"status":            fmt.Sprintf("%d %s", endorsement.Status, endorsement.Message),
+		"chaincode version": endorsement.ChaincodeID.GetVersion(),
+		"payload":           strconv.Quote(string(endorsement.Payload)),
+	}
+	if event := endorsement.Event; event != nil {
+		items["event"] = fmt.Sprintf("%s %q", event.EventName, event.Payload)
+	}
+	for _, ns := range endorsement.RWSets {
+		for _, read := range ns.KVRWSet.Reads {
+			items[ns.Namespace+" read "+read.Key] = versionString(read.Version)
+		}
+		for _, rq := range ns.KVRWSet.RangeQueriesInfo {
+			items[fmt.Sprintf("%s range [%s,%s)", ns.Namespace, rq.StartKey, rq.EndKey)] =
+				fmt.Sprintf("%d read(s), exhausted %t", len(rq.GetRawReads().GetKvReads()), rq.ItrExhausted)
+		}
+		for _, write := range ns.KVRWSet.Writes {
+			value := strconv.Quote(string(write.Value))
+			if write.IsDelete {
+				value = "<delete>"
+			}
+			items[ns.Namespace+" write "+write.Key] = value
+		}
+		for _, coll := range ns.Collections {
+			for _, read := range coll.HashedRWSet.HashedReads {
+				items[fmt.Sprintf("%s/%s read %x", ns.Namespace, coll.Collection, read.KeyHash)] = versionString(read.Version)
+			}
+			for _, write := range coll.HashedRWSet.HashedWrites {
+				value := hex.EncodeToString(write.ValueHash)
+				if write.IsDelete {
+					value = "<delete>"
+				}
+				items[fmt.Sprintf("%s/%s write %x", ns.Namespace, coll.Collection, write.KeyHash)] = value
+			}
+		}
+	}
+	return items
+}
+
+// errorItem returns the error of a peer without what is specific to the peer, such as its URL, so that
+// the peers failing the same way are grouped together. The full error is kept in the INFO column
+func errorItem(err error) string {
+	cause := errors.Cause(err)
+	if errs, ok := cause.(multi.Errors); ok && len(errs) == 1 {
+		cause = errors.Cause(errs[0])
+	}
+	s, ok := cause.(*status.Status)
+	if !ok {
+		return err.Error()
+	}
+	if s.Group == status.GRPCTransportStatus || s.Group == status.EndorserClientStatus && s.Code == status.ConnectionFailed.ToInt32() {
+		// the message of a connection error holds the address of the peer
+		return fmt.Sprintf("%s Code: (%d)", s.Group, s.Code)
+	}
+	return fmt.Sprintf("%s Code: (%d) %s", s.Group, s.Code, s.Message)
+}
+
+// diffItems returns the items of other that differ from the reference
+func diffItems(reference, other map[string]string) []string {
+	var names []string
+	for name := range reference {
+		names = append(names, name)
+	}
+	for name := range other {
+		if _, ok := reference[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var diffs []string
+	for _, name := range names {
+		ref, inRef := reference[name]
+		value, inOther := other[name]
+		switch {
+		case !inOther:
+			diffs = append(diffs, fmt.Sprintf("%s: missing, expecting %s", name, truncate(ref)))
+		case !inRef:
+			diffs = append(diffs, fmt.Sprintf("%s: unexpected %s", name, truncate(value)))
+		case ref != value:
+			diffs = append(diffs, fmt.Sprintf("%s: %s, expecting %s", name, truncate(value), truncate(ref)))
+		}
+	}
+	return diffs
+}
+
+func truncate(value string) string {
+	if len(value) > maxValueLength {
+		return value[:maxValueLength] + "..."
+	}
+	return value
+}
+
+// Print prints the group of each peer and the differences of each group from group 0
+func (c *Comparison) Print() {
+	var rows [][]string
+	for _, e := range c.Endorsers {
+		status, version, info := "", "", ""
+		if e.Err != nil {
+			info = e.Err.Error()
+		} else {
+			status, version = strconv.Itoa(int(e.Endorsement.Status)), e.Endorsement.ChaincodeID.GetVersion()
+			info = e.Endorsement.Message
+		}
+		rows = append(rows, []string{e.OrgID, e.Peer, strconv.Itoa(e.Group), status, version, info})
+	}
+	printer.Table([]string{"ORG", "PEER", "GROUP", "STATUS", "VERSION", "INFO"}, rows)
+
+	if c.Consistent() {
+		fmt.Printf("\n*** All %d peer(s) returned the same response to transaction %s\n", len(c.Endorsers), c.TxID)
+		return
+	}
+	for group := 1; group <= len(c.Differences); group++ {
+		fmt.Printf("\n*** Group %d differs from group 0:\n", group)
+		for _, diff := range c.Differences[group] {
+			fmt.Printf("- %s\n", diff)
+		}
+	}
+}
+
+// errDiverged is returned by the compare command so that a divergence fails the command
+var errDiverged = errors.New("the peers returned different responses to the same proposal")
@@ -0,0 +1,81 @@
+package chaincode
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric-protos-go/ledger/rwset/kvrwset"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/multi"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
+	"github.com/pkg/errors"
+)
+
+func newPeerEndorsement(peer, version, value string) PeerEndorsement {
+	return PeerEndorsement{OrgID: "org1", Peer: peer, Endorsement: &Endorsement{
+		Endorser:    peer,
+		Status:      200,
+		ChaincodeID: &pb.ChaincodeID{Name: "mycc", Version: version},
+		Payload:     []byte("ok"),
+		RWSets: []NsRWSet{{Namespace: "mycc", KVRWSet: &kvrwset.KVRWSet{
+			Writes: []*kvrwset.KVWrite{{Key: "A", Value: []byte(value)}},
+		}}},
+	}}
+}
+
+func TestCompareEndorsements(t *testing.T) {
+	comparison := compareEndorsements("tx1", []PeerEndorsement{
+		newPeerEndorsement("peer0", "1.0", "90"),
+		newPeerEndorsement("peer1", "1.0", "90"),
+	})
+	if !comparison.Consistent() || comparison.Endorsers[1].Group != 0 {
+		t.Fatalf("expecting consistent endorsements, got %+v", comparison)
+	}
+
+	comparison = compareEndorsements("tx1", []PeerEndorsement{
+		newPeerEndorsement("peer0", "1.1", "91"),
+		newPeerEndorsement("peer1", "1.0", "90"),
+		newPeerEndorsement("peer2", "1.0", "90"),
+		{OrgID: "org2", Peer: "peer3", Err: errors.New("chaincode mycc not found")},
+	})
+	if comparison.Consistent() || len(comparison.Differences) != 2 {
+		t.Fatalf("expecting 2 diverging groups, got %+v", comparison.Differences)
+	}
+	if groups := []int{comparison.Endorsers[0].Group, comparison.Endorsers[1].Group, comparison.Endorsers[3].Group}; groups[0] != 1 || groups[1] != 0 || groups[2] != 2 {
+		t.Fatalf("expecting the majority in group 0, got groups %v", groups)
+	}
+	diffs := strings.Join(comparison.Differences[1], "\n")
+	if !strings.Contains(diffs, `chaincode version: 1.1, expecting 1.0`) || !strings.Contains(diffs, `mycc write A: "91", expecting "90"`) {
+		t.Fatalf("unexpected differences:\n%s", diffs)
+	}
+	if diffs := comparison.Differences[2]; len(diffs) == 0 || !strings.HasPrefix(diffs[0], "chaincode version: missing") {
+		t.Fatalf("unexpected differences %v", diffs)
+	}
+}
+
+func TestCompareEndorsements_Errors(t *testing.T) {
+	// the errors of the SDK hold the URL of the endorser
+	endorserErr := func(peer string, code int32, message string) error {
+		return multi.Errors{errors.Wrapf(status.New(status.ChaincodeStatus, code, message, []interface{}{peer}),
+			"Transaction processing for endorser [%s]", peer)}
+	}
+	connectionErr := func(peer string) error {
+		return multi.Errors{errors.Wrapf(status.New(status.EndorserClientStatus, status.ConnectionFailed.ToInt32(),
+			"dial tcp "+peer+": connect: connection refused", []interface{}{peer}), "Transaction processing for endorser [%s]", peer)}
+	}
+	comparison := compareEndorsements("tx1", []PeerEndorsement{
+		{OrgID: "org1", Peer: "peer0:7051", Err: endorserErr("peer0:7051", 500, "make sure the chaincode mycc has been successfully defined")},
+		{OrgID: "org2", Peer: "peer1:7051", Err: endorserErr("peer1:7051", 500, "make sure the chaincode mycc has been successfully defined")},
+		{OrgID: "org1", Peer: "peer2:7051", Err: connectionErr("peer2:7051")},
+		{OrgID: "org2", Peer: "peer3:7051", Err: connectionErr("peer3:7051")},
+	})
+	if groups := []int{comparison.Endorsers[0].Group, comparison.Endorsers[1].Group, comparison.Endorsers[2].Group, comparison.Endorsers[3].Group}; groups[0] != groups[1] || groups[2] != groups[3] || groups[0] == groups[2] {
+		t.Fatalf("expecting the peers failing the same way grouped together, got groups %v", groups)
+	}
+	if len(comparison.Differences) != 1 {
+		t.Fatalf("expecting 1 diverging group, got %+v", comparison.Differences)
+	}
+	if !strings.Contains(comparison.Endorsers[1].Err.Error(), "peer1:7051") {
+		t.Fatalf("expecting the full error kept, got %s", comparison.Endorsers[1].Err)
+	}
+}
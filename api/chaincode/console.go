@@ -0,0 +1,128 @@
+package chaincode
+
+import (
+	"reflect"
+
+	"github.com/zhcppy/fabricli/api"
+	"github.com/zhcppy/fabricli/console"
+)
+
+// consoleAction is the chaincode action of the console, its invoke and query take the default options
+type consoleAction struct {
+	*CCAction
+}
+
+func (cc consoleAction) Invoke(chaincodeID string, args string) error {
+	return cc.CCAction.Invoke(chaincodeID, args, DefaultInvokeOptions())
+}
+
+func (cc consoleAction) QueryInfo(chaincodeID string, args string) error {
+	return cc.CCAction.QueryInfo(chaincodeID, args, DefaultInvokeOptions())
+}
+
+// Namespace returns the cc namespace of the console, calling the methods of the chaincode action
+func Namespace(c *api.Config) console.Namespace {
+	return console.Namespace{
+		Name: "cc",
+		Type: reflect.TypeOf(consoleAction{}),
+		New: func() (interface{}, error) {
+			action, err := NewCCAction(c)
+			if err != nil {
+				return nil, err
+			}
+			return consoleAction{action}, nil
+		},
+		Docs: docs,
+	}
+}
+
+var docs = map[string]console.Doc{
+	"Invoke": {
+		Params:      []string{"chaincodeID", "args"},
+		Description: "Invokes the chaincode once with the args, a JSON object or an array of them",
+		Example:     `cc.Invoke(mycc, {"Func":"move","Args":["A","B","1"]})`,
+	},
+	"QueryInfo": {
+		Params:      []string{"chaincodeID", "args"},
+		Description: "Queries the chaincode once with the args, a JSON object or an array of them",
+		Example:     `cc.QueryInfo(mycc, {"Func":"query","Args":["A"]})`,
+	},
+	"DryRun": {
+		Params:      []string{"chaincodeID", "args"},
+		Description: "Endorses the args without submitting the transactions and prints what they would read and write",
+		Example:     `cc.DryRun(mycc, {"Func":"move","Args":["A","B","1"]})`,
+	},
+	"Compare": {
+		Params:      []string{"chaincodeID", "args", "orgIDs", "peerURLs"},
+		Description: "Endorses the args on each peer of the orgs and compares their responses",
+		Example:     `cc.Compare(mycc, {"Func":"query","Args":["A"]}, ["Org1","Org2"], [])`,
+	},
+	"Install": {
+		Params:      []string{"info", "orgIDs", "peerURLs"},
+		Description: "Installs the chaincode on the peers of the orgs, filtered by the peer URLs",
+		Example:     `cc.Install({"ChaincodeId":"mycc","ChaincodePath":"github.com/example_cc","ChaincodeVersion":"v0"}, ["Org1"], [])`,
+	},
+	"InstallPackage": {
+		Params:      []string{"file", "orgIDs", "peerURLs"},
+		Description: "Installs the chaincode package file as-is on the peers of the orgs, filtered by the peer URLs",
+		Example:     `cc.InstallPackage(mycc.cds, ["Org1","Org2"], [])`,
+	},
+	"Upgrade": {
+		Params:      []string{"info", "orgIDs", "peerURLs"},
+		Description: "Upgrades the chaincode on the channel, endorsed by the peers of the orgs",
+		Example:     `cc.Upgrade({"ChaincodeId":"mycc","ChaincodePath":"github.com/example_cc","ChaincodeVersion":"v1"}, ["Org1"], [])`,
+	},
+	"Instantiate": {
+		Params:      []string{"info"},
+		Description: "Instantiates the chaincode on the channel",
+		Example:     `cc.Instantiate({"ChaincodeId":"mycc","ChaincodePath":"github.com/example_cc","ChaincodeVersion":"v0","ChaincodeArgs":"{\"Func\":\"init\",\"Args\":[\"A\",\"1\",\"B\",\"2\"]}"})`,
+	},
+	"QueryPrivateData": {
+		Params:      []string{"query", "keys", "orgIDs", "peerURLs"},
+		Description: "Reads the private data keys from each peer of the orgs, filtered by the peer URLs",
+		Example:     `cc.QueryPrivateData({"ChaincodeID":"marbles","Collection":"collectionMarbles","Func":"readMarble"}, ["marble1"], ["Org1"], [])`,
+	},
+	"LifecycleInstall": {
+		Params:      []string{"file", "orgIDs", "peerURLs"},
+		Description: "Installs the lifecycle package file on the peers of the orgs",
+		Example:     `cc.LifecycleInstall(mycc.tar.gz, ["Org1","Org2"], [])`,
+	},
+	"LifecycleQueryInstalled": {
+		Params:      []string{"orgIDs", "peerURLs"},
+		Description: "Returns the lifecycle packages installed on the peers of the orgs",
+		Example:     `cc.LifecycleQueryInstalled(["Org1"], [])`,
+	},
+	"ApproveForMyOrg": {
+		Params:      []string{"def", "orgIDs", "peerURLs"},
+		Description: "Approves the chaincode definition for each of the orgs",
+		Example:     `cc.ApproveForMyOrg({"Name":"mycc","Version":"1.0","Sequence":1,"PackageID":"mycc_1:a1b2"}, ["Org1"], [])`,
+	},
+	"CheckCommitReadiness": {
+		Params:      []string{"def", "orgIDs", "peerURLs"},
+		Description: "Returns the approval of each org for the chaincode definition",
+		Example:     `cc.CheckCommitReadiness({"Name":"mycc","Version":"1.0","Sequence":1}, ["Org1"], [])`,
+	},
+	"Commit": {
+		Params:      []string{"def", "orgIDs", "peerURLs"},
+		Description: "Commits the chaincode definition to the channel, endorsed by the peers of the orgs",
+		Example:     `cc.Commit({"Name":"mycc","Version":"1.0","Sequence":1}, ["Org1","Org2"], [])`,
+	},
+	"QueryApproved": {
+		Params:      []string{"name", "sequence", "orgIDs", "peerURLs"},
+		Description: "Returns the chaincode definition approved by the org of the target peer, sequence 0 for the latest",
+		Example:     `cc.QueryApproved(mycc, 0, ["Org1"], [])`,
+	},
+	"QueryCommitted": {
+		Params:      []string{"name", "orgIDs", "peerURLs"},
+		Description: "Returns the chaincode definitions committed on the channel, all of them when the name is empty",
+		Example:     `cc.QueryCommitted(mycc, ["Org1"], [])`,
+	},
+	"Replay": {
+		Params:      []string{"calls", "chaincodeID", "originalTiming", "opts"},
+		Description: "Replays the recorded calls, see chaincode replay",
+	},
+	"RunScenario": {
+		Params:      []string{"scenario", "opts"},
+		Description: "Runs the scenario, see chaincode scenario",
+	},
+}
@@ -0,0 +1,35 @@
+package chaincode
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/zhcppy/fabricli/api"
+	"github.com/zhcppy/fabricli/console"
+)
+
+func TestNamespace_Complete(t *testing.T) {
+	namespaces := console.Namespaces{Namespace(&api.Config{})}.WithValues(map[string][]string{
+		"chaincodeID": {"marbles", "mycc"},
+		"peerURL":     {"grpcs://localhost:7051", "grpcs://localhost:9051"},
+		"orgID":       {"Org1", "Org2"},
+	})
+	for _, tc := range []struct {
+		line        string
+		head        string
+		completions []string
+	}{
+		{"cc.Invoke(my", "cc.Invoke(", []string{"mycc"}},
+		{`cc.Install({"ChaincodeID":"mycc"}, `, `cc.Install({"ChaincodeID":"mycc"}, `, []string{`["Org1"`, `["Org2"`}},
+		{`cc.Install({"ChaincodeID":"mycc"}, [`, `cc.Install({"ChaincodeID":"mycc"}, [`, []string{`"Org1"`, `"Org2"`}},
+		{`cc.Upgrade({}, ["Org1", "O`, `cc.Upgrade({}, ["Org1", `, []string{`"Org1"`, `"Org2"`}},
+		{`cc.InstallPackage(mycc.pkg, ["Org1"], ["grpcs://localhost:9`, `cc.InstallPackage(mycc.pkg, ["Org1"], [`, []string{`"grpcs://localhost:9051"`}},
+		{`cc.QueryPrivateData({}, [`, `cc.QueryPrivateData({}, [`, nil},
+		{`cc.Install(`, "", nil},
+	} {
+		head, completions, tail := namespaces.Complete(tc.line+")", len([]rune(tc.line)))
+		if head != tc.head || !reflect.DeepEqual(completions, tc.completions) || tail != ")" {
+			t.Fatalf("line [%s]: unexpected completion [%s] %v [%s]", tc.line, head, completions, tail)
+		}
+	}
+}
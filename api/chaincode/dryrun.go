@@ -0,0 +1,204 @@
+package chaincode
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/ledger/rwset"
+	"github.com/hyperledger/fabric-protos-go/ledger/rwset/kvrwset"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel/invoke"
+	"github.com/pkg/errors"
+	"github.com/zhcppy/fabricli/api/chaincode/task"
+	"github.com/zhcppy/fabricli/printer"
+)
+
+// Endorsement is the decoded outcome of a transaction proposal endorsed by a peer
+type Endorsement struct {
+	Endorser    string
+	Status      int32
+	Message     string
+	ChaincodeID *pb.ChaincodeID
+	Payload     []byte
+	Event       *pb.ChaincodeEvent
+	RWSets      []NsRWSet
+}
+
+// NsRWSet is the read/write set of a chaincode namespace
+type NsRWSet struct {
+	Namespace   string
+	KVRWSet     *kvrwset.KVRWSet
+	Collections []CollectionRWSet
+}
+
+// CollectionRWSet is the hashed read/write set of a private data collection,
+// the private data itself is never part of the proposal response
+type CollectionRWSet struct {
+	Collection  string
+	HashedRWSet *kvrwset.HashedRWSet
+}
+
+// DecodeEndorsement decodes the chaincode action of the proposal response of a peer
+func DecodeEndorsement(endorser string, resp *pb.ProposalResponse) (*Endorsement, error) {
+	endorsement := &Endorsement{Endorser: endorser}
+	if resp.Response != nil {
+		endorsement.Status, endorsement.Message = resp.Response.Status, resp.Response.Message
+	}
+	prp := &pb.ProposalResponsePayload{}
+	if err := proto.Unmarshal(resp.Payload, prp); err != nil {
+		return nil, errors.Wrap(err, "unmarshal of proposal response payload failed")
+	}
+	action := &pb.ChaincodeAction{}
+	if err := proto.Unmarshal(prp.Extension, action); err != nil {
+		return nil, errors.Wrap(err, "unmarshal of chaincode action failed")
+	}
+	endorsement.ChaincodeID = action.ChaincodeId
+	if action.Response != nil {
+		endorsement.Payload = action.Response.Payload
+	}
+	if len(action.Events) > 0 {
+		endorsement.Event = &pb.ChaincodeEvent{}
+		if err := proto.Unmarshal(action.Events, endorsement.Event); err != nil {
+			return nil, errors.Wrap(err, "unmarshal of chaincode event failed")
+		}
+	}
+
+	txRWSet := &rwset.TxReadWriteSet{}
+	if err := proto.Unmarshal(action.Results, txRWSet); err != nil {
+		return nil, errors.Wrap(err, "unmarshal of read/write set failed")
+	}
+	for _, ns := range txRWSet.NsRwset {
+		nsRWSet := NsRWSet{Namespace: ns.Namespace, KVRWSet: &kvrwset.KVRWSet{}}
+		if err := proto.Unmarshal(ns.Rwset, nsRWSet.KVRWSet); err != nil {
+			return nil, errors.Wrapf(err, "unmarshal of read/write set of namespace [%s] failed", ns.Namespace)
+		}
+		for _, coll := range ns.CollectionHashedRwset {
+			collRWSet := CollectionRWSet{Collection: coll.CollectionName, HashedRWSet: &kvrwset.HashedRWSet{}}
+			if err := proto.Unmarshal(coll.HashedRwset, collRWSet.HashedRWSet); err != nil {
+				return nil, errors.Wrapf(err, "unmarshal of hashed read/write set of collection [%s] failed", coll.CollectionName)
+			}
+			nsRWSet.Collections = append(nsRWSet.Collections, collRWSet)
+		}
+		endorsement.RWSets = append(endorsement.RWSets, nsRWSet)
+	}
+	return endorsement, nil
+}
+
+// DryRun endorses the proposals of the args, a JSON object or an array of them, without sending the
+// transactions to the orderer and prints what they would read and write
+func (cc *CCAction) DryRun(chaincodeID string, args string) error {
+	argsArray, err := newInvokeArgs(args)
+	if err != nil {
+		return err
+	}
+	ctxt := task.NewContext()
+	for _, arg := range argsArray {
+		ccArgs, transient, err := arg.Evaluate(ctxt)
+		if err != nil {
+			return err
+		}
+		response, err := cc.channelClient.InvokeHandler(
+			invoke.NewSelectAndEndorseHandler(
+				invoke.NewEndorsementValidationHandler(
+					invoke.NewSignatureValidationHandler(),
+				),
+			),
+			channel.Request{
+				ChaincodeID:  chaincodeID,
+				Fcn:          arg.Func,
+				Args:         ccArgs,
+				TransientMap: transient,
+			},
+		)
+		if err != nil {
+			return errors.Wrapf(err, "endorsement of %s failed", arg.Func)
+		}
+		if len(response.Responses) == 0 {
+			return errors.Errorf("no endorsement returned for %s", arg.Func)
+		}
+
+		var endorsements []*Endorsement
+		for _, resp := range response.Responses {
+			endorsement, err := DecodeEndorsement(resp.Endorser, resp.ProposalResponse)
+			if err != nil {
+				return errors.WithMessagef(err, "invalid proposal response from %s", resp.Endorser)
+			}
+			endorsements = append(endorsements, endorsement)
+		}
+		fmt.Printf("\n*** Dry run of %s, transaction %s was not submitted\n", arg.Func, response.TransactionID)
+		printEndorsements(endorsements)
+	}
+	return nil
+}
+
+// printEndorsements prints the endorsers and the outcome of the first endorsement,
+// the endorsement validation handler already checked that they all match
+func printEndorsements(endorsements []*Endorsement) {
+	var rows [][]string
+	for _, e := range endorsements {
+		rows = append(rows, []string{e.Endorser, strconv.Itoa(int(e.Status)), e.Message})
+	}
+	printer.Table([]string{"ENDORSER", "STATUS", "MESSAGE"}, rows)
+
+	e := endorsements[0]
+	fmt.Printf("\nPayload: %s\n", e.Payload)
+	if e.Event != nil {
+		fmt.Printf("\nEvent: %s, chaincode: %s, payload: %s\n", e.Event.EventName, e.Event.ChaincodeId, e.Event.Payload)
+	}
+	for _, ns := range e.RWSets {
+		printNsRWSet(ns)
+	}
+}
+
+func printNsRWSet(ns NsRWSet) {
+	if len(ns.KVRWSet.Reads) == 0 && len(ns.KVRWSet.Writes) == 0 && len(ns.KVRWSet.RangeQueriesInfo) == 0 && len(ns.Collections) == 0 {
+		return
+	}
+	fmt.Printf("\nNamespace: %s\n", ns.Namespace)
+	if len(ns.KVRWSet.Reads) > 0 {
+		var rows [][]string
+		for _, read := range ns.KVRWSet.Reads {
+			rows = append(rows, []string{read.Key, versionString(read.Version)})
+		}
+		printer.Table([]string{"READ KEY", "VERSION"}, rows)
+	}
+	if len(ns.KVRWSet.RangeQueriesInfo) > 0 {
+		var rows [][]string
+		for _, rq := range ns.KVRWSet.RangeQueriesInfo {
+			rows = append(rows, []string{rq.StartKey, rq.EndKey, strconv.FormatBool(rq.ItrExhausted),
+				strconv.Itoa(len(rq.GetRawReads().GetKvReads()))})
+		}
+		printer.Table([]string{"RANGE START", "RANGE END", "EXHAUSTED", "READS"}, rows)
+	}
+	if len(ns.KVRWSet.Writes) > 0 {
+		var rows [][]string
+		for _, write := range ns.KVRWSet.Writes {
+			rows = append(rows, []string{write.Key, strconv.FormatBool(write.IsDelete), string(write.Value)})
+		}
+		printer.Table([]string{"WRITE KEY", "DELETE", "VALUE"}, rows)
+	}
+	for _, coll := range ns.Collections {
+		var rows [][]string
+		for _, read := range coll.HashedRWSet.HashedReads {
+			rows = append(rows, []string{coll.Collection, "read", hex.EncodeToString(read.KeyHash), versionString(read.Version)})
+		}
+		for _, write := range coll.HashedRWSet.HashedWrites {
+			op := "write"
+			if write.IsDelete {
+				op = "delete"
+			}
+			rows = append(rows, []string{coll.Collection, op, hex.EncodeToString(write.KeyHash), hex.EncodeToString(write.ValueHash)})
+		}
+		printer.Table([]string{"COLLECTION", "OPERATION", "KEY HASH", "VERSION/VALUE HASH"}, rows)
+	}
+}
+
+func versionString(version *kvrwset.Version) string {
+	if version == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%d:%d", version.BlockNum, version.TxNum)
+}
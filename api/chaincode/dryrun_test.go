@@ -0,0 +1,61 @@
+package chaincode
+
+import (
+	"testing"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/ledger/rwset"
+	"github.com/hyperledger/fabric-protos-go/ledger/rwset/kvrwset"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+)
+
+func mustMarshal(t *testing.T, msg proto.Message) []byte {
+	bytes, err := proto.Marshal(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bytes
+}
+
+func TestDecodeEndorsement(t *testing.T) {
+	kvRWSet := &kvrwset.KVRWSet{
+		Reads:  []*kvrwset.KVRead{{Key: "A", Version: &kvrwset.Version{BlockNum: 3, TxNum: 1}}},
+		Writes: []*kvrwset.KVWrite{{Key: "A", Value: []byte("90")}},
+	}
+	hashedRWSet := &kvrwset.HashedRWSet{HashedWrites: []*kvrwset.KVWriteHash{{KeyHash: []byte{1}, ValueHash: []byte{2}}}}
+	txRWSet := &rwset.TxReadWriteSet{NsRwset: []*rwset.NsReadWriteSet{{
+		Namespace: "mycc",
+		Rwset:     mustMarshal(t, kvRWSet),
+		CollectionHashedRwset: []*rwset.CollectionHashedReadWriteSet{
+			{CollectionName: "coll", HashedRwset: mustMarshal(t, hashedRWSet)},
+		},
+	}}}
+	action := &pb.ChaincodeAction{
+		Results:  mustMarshal(t, txRWSet),
+		Events:   mustMarshal(t, &pb.ChaincodeEvent{ChaincodeId: "mycc", EventName: "moved"}),
+		Response: &pb.Response{Status: 200, Payload: []byte("ok")},
+	}
+	resp := &pb.ProposalResponse{
+		Response: &pb.Response{Status: 200},
+		Payload:  mustMarshal(t, &pb.ProposalResponsePayload{Extension: mustMarshal(t, action)}),
+	}
+
+	e, err := DecodeEndorsement("peer0", resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Endorser != "peer0" || e.Status != 200 || string(e.Payload) != "ok" || e.Event.GetEventName() != "moved" {
+		t.Fatalf("unexpected endorsement %+v", e)
+	}
+	if len(e.RWSets) != 1 || e.RWSets[0].Namespace != "mycc" || len(e.RWSets[0].KVRWSet.Writes) != 1 ||
+		string(e.RWSets[0].KVRWSet.Writes[0].Value) != "90" || versionString(e.RWSets[0].KVRWSet.Reads[0].Version) != "3:1" {
+		t.Fatalf("unexpected read/write sets %+v", e.RWSets)
+	}
+	if colls := e.RWSets[0].Collections; len(colls) != 1 || colls[0].Collection != "coll" || len(colls[0].HashedRWSet.HashedWrites) != 1 {
+		t.Fatalf("unexpected collection read/write sets %+v", colls)
+	}
+
+	if _, err := DecodeEndorsement("peer0", &pb.ProposalResponse{Payload: []byte("invalid")}); err == nil {
+		t.Fatal("expecting error decoding an invalid payload")
+	}
+}
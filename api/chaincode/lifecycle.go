@@ -0,0 +1,606 @@
+package chaincode
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/common"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	lb "github.com/hyperledger/fabric-protos-go/peer/lifecycle"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/pkg/errors"
+	"github.com/zhcppy/fabricli/api"
+	"github.com/zhcppy/fabricli/api/chaincode/cauthdsl"
+	"github.com/zhcppy/fabricli/api/chaincode/ccpackager"
+	"github.com/zhcppy/fabricli/logger"
+)
+
+// The resource management client of the SDK only knows the legacy lscc lifecycle,
+// so the fabric 2.x _lifecycle system chaincode is called directly
+
+const (
+	lifecycleName                     = "_lifecycle"
+	installFuncName                   = "InstallChaincode"
+	queryInstalledFuncName            = "QueryInstalledChaincodes"
+	approveFuncName                   = "ApproveChaincodeDefinitionForMyOrg"
+	checkCommitReadinessFuncName      = "CheckCommitReadiness"
+	commitFuncName                    = "CommitChaincodeDefinition"
+	queryApprovedFuncName             = "QueryApprovedChaincodeDefinition"
+	queryChaincodeDefinitionFuncName  = "QueryChaincodeDefinition"
+	queryChaincodeDefinitionsFuncName = "QueryChaincodeDefinitions"
+
+	metadataFile = "metadata.json"
+	codeFile     = "code.tar.gz"
+)
+
+var labelRegexp = regexp.MustCompile(`^[[:alnum:]][[:alnum:]_.+-]*$`)
+
+// LifecycleMetadata is the metadata.json of a lifecycle chaincode package
+type LifecycleMetadata struct {
+	Path  string `json:"path"`
+	Type  string `json:"type"`
+	Label string `json:"label"`
+}
+
+// LifecyclePackage packages the chaincode source into a .tar.gz lifecycle package
+// holding the metadata.json and the code.tar.gz, the same way `peer lifecycle chaincode package` does
+func LifecyclePackage(info api.CCodeInfo, label string) ([]byte, error) {
+	if !labelRegexp.MatchString(label) {
+		return nil, errors.Errorf("invalid label [%s], the label must match %s", label, labelRegexp.String())
+	}
+	if info.ChaincodePath == "" {
+		return nil, errors.New("chaincode path is required")
+	}
+	ccPkg, err := ccpackager.NewCCPackage(info.ChaincodeLang, info.ChaincodePath, info.GoPath)
+	if err != nil {
+		return nil, err
+	}
+	metadata, err := json.Marshal(&LifecycleMetadata{
+		Path:  info.ChaincodePath,
+		Type:  strings.ToLower(ccPkg.Type.String()),
+		Label: label,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal of package metadata failed")
+	}
+
+	payload := bytes.NewBuffer(nil)
+	gw := gzip.NewWriter(payload)
+	tw := tar.NewWriter(gw)
+	for _, file := range []struct {
+		name string
+		body []byte
+	}{{metadataFile, metadata}, {codeFile, ccPkg.Code}} {
+		header := &tar.Header{Name: file.name, Size: int64(len(file.body)), Mode: 0100644}
+		if err := tw.WriteHeader(header); err != nil {
+			return nil, errors.Wrapf(err, "error writing header of %s", file.name)
+		}
+		if _, err := tw.Write(file.body); err != nil {
+			return nil, errors.Wrapf(err, "error writing %s", file.name)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gw.Close(); err != nil {
+		return nil, err
+	}
+	return payload.Bytes(), nil
+}
+
+// PackageID returns the package ID of the lifecycle package, that is <label>:<sha256 of the package>
+func PackageID(label string, pkgBytes []byte) string {
+	return fmt.Sprintf("%s:%x", label, sha256.Sum256(pkgBytes))
+}
+
+// ReadLifecyclePackage reads a lifecycle package file and returns its raw bytes together with its metadata
+func ReadLifecyclePackage(file string) ([]byte, *LifecycleMetadata, error) {
+	pkgBytes, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "could not read chaincode package [%s]", file)
+	}
+	gr, err := gzip.NewReader(bytes.NewReader(pkgBytes))
+	if err != nil {
+		return nil, nil, errors.Errorf("[%s] is not a lifecycle chaincode package: %s", file, err)
+	}
+	tr := tar.NewReader(gr)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil, nil, errors.Errorf("[%s] is not a lifecycle chaincode package: %s not found", file, metadataFile)
+		}
+		if err != nil {
+			return nil, nil, errors.Errorf("[%s] is not a lifecycle chaincode package: %s", file, err)
+		}
+		if header.Name != metadataFile {
+			continue
+		}
+		metadata := &LifecycleMetadata{}
+		if err := json.NewDecoder(tr).Decode(metadata); err != nil {
+			return nil, nil, errors.Wrapf(err, "invalid %s in chaincode package [%s]", metadataFile, file)
+		}
+		return pkgBytes, metadata, nil
+	}
+}
+
+// ChaincodeDefinition is the chaincode definition approved by the orgs and committed to the channel
+type ChaincodeDefinition struct {
+	Name                 string
+	Version              string
+	Sequence             int64
+	PackageID            string
+	SignaturePolicy      string
+	ChannelConfigPolicy  string
+	CollectionConfigFile string
+	InitRequired         bool
+}
+
+// NewChaincodeDefinition returns the chaincode definition of the chaincode info
+func NewChaincodeDefinition(info api.CCodeInfo, sequence int64, packageID, channelConfigPolicy string, initRequired bool) *ChaincodeDefinition {
+	return &ChaincodeDefinition{
+		Name:                 info.ChaincodeID,
+		Version:              info.ChaincodeVersion,
+		Sequence:             sequence,
+		PackageID:            packageID,
+		SignaturePolicy:      info.ChaincodePolicy,
+		ChannelConfigPolicy:  channelConfigPolicy,
+		CollectionConfigFile: info.CollectionConfigFile,
+		InitRequired:         initRequired,
+	}
+}
+
+// validationParameter returns the marshaled endorsement policy, nil lets the peer apply
+// the channel default /Channel/Application/Endorsement
+func (d *ChaincodeDefinition) validationParameter() ([]byte, error) {
+	switch {
+	case d.SignaturePolicy != "" && d.ChannelConfigPolicy != "":
+		return nil, errors.New("signature policy and channel config policy cannot both be set")
+	case d.SignaturePolicy != "":
+		policy, err := cauthdsl.FromString(d.SignaturePolicy)
+		if err != nil {
+			return nil, errors.Errorf("invalid signature policy [%s]: %s", d.SignaturePolicy, err)
+		}
+		return proto.Marshal(&pb.ApplicationPolicy{Type: &pb.ApplicationPolicy_SignaturePolicy{SignaturePolicy: policy}})
+	case d.ChannelConfigPolicy != "":
+		return proto.Marshal(&pb.ApplicationPolicy{Type: &pb.ApplicationPolicy_ChannelConfigPolicyReference{ChannelConfigPolicyReference: d.ChannelConfigPolicy}})
+	}
+	return nil, nil
+}
+
+func (d *ChaincodeDefinition) collections() (*common.CollectionConfigPackage, error) {
+	collConfig, err := getCollectionConfigFromFile(d.CollectionConfigFile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error getting private data collection configuration from file [%s]", d.CollectionConfigFile)
+	}
+	if collConfig == nil {
+		return nil, nil
+	}
+	return &common.CollectionConfigPackage{Config: collConfig}, nil
+}
+
+func (d *ChaincodeDefinition) check() error {
+	if d.Name == "" || d.Version == "" {
+		return errors.New("chaincode name and version are required")
+	}
+	if d.Sequence < 1 {
+		return errors.Errorf("invalid sequence %d, the sequence starts from 1", d.Sequence)
+	}
+	return nil
+}
+
+// ApproveArgs returns the marshaled arguments of ApproveChaincodeDefinitionForMyOrg,
+// without a package ID the approved chaincode is unavailable on the peers of the org
+func (d *ChaincodeDefinition) ApproveArgs() ([]byte, error) {
+	if err := d.check(); err != nil {
+		return nil, err
+	}
+	validationParameter, err := d.validationParameter()
+	if err != nil {
+		return nil, err
+	}
+	collections, err := d.collections()
+	if err != nil {
+		return nil, err
+	}
+	source := &lb.ChaincodeSource{Type: &lb.ChaincodeSource_Unavailable_{Unavailable: &lb.ChaincodeSource_Unavailable{}}}
+	if d.PackageID != "" {
+		source = &lb.ChaincodeSource{Type: &lb.ChaincodeSource_LocalPackage{LocalPackage: &lb.ChaincodeSource_Local{PackageId: d.PackageID}}}
+	}
+	return proto.Marshal(&lb.ApproveChaincodeDefinitionForMyOrgArgs{
+		Name:                d.Name,
+		Version:             d.Version,
+		Sequence:            d.Sequence,
+		EndorsementPlugin:   escc,
+		ValidationPlugin:    vscc,
+		ValidationParameter: validationParameter,
+		Collections:         collections,
+		InitRequired:        d.InitRequired,
+		Source:              source,
+	})
+}
+
+// CommitArgs returns the marshaled arguments of CommitChaincodeDefinition
+func (d *ChaincodeDefinition) CommitArgs() ([]byte, error) {
+	if err := d.check(); err != nil {
+		return nil, err
+	}
+	validationParameter, err := d.validationParameter()
+	if err != nil {
+		return nil, err
+	}
+	collections, err := d.collections()
+	if err != nil {
+		return nil, err
+	}
+	return proto.Marshal(&lb.CommitChaincodeDefinitionArgs{
+		Name:                d.Name,
+		Version:             d.Version,
+		Sequence:            d.Sequence,
+		EndorsementPlugin:   escc,
+		ValidationPlugin:    vscc,
+		ValidationParameter: validationParameter,
+		Collections:         collections,
+		InitRequired:        d.InitRequired,
+	})
+}
+
+// CheckCommitReadinessArgs returns the marshaled arguments of CheckCommitReadiness
+func (d *ChaincodeDefinition) CheckCommitReadinessArgs() ([]byte, error) {
+	if err := d.check(); err != nil {
+		return nil, err
+	}
+	validationParameter, err := d.validationParameter()
+	if err != nil {
+		return nil, err
+	}
+	collections, err := d.collections()
+	if err != nil {
+		return nil, err
+	}
+	return proto.Marshal(&lb.CheckCommitReadinessArgs{
+		Name:                d.Name,
+		Version:             d.Version,
+		Sequence:            d.Sequence,
+		EndorsementPlugin:   escc,
+		ValidationPlugin:    vscc,
+		ValidationParameter: validationParameter,
+		Collections:         collections,
+		InitRequired:        d.InitRequired,
+	})
+}
+
+// LifecycleInstall installs the lifecycle package file on the peers of the given orgs
+func (cc *CCAction) LifecycleInstall(file string, orgIDs, peerURLs []string) ([]PeerResult, error) {
+	pkgBytes, metadata, err := ReadLifecyclePackage(file)
+	if err != nil {
+		return nil, err
+	}
+	args, err := proto.Marshal(&lb.InstallChaincodeArgs{ChaincodeInstallPackage: pkgBytes})
+	if err != nil {
+		return nil, errors.WithMessage(err, "marshal of install chaincode args failed")
+	}
+	packageID := PackageID(metadata.Label, pkgBytes)
+	results, err := cc.sendLifecycleProposal(installFuncName, args, orgIDs, peerURLs, func(payload []byte) (string, error) {
+		result := &lb.InstallChaincodeResult{}
+		if err := proto.Unmarshal(payload, result); err != nil {
+			return "", errors.Wrap(err, "failed to unmarshal install chaincode result")
+		}
+		return result.PackageId, nil
+	})
+	for i := range results {
+		if results[i].Status == "ok" {
+			results[i].Status = "installed"
+		} else if strings.Contains(results[i].Info, "chaincode already successfully installed") {
+			results[i].Status, results[i].Info = "installed", packageID
+		}
+	}
+	return results, err
+}
+
+// LifecycleQueryInstalled returns the packages installed on the peers of the given orgs
+func (cc *CCAction) LifecycleQueryInstalled(orgIDs, peerURLs []string) ([]PeerResult, error) {
+	args, err := proto.Marshal(&lb.QueryInstalledChaincodesArgs{})
+	if err != nil {
+		return nil, errors.WithMessage(err, "marshal of query installed chaincodes args failed")
+	}
+	return cc.sendLifecycleProposal(queryInstalledFuncName, args, orgIDs, peerURLs, func(payload []byte) (string, error) {
+		result := &lb.QueryInstalledChaincodesResult{}
+		if err := proto.Unmarshal(payload, result); err != nil {
+			return "", errors.Wrap(err, "failed to unmarshal query installed chaincodes result")
+		}
+		var packageIDs []string
+		for _, installed := range result.InstalledChaincodes {
+			packageIDs = append(packageIDs, installed.PackageId)
+		}
+		return strings.Join(packageIDs, ", "), nil
+	})
+}
+
+// sendLifecycleProposal sends the peer level _lifecycle proposal to the peers of each org with the user of the org
+func (cc *CCAction) sendLifecycleProposal(fcn string, args []byte, orgIDs, peerURLs []string, info func(payload []byte) (string, error)) ([]PeerResult, error) {
+	peersByOrg, err := cc.action.FilterPeers(orgIDs, peerURLs)
+	if err != nil {
+		return nil, err
+	}
+	request := fab.ChaincodeInvokeRequest{ChaincodeID: lifecycleName, Fcn: fcn, Args: [][]byte{args}}
+	var results []PeerResult
+	var errs []error
+	for orgID, peers := range peersByOrg {
+		logger.L().Infof("Sending %s to org [%s] peers ...", fcn, orgID)
+		user, err := cc.action.UserByOrg(orgID, cc.username)
+		if err != nil {
+			errs = append(errs, err)
+			results = append(results, failedResults(orgID, peers, err)...)
+			continue
+		}
+		responses, err := cc.action.SendProposal(fab.SystemChannel, user, request, peers)
+		if err != nil {
+			errs = append(errs, errors.Errorf("%s returned error: %v", fcn, err))
+		}
+		for _, peer := range peers {
+			result := PeerResult{OrgID: orgID, Peer: peer.URL(), Status: "failed"}
+			if err != nil {
+				result.Info = err.Error()
+			}
+			for _, resp := range responses {
+				if resp.Endorser != peer.URL() {
+					continue
+				}
+				if resp.Status != http.StatusOK {
+					result.Info = resp.ProposalResponse.GetResponse().GetMessage()
+					errs = append(errs, errors.Errorf("%s returned error from peer %s: %s", fcn, resp.Endorser, result.Info))
+				} else if result.Info, err = info(resp.ProposalResponse.GetResponse().GetPayload()); err != nil {
+					errs = append(errs, err)
+				} else {
+					result.Status = "ok"
+				}
+			}
+			results = append(results, result)
+		}
+	}
+
+	if len(errs) > 0 {
+		logger.L().Warnf("Errors returned from %s: %v\n", fcn, errs)
+		return results, errs[0]
+	}
+	return results, nil
+}
+
+// ApproveForMyOrg approves the chaincode definition for each of the given orgs, each approval
+// is a channel transaction of the org user endorsed by the peers of its own org
+func (cc *CCAction) ApproveForMyOrg(def *ChaincodeDefinition, orgIDs, peerURLs []string) ([]PeerResult, error) {
+	args, err := def.ApproveArgs()
+	if err != nil {
+		return nil, err
+	}
+	peersByOrg, err := cc.action.FilterPeers(orgIDs, peerURLs)
+	if err != nil {
+		return nil, err
+	}
+	request := channel.Request{ChaincodeID: lifecycleName, Fcn: approveFuncName, Args: [][]byte{args}}
+	var results []PeerResult
+	var errs []error
+	for orgID, peers := range peersByOrg {
+		logger.L().Infof("Approving chaincode definition %s sequence %d for org [%s] ...", def.Name, def.Sequence, orgID)
+		resp, err := cc.orgExecute(orgID, request, peers)
+		if err != nil {
+			err = errors.Errorf("approve chaincode definition for org [%s] returned error: %v", orgID, err)
+			errs = append(errs, err)
+			results = append(results, failedResults(orgID, peers, err)...)
+			continue
+		}
+		for _, peer := range peers {
+			results = append(results, PeerResult{OrgID: orgID, Peer: peer.URL(), Status: "approved", Info: string(resp.TransactionID)})
+		}
+	}
+	if len(errs) > 0 {
+		return results, errs[0]
+	}
+	return results, nil
+}
+
+func (cc *CCAction) orgExecute(orgID string, request channel.Request, peers []fab.Peer) (channel.Response, error) {
+	user, err := cc.action.UserByOrg(orgID, cc.username)
+	if err != nil {
+		return channel.Response{}, err
+	}
+	channelClient, err := cc.action.ChannelClient(cc.channelId, user)
+	if err != nil {
+		return channel.Response{}, err
+	}
+	return channelClient.Execute(request, channel.WithTargets(peers...))
+}
+
+// CheckCommitReadiness returns the approval of each org for the chaincode definition
+func (cc *CCAction) CheckCommitReadiness(def *ChaincodeDefinition, orgIDs, peerURLs []string) (map[string]bool, error) {
+	args, err := def.CheckCommitReadinessArgs()
+	if err != nil {
+		return nil, err
+	}
+	payload, err := cc.lifecycleQuery(checkCommitReadinessFuncName, args, orgIDs, peerURLs)
+	if err != nil {
+		return nil, err
+	}
+	result := &lb.CheckCommitReadinessResult{}
+	if err := proto.Unmarshal(payload, result); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal check commit readiness result")
+	}
+	return result.Approvals, nil
+}
+
+// Commit commits the chaincode definition to the channel, the transaction is endorsed
+// by the peers of the given orgs which must satisfy the LifecycleEndorsement policy
+func (cc *CCAction) Commit(def *ChaincodeDefinition, orgIDs, peerURLs []string) ([]PeerResult, error) {
+	args, err := def.CommitArgs()
+	if err != nil {
+		return nil, err
+	}
+	peersByOrg, err := cc.action.FilterPeers(orgIDs, peerURLs)
+	if err != nil {
+		return nil, err
+	}
+	var peers []fab.Peer
+	for _, orgPeers := range peersByOrg {
+		peers = append(peers, orgPeers...)
+	}
+	logger.L().Infof("Committing chaincode definition %s sequence %d ...", def.Name, def.Sequence)
+	request := channel.Request{ChaincodeID: lifecycleName, Fcn: commitFuncName, Args: [][]byte{args}}
+	resp, err := cc.channelClient.Execute(request, channel.WithTargets(peers...))
+	var results []PeerResult
+	if err != nil {
+		err = errors.Errorf("commit chaincode definition returned error: %v", err)
+		for orgID, orgPeers := range peersByOrg {
+			results = append(results, failedResults(orgID, orgPeers, err)...)
+		}
+		return results, err
+	}
+	for orgID, orgPeers := range peersByOrg {
+		for _, peer := range orgPeers {
+			results = append(results, PeerResult{OrgID: orgID, Peer: peer.URL(), Status: "committed", Info: string(resp.TransactionID)})
+		}
+	}
+	return results, nil
+}
+
+// QueryApproved returns the chaincode definition approved by the org of the target peer,
+// sequence 0 queries the latest approved definition
+func (cc *CCAction) QueryApproved(name string, sequence int64, orgIDs, peerURLs []string) (*QueryApprovedChaincodeDefinitionResult, error) {
+	args, err := proto.Marshal(&QueryApprovedChaincodeDefinitionArgs{Name: name, Sequence: sequence})
+	if err != nil {
+		return nil, errors.WithMessage(err, "marshal of query approved chaincode definition args failed")
+	}
+	payload, err := cc.lifecycleQuery(queryApprovedFuncName, args, orgIDs, peerURLs)
+	if err != nil {
+		return nil, err
+	}
+	result := &QueryApprovedChaincodeDefinitionResult{}
+	if err := proto.Unmarshal(payload, result); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal query approved chaincode definition result")
+	}
+	return result, nil
+}
+
+// QueryCommitted returns the committed chaincode definitions on the channel, all of them when the name is empty
+func (cc *CCAction) QueryCommitted(name string, orgIDs, peerURLs []string) ([]*lb.QueryChaincodeDefinitionsResult_ChaincodeDefinition, error) {
+	if name == "" {
+		args, err := proto.Marshal(&lb.QueryChaincodeDefinitionsArgs{})
+		if err != nil {
+			return nil, errors.WithMessage(err, "marshal of query chaincode definitions args failed")
+		}
+		payload, err := cc.lifecycleQuery(queryChaincodeDefinitionsFuncName, args, orgIDs, peerURLs)
+		if err != nil {
+			return nil, err
+		}
+		result := &lb.QueryChaincodeDefinitionsResult{}
+		if err := proto.Unmarshal(payload, result); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal query chaincode definitions result")
+		}
+		return result.ChaincodeDefinitions, nil
+	}
+
+	args, err := proto.Marshal(&lb.QueryChaincodeDefinitionArgs{Name: name})
+	if err != nil {
+		return nil, errors.WithMessage(err, "marshal of query chaincode definition args failed")
+	}
+	payload, err := cc.lifecycleQuery(queryChaincodeDefinitionFuncName, args, orgIDs, peerURLs)
+	if err != nil {
+		return nil, err
+	}
+	result := &lb.QueryChaincodeDefinitionResult{}
+	if err := proto.Unmarshal(payload, result); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal query chaincode definition result")
+	}
+	return []*lb.QueryChaincodeDefinitionsResult_ChaincodeDefinition{{
+		Name:                name,
+		Sequence:            result.Sequence,
+		Version:             result.Version,
+		EndorsementPlugin:   result.EndorsementPlugin,
+		ValidationPlugin:    result.ValidationPlugin,
+		ValidationParameter: result.ValidationParameter,
+		Collections:         result.Collections,
+		InitRequired:        result.InitRequired,
+	}}, nil
+}
+
+// lifecycleQuery queries the _lifecycle on the channel on a peer of the org picked by queryOrg
+func (cc *CCAction) lifecycleQuery(fcn string, args []byte, orgIDs, peerURLs []string) ([]byte, error) {
+	peersByOrg, err := cc.action.FilterPeers(orgIDs, peerURLs)
+	if err != nil {
+		return nil, err
+	}
+	orgID, peers := queryOrg(peersByOrg, orgIDs, cc.orgID)
+	if len(peers) == 0 {
+		return nil, errors.Errorf("no peer to query %s", fcn)
+	}
+	fmt.Printf("...querying %s on peer %s of org [%s]\n", fcn, peers[0].URL(), orgID)
+	resp, err := cc.channelClient.Query(channel.Request{ChaincodeID: lifecycleName, Fcn: fcn, Args: [][]byte{args}}, channel.WithTargets(peers[0]))
+	if err != nil {
+		return nil, errors.Errorf("%s returned error: %v", fcn, err)
+	}
+	return resp.Payload, nil
+}
+
+// queryOrg returns the org queried and its peers: the first of the given orgs which has peers, otherwise
+// the default org, otherwise the first org by name, so that the same org answers from run to run
+func queryOrg(peersByOrg map[string][]fab.Peer, orgIDs []string, defaultOrgID string) (string, []fab.Peer) {
+	var sortedOrgIDs []string
+	for orgID := range peersByOrg {
+		sortedOrgIDs = append(sortedOrgIDs, orgID)
+	}
+	sort.Strings(sortedOrgIDs)
+	for _, candidate := range append(append(append([]string{}, orgIDs...), defaultOrgID), sortedOrgIDs...) {
+		for _, orgID := range sortedOrgIDs {
+			if strings.EqualFold(orgID, candidate) && len(peersByOrg[orgID]) > 0 {
+				return orgID, peersByOrg[orgID]
+			}
+		}
+	}
+	return "", nil
+}
+
+// QueryApprovedChaincodeDefinitionArgs is the argument of QueryApprovedChaincodeDefinition,
+// which is added by fabric v2.0.0 and is missing from the fabric-protos-go in use
+type QueryApprovedChaincodeDefinitionArgs struct {
+	Name     string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Sequence int64  `protobuf:"varint,2,opt,name=sequence,proto3" json:"sequence,omitempty"`
+}
+
+func (m *QueryApprovedChaincodeDefinitionArgs) Reset()         { *m = QueryApprovedChaincodeDefinitionArgs{} }
+func (m *QueryApprovedChaincodeDefinitionArgs) String() string { return proto.CompactTextString(m) }
+func (*QueryApprovedChaincodeDefinitionArgs) ProtoMessage()    {}
+
+// QueryApprovedChaincodeDefinitionResult is the result of QueryApprovedChaincodeDefinition
+type QueryApprovedChaincodeDefinitionResult struct {
+	Sequence            int64                           `protobuf:"varint,1,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	Version             string                          `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	EndorsementPlugin   string                          `protobuf:"bytes,3,opt,name=endorsement_plugin,json=endorsementPlugin,proto3" json:"endorsement_plugin,omitempty"`
+	ValidationPlugin    string                          `protobuf:"bytes,4,opt,name=validation_plugin,json=validationPlugin,proto3" json:"validation_plugin,omitempty"`
+	ValidationParameter []byte                          `protobuf:"bytes,5,opt,name=validation_parameter,json=validationParameter,proto3" json:"validation_parameter,omitempty"`
+	Collections         *common.CollectionConfigPackage `protobuf:"bytes,6,opt,name=collections,proto3" json:"collections,omitempty"`
+	InitRequired        bool                            `protobuf:"varint,7,opt,name=init_required,json=initRequired,proto3" json:"init_required,omitempty"`
+	Source              *lb.ChaincodeSource             `protobuf:"bytes,8,opt,name=source,proto3" json:"source,omitempty"`
+}
+
+func (m *QueryApprovedChaincodeDefinitionResult) Reset()         { *m = QueryApprovedChaincodeDefinitionResult{} }
+func (m *QueryApprovedChaincodeDefinitionResult) String() string { return proto.CompactTextString(m) }
+func (*QueryApprovedChaincodeDefinitionResult) ProtoMessage()    {}
+
+// PackageID returns the package ID of the approved chaincode, empty when it is unavailable
+func (m *QueryApprovedChaincodeDefinitionResult) PackageID() string {
+	if m.Source == nil {
+		return ""
+	}
+	return m.Source.GetLocalPackage().GetPackageId()
+}
@@ -0,0 +1,257 @@
+package chaincode
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/zhcppy/fabricli/api"
+	"github.com/zhcppy/fabricli/cmd"
+	"github.com/zhcppy/fabricli/printer"
+)
+
+func newLifecycleCmd() *cobra.Command {
+	lifecycleCmd := &cobra.Command{
+		Use:   "lifecycle",
+		Short: "Fabric 2.x chaincode lifecycle commands",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.HelpFunc()(cmd, args)
+		},
+	}
+	lifecycleCmd.AddCommand(newLifecyclePackageCmd())
+	lifecycleCmd.AddCommand(newLifecyclePackageIDCmd())
+	lifecycleCmd.AddCommand(newLifecycleInstallCmd())
+	lifecycleCmd.AddCommand(newLifecycleQueryInstalledCmd())
+	lifecycleCmd.AddCommand(newLifecycleApproveCmd())
+	lifecycleCmd.AddCommand(newLifecycleCheckCommitReadinessCmd())
+	lifecycleCmd.AddCommand(newLifecycleCommitCmd())
+	lifecycleCmd.AddCommand(newLifecycleQueryApprovedCmd())
+	lifecycleCmd.AddCommand(newLifecycleQueryCommittedCmd())
+	return lifecycleCmd
+}
+
+func newLifecyclePackageCmd() *cobra.Command {
+	packageCmd := &cobra.Command{
+		Use:     "package <file>",
+		Short:   "Package chaincode into a lifecycle .tar.gz package.",
+		Example: "chaincode lifecycle package mycc.tar.gz --ccp github.com/example_cc --label mycc_1",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(c *cobra.Command, args []string) error {
+			cfg := api.ConfigFlags(c.Flags())
+			label, _ := c.Flags().GetString(cmd.LabelFlag)
+			pkgBytes, err := LifecyclePackage(cfg.CCodeInfo, label)
+			if err != nil {
+				return err
+			}
+			if err = ioutil.WriteFile(args[0], pkgBytes, 0644); err != nil {
+				return errors.Wrapf(err, "failed writing chaincode package to file [%s]", args[0])
+			}
+			fmt.Printf("...successfuly packaged chaincode into %s, package ID: %s\n", args[0], PackageID(label, pkgBytes))
+			return nil
+		},
+	}
+	cmd.InitLabel(packageCmd.Flags())
+	return packageCmd
+}
+
+func newLifecyclePackageIDCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "calculatepackageid <file>",
+		Short: "Calculate the package ID of a lifecycle chaincode package.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(c *cobra.Command, args []string) error {
+			pkgBytes, metadata, err := ReadLifecyclePackage(args[0])
+			if err != nil {
+				return err
+			}
+			fmt.Println(PackageID(metadata.Label, pkgBytes))
+			return nil
+		},
+	}
+}
+
+func newLifecycleInstallCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "install <file>",
+		Short: "Install a lifecycle chaincode package.",
+		Long:  "Install a lifecycle chaincode package on the peers of the orgs given by --orgid, optionally limited to the peers given by --peer",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(c *cobra.Command, args []string) error {
+			cfg := api.ConfigFlags(c.Flags())
+			action, err := NewCCAction(cfg)
+			if err != nil {
+				return err
+			}
+			defer action.Close()
+			results, err := action.LifecycleInstall(args[0], cfg.OrgIDs(), cfg.PeerURLs())
+			printPeerResults(results)
+			return err
+		},
+	}
+}
+
+func newLifecycleQueryInstalledCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "queryinstalled",
+		Short: "Query the lifecycle chaincode packages installed on the peers.",
+		RunE: func(c *cobra.Command, args []string) error {
+			cfg := api.ConfigFlags(c.Flags())
+			action, err := NewCCAction(cfg)
+			if err != nil {
+				return err
+			}
+			defer action.Close()
+			results, err := action.LifecycleQueryInstalled(cfg.OrgIDs(), cfg.PeerURLs())
+			printPeerResults(results)
+			return err
+		},
+	}
+}
+
+func initDefinitionFlags(c *cobra.Command) {
+	cmd.InitSequence(c.Flags())
+	cmd.InitInitRequired(c.Flags())
+	cmd.InitChannelConfigPolicy(c.Flags())
+}
+
+func chaincodeDefinition(c *cobra.Command, cfg *api.Config) *ChaincodeDefinition {
+	sequence, _ := c.Flags().GetInt64(cmd.SequenceFlag)
+	initRequired, _ := c.Flags().GetBool(cmd.InitRequiredFlag)
+	channelConfigPolicy, _ := c.Flags().GetString(cmd.ChannelConfigPolicyFlag)
+	packageID, _ := c.Flags().GetString(cmd.PackageIDFlag)
+	return NewChaincodeDefinition(cfg.CCodeInfo, sequence, packageID, channelConfigPolicy, initRequired)
+}
+
+func newLifecycleApproveCmd() *cobra.Command {
+	approveCmd := &cobra.Command{
+		Use:     "approveformyorg",
+		Short:   "Approve the chaincode definition for the orgs.",
+		Long:    "Approve the chaincode definition for each org given by --orgid, endorsed by the peers of the org",
+		Example: "chaincode lifecycle approveformyorg --ccid mycc --v 1.0 --sequence 1 --package-id mycc_1:<hash> --orgid Org1",
+		RunE: func(c *cobra.Command, args []string) error {
+			cfg := api.ConfigFlags(c.Flags())
+			action, err := NewCCAction(cfg)
+			if err != nil {
+				return err
+			}
+			defer action.Close()
+			results, err := action.ApproveForMyOrg(chaincodeDefinition(c, cfg), cfg.OrgIDs(), cfg.PeerURLs())
+			printPeerResults(results)
+			return err
+		},
+	}
+	initDefinitionFlags(approveCmd)
+	cmd.InitPackageID(approveCmd.Flags())
+	return approveCmd
+}
+
+func newLifecycleCheckCommitReadinessCmd() *cobra.Command {
+	checkCmd := &cobra.Command{
+		Use:   "checkcommitreadiness",
+		Short: "Check which orgs have approved the chaincode definition.",
+		RunE: func(c *cobra.Command, args []string) error {
+			cfg := api.ConfigFlags(c.Flags())
+			action, err := NewCCAction(cfg)
+			if err != nil {
+				return err
+			}
+			defer action.Close()
+			approvals, err := action.CheckCommitReadiness(chaincodeDefinition(c, cfg), cfg.OrgIDs(), cfg.PeerURLs())
+			if err != nil {
+				return err
+			}
+			var mspIDs []string
+			for mspID := range approvals {
+				mspIDs = append(mspIDs, mspID)
+			}
+			sort.Strings(mspIDs)
+			var rows [][]string
+			for _, mspID := range mspIDs {
+				rows = append(rows, []string{mspID, strconv.FormatBool(approvals[mspID])})
+			}
+			printer.Table([]string{"MSPID", "APPROVED"}, rows)
+			return nil
+		},
+	}
+	initDefinitionFlags(checkCmd)
+	return checkCmd
+}
+
+func newLifecycleCommitCmd() *cobra.Command {
+	commitCmd := &cobra.Command{
+		Use:   "commit",
+		Short: "Commit the chaincode definition to the channel.",
+		Long:  "Commit the chaincode definition to the channel, endorsed by the peers of the orgs given by --orgid, optionally limited to the peers given by --peer",
+		RunE: func(c *cobra.Command, args []string) error {
+			cfg := api.ConfigFlags(c.Flags())
+			action, err := NewCCAction(cfg)
+			if err != nil {
+				return err
+			}
+			defer action.Close()
+			results, err := action.Commit(chaincodeDefinition(c, cfg), cfg.OrgIDs(), cfg.PeerURLs())
+			printPeerResults(results)
+			return err
+		},
+	}
+	initDefinitionFlags(commitCmd)
+	return commitCmd
+}
+
+func newLifecycleQueryApprovedCmd() *cobra.Command {
+	queryCmd := &cobra.Command{
+		Use:   "queryapproved",
+		Short: "Query the chaincode definition approved by the org.",
+		Long:  "Query the chaincode definition approved by the org of the first peer, --sequence 0 queries the latest approved definition",
+		RunE: func(c *cobra.Command, args []string) error {
+			cfg := api.ConfigFlags(c.Flags())
+			action, err := NewCCAction(cfg)
+			if err != nil {
+				return err
+			}
+			defer action.Close()
+			sequence, _ := c.Flags().GetInt64(cmd.SequenceFlag)
+			result, err := action.QueryApproved(cfg.CCodeInfo.ChaincodeID, sequence, cfg.OrgIDs(), cfg.PeerURLs())
+			if err != nil {
+				return err
+			}
+			printer.Table([]string{"NAME", "SEQUENCE", "VERSION", "INIT REQUIRED", "PACKAGE ID"}, [][]string{{
+				cfg.CCodeInfo.ChaincodeID, strconv.FormatInt(result.Sequence, 10), result.Version,
+				strconv.FormatBool(result.InitRequired), result.PackageID(),
+			}})
+			return nil
+		},
+	}
+	cmd.InitSequence(queryCmd.Flags(), "0")
+	return queryCmd
+}
+
+func newLifecycleQueryCommittedCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "querycommitted",
+		Short: "Query the chaincode definitions committed to the channel.",
+		Long:  "Query the chaincode definition given by --ccid committed to the channel, all of them without --ccid",
+		RunE: func(c *cobra.Command, args []string) error {
+			cfg := api.ConfigFlags(c.Flags())
+			action, err := NewCCAction(cfg)
+			if err != nil {
+				return err
+			}
+			defer action.Close()
+			definitions, err := action.QueryCommitted(cfg.CCodeInfo.ChaincodeID, cfg.OrgIDs(), cfg.PeerURLs())
+			if err != nil {
+				return err
+			}
+			var rows [][]string
+			for _, def := range definitions {
+				rows = append(rows, []string{def.Name, strconv.FormatInt(def.Sequence, 10), def.Version,
+					strconv.FormatBool(def.InitRequired), def.EndorsementPlugin, def.ValidationPlugin})
+			}
+			printer.Table([]string{"NAME", "SEQUENCE", "VERSION", "INIT REQUIRED", "ENDORSEMENT PLUGIN", "VALIDATION PLUGIN"}, rows)
+			return nil
+		},
+	}
+}
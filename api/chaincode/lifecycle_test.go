@@ -0,0 +1,122 @@
+package chaincode
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gogo/protobuf/proto"
+	lb "github.com/hyperledger/fabric-protos-go/peer/lifecycle"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+	"github.com/zhcppy/fabricli/api"
+)
+
+func TestLifecyclePackage(t *testing.T) {
+	root, err := ioutil.TempDir("", "lifecycle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	if err := ioutil.WriteFile(filepath.Join(root, "index.js"), []byte("module.exports = {}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	info := api.CCodeInfo{ChaincodePath: root, ChaincodeLang: "node"}
+	if _, err := LifecyclePackage(info, "-invalid"); err == nil {
+		t.Fatal("expecting invalid label error")
+	}
+	pkgBytes, err := LifecyclePackage(info, "mycc_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkgFile := filepath.Join(root, "mycc.tar.gz")
+	if err := ioutil.WriteFile(pkgFile, pkgBytes, 0644); err != nil {
+		t.Fatal(err)
+	}
+	readBytes, metadata, err := ReadLifecyclePackage(pkgFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metadata.Label != "mycc_1" || metadata.Type != "node" || metadata.Path != root {
+		t.Fatalf("unexpected metadata %+v", metadata)
+	}
+	packageID := PackageID(metadata.Label, readBytes)
+	if !strings.HasPrefix(packageID, "mycc_1:") || len(packageID) != len("mycc_1:")+64 {
+		t.Fatalf("unexpected package ID %s", packageID)
+	}
+}
+
+func TestChaincodeDefinition_ApproveArgs(t *testing.T) {
+	def := &ChaincodeDefinition{Name: "mycc", Version: "1.0", Sequence: 2, PackageID: "mycc_1:abc",
+		SignaturePolicy: "AND('Org1MSP.member')", InitRequired: true}
+	argBytes, err := def.ApproveArgs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	args := &lb.ApproveChaincodeDefinitionForMyOrgArgs{}
+	if err := proto.Unmarshal(argBytes, args); err != nil {
+		t.Fatal(err)
+	}
+	if args.Name != "mycc" || args.Sequence != 2 || !args.InitRequired || args.ValidationParameter == nil ||
+		args.Source.GetLocalPackage().GetPackageId() != "mycc_1:abc" {
+		t.Fatalf("unexpected approve args %v", args)
+	}
+
+	def.ChannelConfigPolicy = "/Channel/Application/Endorsement"
+	if _, err := def.ApproveArgs(); err == nil {
+		t.Fatal("expecting error with both signature and channel config policy")
+	}
+	def.Sequence = 0
+	if _, err := def.CommitArgs(); err == nil {
+		t.Fatal("expecting invalid sequence error")
+	}
+}
+
+func TestQueryApprovedChaincodeDefinitionResult(t *testing.T) {
+	resultBytes, err := proto.Marshal(&QueryApprovedChaincodeDefinitionResult{Sequence: 3, Version: "1.0",
+		Source: &lb.ChaincodeSource{Type: &lb.ChaincodeSource_LocalPackage{LocalPackage: &lb.ChaincodeSource_Local{PackageId: "mycc_1:abc"}}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := &QueryApprovedChaincodeDefinitionResult{}
+	if err := proto.Unmarshal(resultBytes, result); err != nil {
+		t.Fatal(err)
+	}
+	if result.Sequence != 3 || result.Version != "1.0" || result.PackageID() != "mycc_1:abc" {
+		t.Fatalf("unexpected result %v", result)
+	}
+}
+
+func TestQueryOrg(t *testing.T) {
+	peersByOrg := map[string][]fab.Peer{
+		"org1": {mocks.NewMockPeer("peer0.org1", "peer0.org1.example.com:7051")},
+		"org2": {mocks.NewMockPeer("peer0.org2", "peer0.org2.example.com:7051")},
+		"org3": {mocks.NewMockPeer("peer0.org3", "peer0.org3.example.com:7051")},
+		"org4": nil,
+	}
+	tests := []struct {
+		orgIDs       []string
+		defaultOrgID string
+		expected     string
+	}{
+		{[]string{"Org2", "org1"}, "org1", "org2"},
+		{[]string{"org4", "org3"}, "org1", "org3"},
+		{nil, "org3", "org3"},
+		{nil, "", "org1"},
+		{nil, "org4", "org1"},
+	}
+	for _, test := range tests {
+		// the org must not depend on the order of the map
+		for i := 0; i < 10; i++ {
+			if orgID, peers := queryOrg(peersByOrg, test.orgIDs, test.defaultOrgID); orgID != test.expected || len(peers) != 1 {
+				t.Fatalf("%v %s: expecting org %s, got %s", test.orgIDs, test.defaultOrgID, test.expected, orgID)
+			}
+		}
+	}
+	if orgID, peers := queryOrg(map[string][]fab.Peer{"org4": nil}, nil, "org4"); orgID != "" || peers != nil {
+		t.Fatalf("expecting no org without peers, got %s", orgID)
+	}
+}
@@ -0,0 +1,253 @@
+package chaincode
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/hyperledger/fabric-protos-go/common"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/resmgmt"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
+	"github.com/pkg/errors"
+	"github.com/zhcppy/fabricli/api"
+	"github.com/zhcppy/fabricli/api/chaincode/cauthdsl"
+	"github.com/zhcppy/fabricli/api/chaincode/ccpackager"
+	"github.com/zhcppy/fabricli/logger"
+)
+
+const (
+	lscc        = "lscc"
+	lsccInstall = "install"
+	lsccDeploy  = "deploy"
+	lsccUpgrade = "upgrade"
+	escc        = "escc"
+	vscc        = "vscc"
+)
+
+// NewDeploymentSpec packages the chaincode source into a ChaincodeDeploymentSpec
+func NewDeploymentSpec(info api.CCodeInfo) (*pb.ChaincodeDeploymentSpec, error) {
+	if info.ChaincodeID == "" || info.ChaincodeVersion == "" || info.ChaincodePath == "" {
+		return nil, errors.New("chaincode name, version and path are required")
+	}
+	ccPkg, err := ccpackager.NewCCPackage(info.ChaincodeLang, info.ChaincodePath, info.GoPath)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.ChaincodeDeploymentSpec{
+		ChaincodeSpec: &pb.ChaincodeSpec{
+			Type:        ccPkg.Type,
+			ChaincodeId: &pb.ChaincodeID{Name: info.ChaincodeID, Path: info.ChaincodePath, Version: info.ChaincodeVersion},
+		},
+		CodePackage: ccPkg.Code,
+	}, nil
+}
+
+// Package builds the chaincode package the same way `peer chaincode package` does. Without an owner
+// the package is the bare ChaincodeDeploymentSpec, otherwise it is a CHAINCODE_PACKAGE envelope
+// holding a SignedChaincodeDeploymentSpec endorsed by the owner.
+// The instantiation policy defaults to AND('<owner MSPID>.admin')
+func Package(info api.CCodeInfo, owner msp.SigningIdentity, instantiatePolicy string) ([]byte, error) {
+	cds, err := NewDeploymentSpec(info)
+	if err != nil {
+		return nil, err
+	}
+	cdsBytes, err := proto.Marshal(cds)
+	if err != nil {
+		return nil, errors.WithMessage(err, "marshal of chaincode deployment spec failed")
+	}
+	if owner == nil {
+		return cdsBytes, nil
+	}
+
+	if instantiatePolicy == "" {
+		instantiatePolicy = "AND('" + owner.Identifier().MSPID + ".admin')"
+	}
+	policy, err := cauthdsl.FromString(instantiatePolicy)
+	if err != nil {
+		return nil, errors.Errorf("invalid instantiation policy [%s]: %s", instantiatePolicy, err)
+	}
+	policyBytes, err := proto.Marshal(policy)
+	if err != nil {
+		return nil, errors.WithMessage(err, "marshal of instantiation policy failed")
+	}
+
+	endorser, err := owner.Serialize()
+	if err != nil {
+		return nil, errors.WithMessage(err, "serialize owner identity failed")
+	}
+	// the owner signs the concatenation of the cds, the instantiation policy and its serialized identity
+	msg := append(append(append([]byte{}, cdsBytes...), policyBytes...), endorser...)
+	signature, err := owner.Sign(msg)
+	if err != nil {
+		return nil, errors.WithMessage(err, "sign chaincode package failed")
+	}
+
+	signedCDS, err := proto.Marshal(&pb.SignedChaincodeDeploymentSpec{
+		ChaincodeDeploymentSpec: cdsBytes,
+		InstantiationPolicy:     policyBytes,
+		OwnerEndorsements:       []*pb.Endorsement{{Endorser: endorser, Signature: signature}},
+	})
+	if err != nil {
+		return nil, errors.WithMessage(err, "marshal of signed chaincode deployment spec failed")
+	}
+	channelHeader, err := proto.Marshal(&common.ChannelHeader{
+		Type:      int32(common.HeaderType_CHAINCODE_PACKAGE),
+		Timestamp: &timestamp.Timestamp{Seconds: time.Now().Unix()},
+	})
+	if err != nil {
+		return nil, errors.WithMessage(err, "marshal of channel header failed")
+	}
+	payload, err := proto.Marshal(&common.Payload{Header: &common.Header{ChannelHeader: channelHeader}, Data: signedCDS})
+	if err != nil {
+		return nil, errors.WithMessage(err, "marshal of payload failed")
+	}
+	return proto.Marshal(&common.Envelope{Payload: payload})
+}
+
+// ReadPackage reads a chaincode package file written by Package or `peer chaincode package`
+// and returns its raw bytes together with the ChaincodeDeploymentSpec it holds
+func ReadPackage(file string) ([]byte, *pb.ChaincodeDeploymentSpec, error) {
+	pkgBytes, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "could not read chaincode package [%s]", file)
+	}
+	cdsBytes := pkgBytes
+	if signedCDS := unwrapPackageEnvelope(pkgBytes); signedCDS != nil {
+		cdsBytes = signedCDS.ChaincodeDeploymentSpec
+	}
+	cds := &pb.ChaincodeDeploymentSpec{}
+	if err := proto.Unmarshal(cdsBytes, cds); err != nil || cds.ChaincodeSpec == nil || cds.ChaincodeSpec.ChaincodeId == nil {
+		return nil, nil, errors.Errorf("[%s] is not a chaincode package", file)
+	}
+	return pkgBytes, cds, nil
+}
+
+func unwrapPackageEnvelope(pkgBytes []byte) *pb.SignedChaincodeDeploymentSpec {
+	envelope := &common.Envelope{}
+	if err := proto.Unmarshal(pkgBytes, envelope); err != nil {
+		return nil
+	}
+	payload := &common.Payload{}
+	if err := proto.Unmarshal(envelope.Payload, payload); err != nil || payload.Header == nil {
+		return nil
+	}
+	channelHeader := &common.ChannelHeader{}
+	if err := proto.Unmarshal(payload.Header.ChannelHeader, channelHeader); err != nil {
+		return nil
+	}
+	if channelHeader.Type != int32(common.HeaderType_CHAINCODE_PACKAGE) {
+		return nil
+	}
+	signedCDS := &pb.SignedChaincodeDeploymentSpec{}
+	if err := proto.Unmarshal(payload.Data, signedCDS); err != nil {
+		return nil
+	}
+	return signedCDS
+}
+
+// InstallPackage installs the bytes of the chaincode package file as-is on the peers of the given orgs,
+// so that every org installs exactly the same package
+func (cc *CCAction) InstallPackage(file string, orgIDs, peerURLs []string) ([]PeerResult, error) {
+	pkgBytes, cds, err := ReadPackage(file)
+	if err != nil {
+		return nil, err
+	}
+	peersByOrg, err := cc.action.FilterPeers(orgIDs, peerURLs)
+	if err != nil {
+		return nil, err
+	}
+
+	ccID := cds.ChaincodeSpec.ChaincodeId
+	ccIDVersion := ccID.Name + "." + ccID.Version
+	request := fab.ChaincodeInvokeRequest{ChaincodeID: lscc, Fcn: lsccInstall, Args: [][]byte{pkgBytes}}
+	var results []PeerResult
+	var errs []error
+	for orgID, peers := range peersByOrg {
+		logger.L().Infof("Installing chaincode package %s on org [%s] peers ...", ccIDVersion, orgID)
+		user, err := cc.action.UserByOrg(orgID, cc.username)
+		if err != nil {
+			errs = append(errs, err)
+			results = append(results, failedResults(orgID, peers, err)...)
+			continue
+		}
+		responses, err := cc.action.SendProposal(fab.SystemChannel, user, request, peers)
+		if err != nil {
+			errs = append(errs, errors.Errorf("install chaincode package returned error: %v", err))
+		}
+		for _, peer := range peers {
+			result := PeerResult{OrgID: orgID, Peer: peer.URL(), Status: "failed"}
+			if err != nil {
+				result.Info = err.Error()
+			}
+			for _, resp := range responses {
+				if resp.Endorser != peer.URL() {
+					continue
+				}
+				if message := resp.ProposalResponse.GetResponse().GetMessage(); alreadyInstalled(message, ccIDVersion) {
+					result.Status, result.Info = "installed", "already installed"
+				} else if resp.Status != http.StatusOK {
+					result.Info = message
+					errs = append(errs, errors.Errorf("install chaincode package returned error from peer %s: %s", resp.Endorser, result.Info))
+				} else {
+					result.Status, result.Info = "installed", fmt.Sprintf("successfully installed %s", ccIDVersion)
+				}
+			}
+			results = append(results, result)
+		}
+	}
+
+	if len(errs) > 0 {
+		logger.L().Warnf("Errors returned from install chaincode package: %v\n", errs)
+		return results, errs[0]
+	}
+	return results, nil
+}
+
+// alreadyInstalled reports whether the lscc install response message is the one of a peer which already
+// has the chaincode, "chaincode <install path>/<name>.<version> exists"
+func alreadyInstalled(message, ccIDVersion string) bool {
+	return strings.HasSuffix(message, ccIDVersion+" exists")
+}
+
+// deployLSCC sends the lscc deploy or upgrade transaction of a chaincode of the given type.
+// The resource management client always declares the chaincode as golang, which the peer
+// then fails to build for node and java chaincode
+func (cc *CCAction) deployLSCC(fcn string, ccType pb.ChaincodeSpec_Type, req resmgmt.InstantiateCCRequest, peers []fab.Peer) (fab.TransactionID, error) {
+	cdsBytes, err := proto.Marshal(&pb.ChaincodeDeploymentSpec{ChaincodeSpec: &pb.ChaincodeSpec{
+		Type:        ccType,
+		ChaincodeId: &pb.ChaincodeID{Name: req.Name, Path: req.Path, Version: req.Version},
+		Input:       &pb.ChaincodeInput{Args: req.Args},
+	}})
+	if err != nil {
+		return "", errors.WithMessage(err, "marshal of chaincode deployment spec failed")
+	}
+	policyBytes, err := proto.Marshal(req.Policy)
+	if err != nil {
+		return "", errors.WithMessage(err, "marshal of chaincode policy failed")
+	}
+	args := [][]byte{[]byte(cc.channelId), cdsBytes, policyBytes, []byte(escc), []byte(vscc)}
+	if req.CollConfig != nil {
+		collConfigBytes, err := proto.Marshal(&common.CollectionConfigPackage{Config: req.CollConfig})
+		if err != nil {
+			return "", errors.WithMessage(err, "marshal of collection policy failed")
+		}
+		args = append(args, collConfigBytes)
+	}
+
+	var opts []channel.RequestOption
+	if len(peers) > 0 {
+		opts = append(opts, channel.WithTargets(peers...))
+	}
+	resp, err := cc.channelClient.Execute(channel.Request{ChaincodeID: lscc, Fcn: fcn, Args: args}, opts...)
+	if err != nil {
+		return "", err
+	}
+	return resp.TransactionID, nil
+}
@@ -0,0 +1,62 @@
+package chaincode
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/zhcppy/fabricli/api"
+)
+
+func TestPackage(t *testing.T) {
+	goPath, err := ioutil.TempDir("", "gopath")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(goPath)
+	ccDir := filepath.Join(goPath, "src", "example_cc")
+	if err = os.MkdirAll(ccDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err = ioutil.WriteFile(filepath.Join(ccDir, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	info := api.CCodeInfo{ChaincodeID: "examplecc", ChaincodeVersion: "v0", ChaincodePath: "example_cc", GoPath: goPath}
+	pkgBytes, err := Package(info, nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkgFile := filepath.Join(goPath, "examplecc.cds")
+	if err = ioutil.WriteFile(pkgFile, pkgBytes, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	readBytes, cds, err := ReadPackage(pkgFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(readBytes) != len(pkgBytes) {
+		t.Fatal("package bytes changed")
+	}
+	if id := cds.ChaincodeSpec.ChaincodeId; id.Name != "examplecc" || id.Version != "v0" || id.Path != "example_cc" {
+		t.Fatal("unexpected chaincode ID", id)
+	}
+	if len(cds.CodePackage) == 0 {
+		t.Fatal("empty code package")
+	}
+}
+
+func TestAlreadyInstalled(t *testing.T) {
+	for message, expected := range map[string]bool{
+		"chaincode /var/hyperledger/production/chaincodes/examplecc.v0 exists": true,
+		"chaincode /var/hyperledger/production/chaincodes/examplecc.v1 exists": false,
+		"access denied for [install]":                                          false,
+		"":                                                                     false,
+	} {
+		if alreadyInstalled(message, "examplecc.v0") != expected {
+			t.Fatalf("%s: expecting already installed %t", message, expected)
+		}
+	}
+}
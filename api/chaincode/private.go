@@ -0,0 +1,79 @@
+package chaincode
+
+import (
+	"sort"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel"
+	"github.com/pkg/errors"
+	"github.com/zhcppy/fabricli/logger"
+)
+
+// PrivateDataQuery reads keys from a private data collection through a chaincode function
+type PrivateDataQuery struct {
+	ChaincodeID string
+	// Func is the chaincode function that reads a key from the collection
+	Func string
+	// Collection is the name of the private data collection
+	Collection string
+	// CollectionArg passes the collection as the first argument of the function, followed by the key,
+	// otherwise the key is the only argument
+	CollectionArg bool
+	// Transient is the transient data sent with each query, e.g. a decryption key
+	Transient map[string][]byte
+}
+
+// PrivateDataResult is the value of a private data key read from a single peer
+type PrivateDataResult struct {
+	OrgID string
+	Peer  string
+	Key   string
+	Value []byte
+	Err   error
+}
+
+func (q *PrivateDataQuery) request(key string) channel.Request {
+	args := [][]byte{[]byte(key)}
+	if q.CollectionArg {
+		args = append([][]byte{[]byte(q.Collection)}, args...)
+	}
+	return channel.Request{ChaincodeID: q.ChaincodeID, Fcn: q.Func, Args: args, TransientMap: q.Transient}
+}
+
+// QueryPrivateData reads the keys from each peer of the given orgs, filtered by the given peer URLs,
+// so that peers that are not members of the collection or have not received the private data yet
+// show up with their error. An error is returned only when no peer matches
+func (cc *CCAction) QueryPrivateData(query PrivateDataQuery, keys []string, orgIDs, peerURLs []string) ([]PrivateDataResult, error) {
+	if query.ChaincodeID == "" || query.Func == "" || query.Collection == "" {
+		return nil, errors.New("chaincode ID, function and collection are required")
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("at least one key is required")
+	}
+	peersByOrg, err := cc.action.FilterPeers(orgIDs, peerURLs)
+	if err != nil {
+		return nil, err
+	}
+	orgs := make([]string, 0, len(peersByOrg))
+	for orgID := range peersByOrg {
+		orgs = append(orgs, orgID)
+	}
+	sort.Strings(orgs)
+
+	var results []PrivateDataResult
+	for _, orgID := range orgs {
+		for _, peer := range peersByOrg[orgID] {
+			for _, key := range keys {
+				logger.L().Debugf("Reading key [%s] of collection [%s] from peer %s ...", key, query.Collection, peer.URL())
+				result := PrivateDataResult{OrgID: orgID, Peer: peer.URL(), Key: key}
+				response, err := cc.channelClient.Query(query.request(key), channel.WithTargets(peer))
+				if err != nil {
+					result.Err = err
+				} else {
+					result.Value = response.Payload
+				}
+				results = append(results, result)
+			}
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,204 @@
+package chaincode
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/pkg/errors"
+	"github.com/zhcppy/fabricli/api/chaincode/task"
+	"github.com/zhcppy/fabricli/api/query"
+	"github.com/zhcppy/fabricli/executor/worker"
+	"github.com/zhcppy/fabricli/stats"
+)
+
+// systemChaincodes are not recorded, their calls such as deployments cannot be replayed as a workload
+var systemChaincodes = map[string]bool{"lscc": true, "cscc": true, "qscc": true, "_lifecycle": true}
+
+// ReplayCall is a recorded chaincode call, a replay file holds one JSON call per line
+type ReplayCall struct {
+	Block     uint64    `json:"block"`
+	TxID      string    `json:"txId"`
+	Timestamp time.Time `json:"timestamp"`
+	MspID     string    `json:"mspId,omitempty"`
+	// Code is the validation code the transaction was committed with
+	Code      string `json:"code,omitempty"`
+	Chaincode string `json:"chaincode"`
+	Func      string `json:"func"`
+	// Args are literal invoke args, the functions are escaped
+	Args []string `json:"args"`
+}
+
+// NewReplayCall returns the replay call of the chaincode call recorded in the ledger
+func NewReplayCall(call query.ChaincodeCall) ReplayCall {
+	replayCall := ReplayCall{
+		Block:     call.BlockNumber,
+		TxID:      call.TxID,
+		Timestamp: call.Timestamp,
+		MspID:     call.MspID,
+		Code:      call.ValidationCode,
+		Chaincode: call.ChaincodeID,
+		Args:      []string{},
+	}
+	if len(call.Args) > 0 {
+		replayCall.Func = string(call.Args[0])
+		for _, arg := range call.Args[1:] {
+			replayCall.Args = append(replayCall.Args, task.Literal(arg))
+		}
+	}
+	return replayCall
+}
+
+// Record writes the chaincode calls of the blocks from the block number to the block number to the replay file,
+// only the calls of the given chaincode are recorded unless it is empty. It returns the number of calls recorded
+func Record(q *query.Query, from, to uint64, chaincodeID, file string) (int, error) {
+	f, err := os.Create(file)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to create replay file [%s]", file)
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	encoder := json.NewEncoder(w)
+	var recorded int
+	err = q.ScanChaincodeCalls(from, to, func(call query.ChaincodeCall) error {
+		if systemChaincodes[call.ChaincodeID] || (chaincodeID != "" && call.ChaincodeID != chaincodeID) {
+			return nil
+		}
+		recorded++
+		return encoder.Encode(NewReplayCall(call))
+	})
+	if err != nil {
+		return recorded, err
+	}
+	if err := w.Flush(); err != nil {
+		return recorded, errors.Wrapf(err, "failed to write replay file [%s]", file)
+	}
+	return recorded, nil
+}
+
+// ReadReplayFile reads the calls of the replay file
+func ReadReplayFile(file string) ([]ReplayCall, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open replay file [%s]", file)
+	}
+	defer f.Close()
+	calls, err := readReplayCalls(f)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "invalid replay file [%s]", file)
+	}
+	return calls, nil
+}
+
+func readReplayCalls(r io.Reader) ([]ReplayCall, error) {
+	var calls []ReplayCall
+	decoder := json.NewDecoder(r)
+	for {
+		var call ReplayCall
+		if err := decoder.Decode(&call); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, errors.Wrapf(err, "failed to decode call %d", len(calls)+1)
+		}
+		if call.Chaincode == "" || call.Func == "" {
+			return nil, errors.Errorf("call %d has no chaincode or func", len(calls)+1)
+		}
+		calls = append(calls, call)
+	}
+	if len(calls) == 0 {
+		return nil, errors.New("no calls recorded")
+	}
+	return calls, nil
+}
+
+// replayOffset returns the time offset of the nth call of the replay from its start, the calls
+// are replayed in turn opts.Iterations times, each pass starting after the previous one
+func replayOffset(calls []ReplayCall, n int) time.Duration {
+	first, last := calls[0].Timestamp, calls[len(calls)-1].Timestamp
+	pass, call := n/len(calls), calls[n%len(calls)]
+	return time.Duration(pass)*last.Sub(first) + call.Timestamp.Sub(first)
+}
+
+// Replay invokes the recorded calls in turn opts.Iterations times, or for opts.Duration at the target rate.
+// The calls are replayed on the chaincode they were recorded on unless chaincodeID is given. With the original
+// timing each call is submitted at the same offset from the start as it was committed, the concurrency must
+// be high enough for the workers to keep up
+func (cc *CCAction) Replay(calls []ReplayCall, chaincodeID string, originalTiming bool, opts InvokeOptions) error {
+	if err := opts.check(false); err != nil {
+		return err
+	}
+	if opts.Async {
+		return errors.New("async submission is not supported by replays")
+	}
+	if originalTiming && opts.TPS > 0 {
+		return errors.New("the original timing cannot be kept at a target rate")
+	}
+
+	var targets []fab.Peer
+	for _, peer := range cc.action.GetPeers() {
+		targets = append(targets, peer)
+	}
+	recorder := stats.NewRecorder()
+	ctxt := task.NewContext()
+	ctx, stop := interruptible()
+	defer stop()
+
+	var start time.Time
+	var n int
+	next := func(done func()) worker.Task {
+		if opts.Duration == 0 && n >= opts.Iterations*len(calls) {
+			return nil
+		}
+		if start.IsZero() {
+			start = time.Now()
+		}
+		if originalTiming {
+			timer := time.NewTimer(time.Until(start.Add(replayOffset(calls, n))))
+			defer timer.Stop()
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				return nil
+			}
+		}
+		call := calls[n%len(calls)]
+		n++
+		ccID := call.Chaincode
+		if chaincodeID != "" {
+			ccID = chaincodeID
+		}
+		ccTask := task.NewCCTask(ctxt, strconv.Itoa(n), cc.channelClient, targets, ccID,
+			task.ArgStruct{Func: call.Func, Args: call.Args}, opts.Retry, func() {}, recorder.Add, false)
+		ccTask.Prepare()
+		multiTask := task.NewMultiTask(done)
+		multiTask.Add(ccTask)
+		return multiTask
+	}
+
+	iterations, stepResults, err := runIterations(ctx, "Replay", opts, recorder, nil, next)
+	if err != nil {
+		return err
+	}
+	interrupted := ctx.Err() != nil
+
+	fmt.Printf("\n*** Replayed %d call(s) of %d recorded with concurrency %d\n", iterations, len(calls), opts.Concurrency)
+	summary := recorder.Summary()
+	summary.Print()
+	if len(stepResults) > 0 {
+		fmt.Printf("\n")
+		printStepResults(stepResults)
+	}
+	if err := writeReport(recorder, opts); err != nil {
+		return err
+	}
+	if interrupted {
+		return errors.Errorf("interrupted after %d call(s)", summary.Invocations)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package chaincode
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zhcppy/fabricli/api/chaincode/task"
+	"github.com/zhcppy/fabricli/api/query"
+)
+
+func TestNewReplayCall(t *testing.T) {
+	args := [][]byte{[]byte("move"), []byte("key$rand(3)"), []byte(`a\b,c)`), {0xff, 0x00, 0x24}}
+	call := NewReplayCall(query.ChaincodeCall{BlockNumber: 3, TxID: "tx1", ChaincodeID: "mycc", Args: args})
+	if call.Func != "move" || len(call.Args) != 3 {
+		t.Fatalf("unexpected call %+v", call)
+	}
+
+	// the recorded args are replayed as is
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(call); err != nil {
+		t.Fatal(err)
+	}
+	calls, err := readReplayCalls(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayed, err := task.ArgToBytes(task.NewContext(), calls[0].Args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, arg := range replayed {
+		if !bytes.Equal(arg, args[i+1]) {
+			t.Fatalf("expecting arg [%d] %q, got %q", i, args[i+1], arg)
+		}
+	}
+}
+
+func TestReadReplayCalls(t *testing.T) {
+	start := time.Date(2019, 10, 1, 12, 0, 0, 0, time.UTC)
+	var lines []string
+	for i, offset := range []time.Duration{0, time.Second, 3 * time.Second} {
+		line, _ := json.Marshal(ReplayCall{Block: uint64(i), Timestamp: start.Add(offset), Chaincode: "mycc", Func: "move"})
+		lines = append(lines, string(line))
+	}
+	calls, err := readReplayCalls(strings.NewReader(strings.Join(lines, "\n")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 3 {
+		t.Fatalf("expecting 3 calls, got %d", len(calls))
+	}
+	// the second pass starts after the first one
+	for n, expected := range []time.Duration{0, time.Second, 3 * time.Second, 3 * time.Second, 4 * time.Second} {
+		if offset := replayOffset(calls, n); offset != expected {
+			t.Fatalf("expecting offset %s of call %d, got %s", expected, n, offset)
+		}
+	}
+
+	if _, err := readReplayCalls(strings.NewReader(`{"chaincode":"mycc"}`)); err == nil || !strings.Contains(err.Error(), "no chaincode or func") {
+		t.Fatalf("expecting an invalid call, got %v", err)
+	}
+	if _, err := readReplayCalls(strings.NewReader("")); err == nil {
+		t.Fatal("expecting an error for an empty replay file")
+	}
+}
@@ -0,0 +1,283 @@
+package chaincode
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"strconv"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/pkg/errors"
+	"github.com/zhcppy/fabricli/api/chaincode/task"
+	"github.com/zhcppy/fabricli/executor/worker"
+	"github.com/zhcppy/fabricli/printer"
+	"github.com/zhcppy/fabricli/stats"
+	"gopkg.in/yaml.v2"
+)
+
+// Scenario is a workload of weighted operations, each iteration of a run invokes one of the operations
+// picked at random according to their weights. Example:
+//
+//	name: marbles
+//	operations:
+//	  - name: create
+//	    chaincode: marbles
+//	    func: initMarble
+//	    args: ["marble$seq()", "blue", "35", "tom"]
+//	    weight: 1
+//	  - name: read
+//	    chaincode: marbles
+//	    func: readMarble
+//	    args: ["marble$zipf(1000,1.2)"]
+//	    user: User2
+//	    org: Org2
+//	    query: true
+//	    weight: 4
+//	    think: 100ms
+type Scenario struct {
+	Name       string      `yaml:"name"`
+	Operations []Operation `yaml:"operations"`
+}
+
+// Operation is an invocation of a chaincode function by a user
+type Operation struct {
+	Name      string `yaml:"name"`
+	Chaincode string `yaml:"chaincode"`
+	Func      string `yaml:"func"`
+	// Args and the values of Transient may contain the same functions as the invoke args
+	Args      []string          `yaml:"args"`
+	Transient map[string]string `yaml:"transient"`
+	// User and Org are the identity invoking the operation, they default to the user and org of the config
+	User string `yaml:"user"`
+	Org  string `yaml:"org"`
+	// Query only evaluates the operation on the peers instead of submitting a transaction
+	Query bool `yaml:"query"`
+	// Weight is the relative frequency of the operation, 1 by default
+	Weight int `yaml:"weight"`
+	// Think is the time the worker waits after the operation before taking the next iteration
+	Think time.Duration `yaml:"think"`
+}
+
+// LoadScenario reads the scenario from the YAML file
+func LoadScenario(file string) (*Scenario, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read scenario file [%s]", file)
+	}
+	s, err := ParseScenario(data)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "invalid scenario file [%s]", file)
+	}
+	return s, nil
+}
+
+// ParseScenario parses the YAML scenario and checks its operations
+func ParseScenario(data []byte) (*Scenario, error) {
+	s := &Scenario{}
+	if err := yaml.UnmarshalStrict(data, s); err != nil {
+		return nil, errors.Wrap(err, "failed to parse scenario")
+	}
+	if len(s.Operations) == 0 {
+		return nil, errors.New("no operations given")
+	}
+	names := map[string]bool{}
+	for i := range s.Operations {
+		op := &s.Operations[i]
+		if op.Chaincode == "" || op.Func == "" {
+			return nil, errors.Errorf("operation [%d] requires a chaincode and a func", i)
+		}
+		if op.Name == "" {
+			op.Name = op.Func
+		}
+		if names[op.Name] {
+			return nil, errors.Errorf("duplicate operation name [%s]", op.Name)
+		}
+		names[op.Name] = true
+		if op.Weight == 0 {
+			op.Weight = 1
+		}
+		if op.Weight < 0 || op.Think < 0 {
+			return nil, errors.Errorf("operation [%s] cannot have a negative weight or think time", op.Name)
+		}
+	}
+	return s, nil
+}
+
+// pick returns the index of an operation picked at random according to the weights
+func (s *Scenario) pick(r *rand.Rand) int {
+	var total int
+	for _, op := range s.Operations {
+		total += op.Weight
+	}
+	n := r.Intn(total)
+	for i, op := range s.Operations {
+		if n < op.Weight {
+			return i
+		}
+		n -= op.Weight
+	}
+	return len(s.Operations) - 1
+}
+
+// operationTask is an iteration of a scenario, it invokes an operation and then waits for the think time
+type operationTask struct {
+	*task.ChaincodeTask
+	think time.Duration
+	done  func()
+}
+
+func (t *operationTask) Invoke() {
+	t.InvokeContext(context.Background())
+}
+
+func (t *operationTask) InvokeContext(ctx context.Context) {
+	defer t.done()
+	t.ChaincodeTask.InvokeContext(ctx)
+	if t.think <= 0 {
+		return
+	}
+	timer := time.NewTimer(t.think)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+}
+
+// operationClient returns the channel client of the identity of the operation
+func (cc *CCAction) operationClient(op Operation) (*channel.Client, error) {
+	if op.User == "" && op.Org == "" {
+		return cc.channelClient, nil
+	}
+	username, orgID := op.User, op.Org
+	if username == "" {
+		username = cc.username
+	}
+	if orgID == "" {
+		orgID = cc.orgID
+	}
+	user, err := cc.action.UserByOrg(orgID, username)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "operation [%s]", op.Name)
+	}
+	return cc.action.ChannelClient(cc.channelId, user)
+}
+
+// RunScenario runs opts.Iterations iterations of the scenario, or for opts.Duration at the target rate.
+// The results are reported for the whole run and for each operation
+func (cc *CCAction) RunScenario(s *Scenario, opts InvokeOptions) error {
+	if err := opts.check(false); err != nil {
+		return err
+	}
+	if opts.Async {
+		return errors.New("async submission is not supported by scenarios")
+	}
+
+	clients := make([]*channel.Client, len(s.Operations))
+	for i, op := range s.Operations {
+		client, err := cc.operationClient(op)
+		if err != nil {
+			return err
+		}
+		clients[i] = client
+	}
+
+	var targets []fab.Peer
+	for _, peer := range cc.action.GetPeers() {
+		targets = append(targets, peer)
+	}
+	recorder := stats.NewRecorder()
+	opRecorders := make([]*stats.Recorder, len(s.Operations))
+	for i := range opRecorders {
+		opRecorders[i] = stats.NewRecorder()
+	}
+
+	seed := opts.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	fmt.Printf("*** Seed: %d\n", seed)
+	ctxt := task.NewContextWithSeed(seed)
+	r := rand.New(rand.NewSource(seed))
+
+	ctx, stop := interruptible()
+	defer stop()
+
+	var n int
+	next := func(done func()) worker.Task {
+		if opts.Duration == 0 && n >= opts.Iterations {
+			return nil
+		}
+		n++
+		i := s.pick(r)
+		op := s.Operations[i]
+		opRecorder := opRecorders[i]
+		ccTask := task.NewCCTask(ctxt, op.Name+"-"+strconv.Itoa(n), clients[i], targets, op.Chaincode,
+			task.ArgStruct{Func: op.Func, Args: op.Args, Transient: op.Transient}, opts.Retry, func() {},
+			func(result *stats.Result) {
+				recorder.Add(result)
+				opRecorder.Add(result)
+			}, op.Query)
+		ccTask.Prepare()
+		return &operationTask{ChaincodeTask: ccTask, think: op.Think, done: done}
+	}
+
+	for _, opRecorder := range opRecorders {
+		opRecorder.Start()
+	}
+	iterations, stepResults, err := runIterations(ctx, "Run Scenario", opts, recorder, nil, next)
+	for _, opRecorder := range opRecorders {
+		opRecorder.Stop()
+	}
+	if err != nil {
+		return err
+	}
+	interrupted := ctx.Err() != nil
+
+	fmt.Printf("\n*** Ran %d iteration(s) of scenario [%s] with concurrency %d\n", iterations, s.Name, opts.Concurrency)
+	summary := recorder.Summary()
+	summary.Print()
+	fmt.Printf("\n")
+	printOperations(s, opRecorders)
+	if len(stepResults) > 0 {
+		fmt.Printf("\n")
+		printStepResults(stepResults)
+	}
+
+	if err := writeReport(recorder, opts); err != nil {
+		return err
+	}
+	if interrupted {
+		return errors.Errorf("interrupted after %d iteration(s)", summary.Invocations)
+	}
+	return nil
+}
+
+// printOperations prints the summary of each operation of the scenario
+func printOperations(s *Scenario, recorders []*stats.Recorder) {
+	var rows [][]string
+	for i, op := range s.Operations {
+		summary := recorders[i].Summary()
+		kind := "invoke"
+		if op.Query {
+			kind = "query"
+		}
+		rows = append(rows, []string{
+			op.Name,
+			kind,
+			op.Chaincode + "." + op.Func,
+			strconv.Itoa(op.Weight),
+			strconv.Itoa(summary.Invocations),
+			strconv.Itoa(summary.Failed),
+			strconv.Itoa(summary.MVCCConflicts + summary.PhantomConflicts),
+			fmt.Sprintf("%2.2f/s", summary.Rate),
+			stats.Seconds(summary.Latency.Avg),
+			stats.Seconds(summary.Latency.P50),
+			stats.Seconds(summary.Latency.P99),
+		})
+	}
+	printer.Table([]string{"OPERATION", "TYPE", "FUNCTION", "WEIGHT", "INVOCATIONS", "FAILED", "CONFLICTS", "RATE", "AVG", "P50", "P99"}, rows)
+}
@@ -0,0 +1,71 @@
+package chaincode
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testScenario = `
+name: marbles
+operations:
+  - name: create
+    chaincode: marbles
+    func: initMarble
+    args: ["marble$seq()", "blue", "35", "tom"]
+    transient:
+      secret: "$randstr(8)"
+  - chaincode: marbles
+    func: readMarble
+    args: ["marble$zipf(1000,1.2)"]
+    user: User2
+    org: Org2
+    query: true
+    weight: 3
+    think: 100ms
+`
+
+func TestParseScenario(t *testing.T) {
+	s, err := ParseScenario([]byte(testScenario))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Name != "marbles" || len(s.Operations) != 2 {
+		t.Fatalf("unexpected scenario %+v", s)
+	}
+	create, read := s.Operations[0], s.Operations[1]
+	if create.Weight != 1 || create.Query || create.Transient["secret"] != "$randstr(8)" || len(create.Args) != 4 {
+		t.Fatalf("unexpected operation %+v", create)
+	}
+	if read.Name != "readMarble" || read.Weight != 3 || !read.Query || read.Think != 100*time.Millisecond || read.User != "User2" {
+		t.Fatalf("unexpected operation %+v", read)
+	}
+
+	r := rand.New(rand.NewSource(1))
+	counts := make([]int, 2)
+	for i := 0; i < 4000; i++ {
+		counts[s.pick(r)]++
+	}
+	if counts[1] < 2800 || counts[1] > 3200 {
+		t.Fatalf("expecting the operations picked according to their weights, got %v", counts)
+	}
+}
+
+func TestParseScenario_Error(t *testing.T) {
+	tests := []struct {
+		data     string
+		expected string
+	}{
+		{"name: empty", "no operations given"},
+		{"operations:\n  - func: f", "requires a chaincode and a func"},
+		{"operations:\n  - {chaincode: c, func: f, weight: -1}", "negative weight"},
+		{"operations:\n  - {chaincode: c, func: f}\n  - {chaincode: d, func: f}", "duplicate operation name [f]"},
+		{"operations:\n  - {chaincode: c, func: f, wieght: 2}", "field wieght not found"},
+	}
+	for _, test := range tests {
+		if _, err := ParseScenario([]byte(test.data)); err == nil || !strings.Contains(err.Error(), test.expected) {
+			t.Fatalf("expecting error containing '%s', got %v", test.expected, err)
+		}
+	}
+}
@@ -6,41 +6,51 @@
 package task
 
 import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"math/rand"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
-	"sync/atomic"
 	"time"
+	"unicode/utf8"
 
 	"github.com/pkg/errors"
 	"github.com/zhcppy/fabricli/logger"
 )
 
-const (
-	randFunc = "$rand("
-	padFunc  = "$pad("
-	seqFunc  = "$seq("
-	setFunc  = "$set("
-	fileFunc = "$file("
-	varExp   = "${"
-)
-
-var (
-	sequence uint64
-)
-
 type ArgStruct struct {
 	Func string   `json:"Func"`
 	Args []string `json:"Args"`
+	// Transient is the transient data of the proposal, e.g. the private data written to a collection.
+	// The values may contain the same functions as the args
+	Transient map[string]string `json:"Transient,omitempty"`
+}
+
+// Evaluate evaluates the functions in the args and in the values of the transient map
+func (a ArgStruct) Evaluate(ctxt Context) ([][]byte, map[string][]byte, error) {
+	args, err := ArgToBytes(ctxt, a.Args)
+	if err != nil {
+		return nil, nil, err
+	}
+	transient, err := TransientToBytes(ctxt, a.Transient)
+	if err != nil {
+		return nil, nil, err
+	}
+	return args, transient, nil
 }
 
 // ArgToBytes converts the string array to an array of byte arrays.
-// The args may contain functions $rand(n) or $pad(n,chars).
-// The functions are evaluated before returning.
+// The args may contain functions, which may be nested, and variables.
+// The functions are evaluated before returning, an error is returned if any of them fails.
+// The random values and the sequence come from the context, the same args evaluated in the same
+// order with contexts of the same seed are identical, except for $now and the content of $file and $env.
 //
 // Examples:
 // - "key$rand(3)" -> "key0" or "key1" or "key2"
@@ -49,148 +59,342 @@ type ArgStruct struct {
 // - "key$seq()" -> "key1", "key2", "key2", ...
 // - "val$pad($seq(),X)" -> "valX", "valXX", "valXX", "valXXX", ...
 // - "Key_$set(x,$seq())=Val_${x}" -> Key_1=Val_1, Key_2=Val_2, ...
-func ArgToBytes(ctxt Context, args []string) [][]byte {
-	r := rand.New(rand.NewSource(time.Now().UTC().UnixNano()))
+// - "$uuid()" -> "3b241101-e2bb-4255-8caf-4136c566a962"
+// - "$now(2006-01-02)" -> "2019-09-17", $now() is RFC3339, $now(unix) and $now(unixnano) are epoch times
+// - "$hex(4)" -> "9f86d081", 4 random bytes
+// - "$base64(hello)" -> "aGVsbG8=" and "$sha256(hello)" -> "2cf24dba...", the hex encoded hash
+// - "$randstr(5)" -> "x7Gq2", random alphanumeric characters
+// - "$choice(red,green,blue)" -> one of the values
+// - "$json(name,m$seq(),size,$rand(10))" -> {"name":"m1","size":7}, values that are valid JSON are kept as is
+// - "$env(HOME)" -> the value of the environment variable, which must be set
+// - "$file(path)" -> the content of the file
+// - "$unbase64(aGVsbG8=)" -> "hello", e.g. binary values
+//
+// The key distributions return a key index between 0 and n (exclusive), e.g. "key$zipf(1000,1.1)":
+// - "$uniform(n)" -> every key is equally likely
+// - "$zipf(n,s)" -> key 0 is the most likely, key k has a probability proportional to 1/(k+1)^s, s > 1
+// - "$hotspot(n,pct,hot)" -> pct% of the time one of the first hot keys, one of the other keys otherwise
+//
+// A backslash escapes $ ( ) , and \, e.g. "a\,b" passes "a,b" as a single argument. The other
+// backslashes are kept as is, e.g. in the JSON string "a\"b" or the path C:\dir
+func ArgToBytes(ctxt Context, args []string) ([][]byte, error) {
 	bytes := make([][]byte, len(args))
 
 	fmt.Printf("Args:\n")
 	for i, a := range args {
-		arg := getArg(ctxt, r, a)
+		arg, err := getArg(ctxt, a)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "invalid arg [%d] '%s'", i, a)
+		}
 		bytes[i] = []byte(arg)
 		fmt.Printf("- [%d]=%s\n", i, arg)
 	}
-	return bytes
-}
-
-func getArg(ctxt Context, r *rand.Rand, arg string) string {
-	arg = evaluateSeqExpression(arg)
-	arg = evaluateRandExpression(r, arg)
-	arg = evaluatePadExpression(arg)
-	arg = evaluateFileExpression(arg)
-	arg = evaluateSetExpression(ctxt, arg)
-	arg = evaluateVarExpression(ctxt, arg)
-	return arg
-}
-
-// evaluateSeqExpression replaces occurrences of $seq() with a sequential
-// number starting at 1 and incrementing for each task
-func evaluateSeqExpression(arg string) string {
-	return evaluateExpression(arg, seqFunc, ")",
-		func(expression string) (string, error) {
-			return strconv.FormatUint(atomic.AddUint64(&sequence, 1), 10), nil
-		})
-}
-
-// evaluateRandExpression replaces occurrences of $rand(n) with a random
-// number between 0 and n (exclusive)
-func evaluateRandExpression(r *rand.Rand, arg string) string {
-	return evaluateExpression(arg, randFunc, ")",
-		func(expression string) (string, error) {
-			n, err := strconv.ParseInt(expression, 10, 64)
-			if err != nil {
-				return "", errors.Errorf("invalid number %s in $rand expression\n", expression)
-			}
-			return strconv.FormatInt(r.Int63n(n), 10), nil
-		})
-}
-
-// evaluatePadExpression replaces occurrences of $pad(n,chars) with n of the given pad characters
-func evaluatePadExpression(arg string) string {
-	return evaluateExpression(arg, padFunc, ")",
-		func(expression string) (string, error) {
-			s := strings.Split(expression, ",")
-			if len(s) != 2 {
-				return "", errors.Errorf("invalid $pad expression: '%s'. Expecting $pad(n,chars)", expression)
-			}
-
-			n, err := strconv.Atoi(s[0])
-			if err != nil {
-				return "", errors.Errorf("invalid number %s in $pad expression\n", s[0])
-			}
-
-			result := ""
-			for i := 0; i < n; i++ {
-				result += s[1]
-			}
-
-			return result, nil
-		})
-}
-
-// evaluateFileExpression replaces occurrences of $file(path) with the contents of the file
-func evaluateFileExpression(arg string) string {
-	return evaluateExpression(arg, fileFunc, ")",
-		func(expression string) (string, error) {
-			return readFile(expression)
-		})
-}
-
-// evaluateSetExpression sets a variable to the given value using the syntax $set(var,expression).
+	return bytes, nil
+}
+
+// TransientToBytes evaluates the functions in the values of the transient map the same way as ArgToBytes.
+// Only the keys and the length of the values are printed since the transient data is usually private
+func TransientToBytes(ctxt Context, transient map[string]string) (map[string][]byte, error) {
+	if len(transient) == 0 {
+		return nil, nil
+	}
+	keys := make([]string, 0, len(transient))
+	for k := range transient {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	transientMap := make(map[string][]byte, len(transient))
+	fmt.Printf("Transient:\n")
+	for _, k := range keys {
+		value, err := getArg(ctxt, transient[k])
+		if err != nil {
+			return nil, errors.WithMessagef(err, "invalid transient value [%s]", k)
+		}
+		transientMap[k] = []byte(value)
+		fmt.Printf("- [%s]=(%d bytes)\n", k, len(transientMap[k]))
+	}
+	return transientMap, nil
+}
+
+func getArg(ctxt Context, arg string) (string, error) {
+	x, err := parseExpression(arg)
+	if err != nil {
+		return "", err
+	}
+	return x.eval(&evaluator{ctxt: ctxt, r: ctxt.Rand()})
+}
+
+// evaluator holds what the functions need to evaluate an arg
+type evaluator struct {
+	ctxt Context
+	r    *rand.Rand
+}
+
+// function is a function of the arg expressions, maxArgs is -1 for any number of arguments
+type function struct {
+	minArgs int
+	maxArgs int
+	eval    func(e *evaluator, args []string) (string, error)
+}
+
+func (f function) checkArgs(n int) error {
+	switch {
+	case f.minArgs == f.maxArgs && n != f.minArgs:
+		return errors.Errorf("expecting %d argument(s), got %d", f.minArgs, n)
+	case n < f.minArgs:
+		return errors.Errorf("expecting at least %d argument(s), got %d", f.minArgs, n)
+	case f.maxArgs >= 0 && n > f.maxArgs:
+		return errors.Errorf("expecting at most %d argument(s), got %d", f.maxArgs, n)
+	}
+	return nil
+}
+
+var functions map[string]function
+
+func init() {
+	functions = map[string]function{
+		"rand":     {1, 1, evalRand},
+		"pad":      {2, 2, evalPad},
+		"seq":      {0, 0, evalSeq},
+		"set":      {2, 2, evalSet},
+		"file":     {1, 1, evalFile},
+		"uuid":     {0, 0, evalUUID},
+		"now":      {0, 1, evalNow},
+		"hex":      {1, 1, evalHex},
+		"base64":   {1, 1, evalBase64},
+		"unbase64": {1, 1, evalUnbase64},
+		"sha256":   {1, 1, evalSHA256},
+		"randstr":  {1, 1, evalRandStr},
+		"choice":   {1, -1, evalChoice},
+		"json":     {0, -1, evalJSON},
+		"env":      {1, 1, evalEnv},
+		"uniform":  {1, 1, evalUniform},
+		"zipf":     {2, 2, evalZipf},
+		"hotspot":  {3, 3, evalHotspot},
+	}
+}
+
+func parseCount(s string) (int, error) {
+	n, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || n < 0 {
+		return 0, errors.Errorf("invalid number %s", s)
+	}
+	return n, nil
+}
+
+// evalRand returns a random number between 0 and n (exclusive)
+func evalRand(e *evaluator, args []string) (string, error) {
+	n, err := strconv.ParseInt(strings.TrimSpace(args[0]), 10, 64)
+	if err != nil || n <= 0 {
+		return "", errors.Errorf("invalid number %s", args[0])
+	}
+	return strconv.FormatInt(e.r.Int63n(n), 10), nil
+}
+
+// evalPad returns n of the given pad characters
+func evalPad(e *evaluator, args []string) (string, error) {
+	n, err := parseCount(args[0])
+	if err != nil {
+		return "", err
+	}
+	return strings.Repeat(args[1], n), nil
+}
+
+// evalSeq returns the next number of the sequence of the context, starting at 1
+func evalSeq(e *evaluator, args []string) (string, error) {
+	return strconv.FormatUint(e.ctxt.NextSeq(), 10), nil
+}
+
+// evalSet sets a variable to the given value using the syntax $set(var,expression).
 // The variable may be used in a subsequent expression, ${var}.
 // Example: $set(v,$rand(10)) sets variable "v" to a random value that may be accessed as ${v}
-func evaluateSetExpression(ctxt Context, arg string) string {
-	return evaluateExpression(arg, setFunc, ")",
-		func(expression string) (string, error) {
-			s := strings.Split(expression, ",")
-			if len(s) != 2 {
-				return "", errors.Errorf("invalid $set expression: '%s'. Expecting $set(var,value)", expression)
-			}
-
-			variable := s[0]
-			value := s[1]
-
-			ctxt.SetVar(variable, value)
-			return value, nil
-		})
-}
-
-// evaluateVarExpression references a variable previously set with the $set(var,expression) expression.
-// Example: ${someVar}
-func evaluateVarExpression(ctxt Context, arg string) string {
-	return evaluateExpression(arg, varExp, "}",
-		func(varName string) (string, error) {
-			value, ok := ctxt.GetVar(varName)
-			if !ok {
-				return "", errors.Errorf("variable [%s] not set", varName)
-			}
-			return value, nil
-		})
-}
-
-func evaluateExpression(expression, funcType, endDelim string, evaluate func(string) (string, error)) string {
-	result := ""
-	for {
-		i := strings.Index(expression, funcType)
-		if i == -1 {
-			result += expression
-			break
-		}
+func evalSet(e *evaluator, args []string) (string, error) {
+	e.ctxt.SetVar(args[0], args[1])
+	return args[1], nil
+}
 
-		j := strings.Index(expression[i:], endDelim)
-		if j == -1 {
-			fmt.Printf("expecting '%s' in expression '%s'", endDelim, expression)
-			result = expression
-			break
-		}
+// evalFile returns the contents of the file
+func evalFile(e *evaluator, args []string) (string, error) {
+	return readFile(args[0])
+}
 
-		j = i + j
+// evalUUID returns a random (version 4) UUID
+func evalUUID(e *evaluator, args []string) (string, error) {
+	b := make([]byte, 16)
+	e.r.Read(b)
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
 
-		replacement, err := evaluate(expression[i+len(funcType) : j])
-		if err != nil {
-			fmt.Printf("%s\n", err)
-			result += expression[0 : j+1]
+// evalNow returns the current time in the given layout, RFC3339 by default
+func evalNow(e *evaluator, args []string) (string, error) {
+	now := time.Now()
+	layout := time.RFC3339
+	if len(args) > 0 && args[0] != "" {
+		layout = args[0]
+	}
+	switch layout {
+	case "unix":
+		return strconv.FormatInt(now.Unix(), 10), nil
+	case "unixnano":
+		return strconv.FormatInt(now.UnixNano(), 10), nil
+	}
+	return now.Format(layout), nil
+}
+
+// evalHex returns n random bytes hex encoded
+func evalHex(e *evaluator, args []string) (string, error) {
+	n, err := parseCount(args[0])
+	if err != nil {
+		return "", err
+	}
+	b := make([]byte, n)
+	e.r.Read(b)
+	return hex.EncodeToString(b), nil
+}
+
+func evalBase64(e *evaluator, args []string) (string, error) {
+	return base64.StdEncoding.EncodeToString([]byte(args[0])), nil
+}
+
+// evalUnbase64 returns the value of the standard base64 encoded argument
+func evalUnbase64(e *evaluator, args []string) (string, error) {
+	value, err := base64.StdEncoding.DecodeString(strings.TrimSpace(args[0]))
+	if err != nil {
+		return "", errors.Wrap(err, "invalid base64 value")
+	}
+	return string(value), nil
+}
+
+// Literal returns an arg that evaluates to the value as is, e.g. to replay recorded args.
+// The backslashes and dollar signs are escaped, a value that is not valid UTF-8 is base64 encoded
+func Literal(value []byte) string {
+	if utf8.Valid(value) {
+		return literalReplacer.Replace(string(value))
+	}
+	return "$unbase64(" + base64.StdEncoding.EncodeToString(value) + ")"
+}
+
+var literalReplacer = strings.NewReplacer(`\`, `\\`, `$`, `\$`)
+
+// evalSHA256 returns the hex encoded SHA-256 hash of the value
+func evalSHA256(e *evaluator, args []string) (string, error) {
+	hash := sha256.Sum256([]byte(args[0]))
+	return hex.EncodeToString(hash[:]), nil
+}
+
+const alphanumeric = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// evalRandStr returns n random alphanumeric characters
+func evalRandStr(e *evaluator, args []string) (string, error) {
+	n, err := parseCount(args[0])
+	if err != nil {
+		return "", err
+	}
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = alphanumeric[e.r.Intn(len(alphanumeric))]
+	}
+	return string(b), nil
+}
+
+// evalChoice returns one of the values at random
+func evalChoice(e *evaluator, args []string) (string, error) {
+	return args[e.r.Intn(len(args))], nil
+}
+
+// evalJSON returns the JSON object of the key/value pairs, the values
+// that are valid JSON are kept as is, the others are strings
+func evalJSON(e *evaluator, args []string) (string, error) {
+	if len(args)%2 != 0 {
+		return "", errors.Errorf("expecting key/value pairs, got %d argument(s)", len(args))
+	}
+	var sb strings.Builder
+	sb.WriteString("{")
+	for i := 0; i < len(args); i += 2 {
+		if i > 0 {
+			sb.WriteString(",")
+		}
+		key, _ := json.Marshal(args[i])
+		sb.Write(key)
+		sb.WriteString(":")
+		if value := strings.TrimSpace(args[i+1]); value != "" && json.Valid([]byte(value)) {
+			sb.WriteString(value)
 		} else {
-			result += expression[0:i] + replacement
+			value, _ := json.Marshal(args[i+1])
+			sb.Write(value)
 		}
+	}
+	sb.WriteString("}")
+	return sb.String(), nil
+}
+
+// evalEnv returns the value of the environment variable
+func evalEnv(e *evaluator, args []string) (string, error) {
+	value, ok := os.LookupEnv(args[0])
+	if !ok {
+		return "", errors.Errorf("environment variable [%s] not set", args[0])
+	}
+	return value, nil
+}
+
+func parseKeys(s string) (int64, error) {
+	n, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	if err != nil || n <= 0 {
+		return 0, errors.Errorf("invalid number of keys %s", s)
+	}
+	return n, nil
+}
 
-		expression = expression[j+1:]
+// evalUniform returns a key index between 0 and n (exclusive), every key being equally likely
+func evalUniform(e *evaluator, args []string) (string, error) {
+	n, err := parseKeys(args[0])
+	if err != nil {
+		return "", err
 	}
+	return strconv.FormatInt(e.r.Int63n(n), 10), nil
+}
 
-	return result
+// evalZipf returns a key index between 0 and n (exclusive) following a Zipf distribution of exponent s,
+// the lower indexes being the hottest keys
+func evalZipf(e *evaluator, args []string) (string, error) {
+	n, err := parseKeys(args[0])
+	if err != nil {
+		return "", err
+	}
+	exp, err := strconv.ParseFloat(strings.TrimSpace(args[1]), 64)
+	if err != nil || exp <= 1 {
+		return "", errors.Errorf("invalid exponent %s, expecting a number greater than 1", args[1])
+	}
+	zipf := rand.NewZipf(e.r, exp, 1, uint64(n-1))
+	return strconv.FormatUint(zipf.Uint64(), 10), nil
+}
+
+// evalHotspot returns a key index between 0 and n (exclusive), one of the first hot keys
+// pct percent of the time and one of the other keys otherwise
+func evalHotspot(e *evaluator, args []string) (string, error) {
+	n, err := parseKeys(args[0])
+	if err != nil {
+		return "", err
+	}
+	pct, err := strconv.ParseFloat(strings.TrimSpace(args[1]), 64)
+	if err != nil || pct < 0 || pct > 100 {
+		return "", errors.Errorf("invalid percentage %s", args[1])
+	}
+	hot, err := parseKeys(args[2])
+	if err != nil || hot > n {
+		return "", errors.Errorf("invalid number of hot keys %s, expecting 1 to %d", args[2], n)
+	}
+	if hot == n || e.r.Float64()*100 < pct {
+		return strconv.FormatInt(e.r.Int63n(hot), 10), nil
+	}
+	return strconv.FormatInt(hot+e.r.Int63n(n-hot), 10), nil
 }
 
 func readFile(filePath string) (string, error) {
-	fmt.Printf("Reading file: [%s]", filePath)
+	logger.L().Debugf("Reading file: [%s]", filePath)
 	file, err := os.Open(filepath.Clean(filePath))
 	if err != nil {
 		return "", errors.Wrapf(err, "error opening file [%s]", filePath)
@@ -0,0 +1,174 @@
+package task
+
+import (
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestGetArg(t *testing.T) {
+	os.Setenv("FABRICLI_TEST_ENV", "env")
+	defer os.Unsetenv("FABRICLI_TEST_ENV")
+
+	ctxt := NewContextWithSeed(1)
+	ctxt.SetVar("v", "value")
+
+	tests := []struct {
+		arg     string
+		pattern string
+	}{
+		{"plain", `^plain$`},
+		{"$5 and $", `^\$5 and \$$`},
+		{"val$pad(3,XY)", `^valXYXYXY$`},
+		{"$pad($rand(1),X)", `^$`},
+		{"$pad(2,$pad(2,a))", `^aaaa$`},
+		{"${v}-$base64(${v})", `^value-dmFsdWU=$`},
+		{`$pad(2,a\,b)`, `^a,ba,b$`},
+		{`$pad(1,(x))`, `^\(x\)$`},
+		{`\$pad(1,x)`, `^\$pad\(1,x\)$`},
+		{"$sha256(hello)", `^2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824$`},
+		{"$uuid()", `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`},
+		{"$now(2006)", `^\d{4}$`},
+		{"$now(unix)", `^\d+$`},
+		{"$hex(4)", `^[0-9a-f]{8}$`},
+		{"$randstr(6)", `^[a-zA-Z0-9]{6}$`},
+		{"$choice(a,b)", `^(a|b)$`},
+		{`$json(name,x,size,$pad(2,1),tags,["a"])`, `^\{"name":"x","size":11,"tags":\["a"\]\}$`},
+		{"$env(FABRICLI_TEST_ENV)", `^env$`},
+		{"k$set(x,$pad(2,z))=${x}", `^kzz=zz$`},
+	}
+	for _, test := range tests {
+		value, err := getArg(ctxt, test.arg)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", test.arg, err)
+		}
+		if !regexp.MustCompile(test.pattern).MatchString(value) {
+			t.Fatalf("%s: unexpected value %s", test.arg, value)
+		}
+	}
+}
+
+func TestGetArg_Backslashes(t *testing.T) {
+	ctxt := NewContextWithSeed(1)
+	// only the backslashes before $ ( ) , and \ are escapes, the other ones are kept
+	for arg, expected := range map[string]string{
+		`a\"b`:                 `a\"b`,
+		`C:\dir`:               `C:\dir`,
+		`{"name":"a\"b"}`:      `{"name":"a\"b"}`,
+		`$pad(1,C:\dir\,x)`:    `C:\dir,x`,
+		`\$pad(1,x) \(\) \\ \`: `$pad(1,x) () \ \`,
+	} {
+		value, err := getArg(ctxt, arg)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", arg, err)
+		}
+		if value != expected {
+			t.Fatalf("%s: expecting %s, got %s", arg, expected, value)
+		}
+	}
+}
+
+func TestGetArg_Error(t *testing.T) {
+	tests := map[string]string{
+		"$unknown(1)":           "unknown function $unknown",
+		"$pad(1)":               "expecting 2 argument(s), got 1",
+		"$rand(x)":              "invalid number x",
+		"$pad($rand(0),X)":      "$rand: invalid number 0",
+		"$pad(2,X":              "expecting ')'",
+		"${missing}":            "variable [missing] not set",
+		"${open":                "expecting '}'",
+		"$json(a)":              "expecting key/value pairs",
+		"$env(FABRICLI_UNSET_)": "environment variable [FABRICLI_UNSET_] not set",
+		"$uuid(1)":              "expecting 0 argument(s), got 1",
+		"$choice()":             "expecting at least 1 argument(s), got 0",
+		"$zipf(10,1)":           "invalid exponent 1",
+		"$uniform(0)":           "invalid number of keys 0",
+		"$hotspot(10,101,2)":    "invalid percentage 101",
+		"$hotspot(10,50,11)":    "invalid number of hot keys 11",
+	}
+	for arg, expected := range tests {
+		_, err := getArg(NewContext(), arg)
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Fatalf("%s: expecting error containing '%s', got %v", arg, expected, err)
+		}
+	}
+}
+
+func TestArgToBytes_Error(t *testing.T) {
+	if _, err := ArgToBytes(NewContext(), []string{"ok", "$pad(1)"}); err == nil || !strings.Contains(err.Error(), "invalid arg [1]") {
+		t.Fatalf("expecting the invalid arg reported, got %v", err)
+	}
+	if _, err := TransientToBytes(NewContext(), map[string]string{"k": "$nope()"}); err == nil || !strings.Contains(err.Error(), "invalid transient value [k]") {
+		t.Fatalf("expecting the invalid transient value reported, got %v", err)
+	}
+}
+
+func TestArgToBytes_Seed(t *testing.T) {
+	args := []string{"key$seq()", "$rand(1000)-$randstr(8)", "$uuid()", "$choice(a,b,c,d)$hex(4)"}
+	evaluate := func(seed int64) [][][]byte {
+		ctxt := NewContextWithSeed(seed)
+		var values [][][]byte
+		for i := 0; i < 3; i++ {
+			bytes, err := ArgToBytes(ctxt, args)
+			if err != nil {
+				t.Fatal(err)
+			}
+			values = append(values, bytes)
+		}
+		return values
+	}
+
+	first := evaluate(42)
+	if !reflect.DeepEqual(first, evaluate(42)) {
+		t.Fatal("expecting the same args with the same seed")
+	}
+	if reflect.DeepEqual(first, evaluate(43)) {
+		t.Fatal("expecting different args with a different seed")
+	}
+	if string(first[0][0]) != "key1" || string(first[2][0]) != "key3" {
+		t.Fatalf("expecting the sequence of the run to start at 1, got %s and %s", first[0][0], first[2][0])
+	}
+}
+
+func TestKeyDistributions(t *testing.T) {
+	ctxt := NewContextWithSeed(7)
+	count := func(arg string, n int) []int {
+		counts := make([]int, n)
+		for i := 0; i < 10000; i++ {
+			value, err := getArg(ctxt, arg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			k, err := strconv.Atoi(value)
+			if err != nil || k < 0 || k >= n {
+				t.Fatalf("%s: unexpected key %s", arg, value)
+			}
+			counts[k]++
+		}
+		return counts
+	}
+
+	uniform := count("$uniform(10)", 10)
+	for k, c := range uniform {
+		if c < 800 || c > 1200 {
+			t.Fatalf("$uniform: unexpected count %d for key %d", c, k)
+		}
+	}
+
+	zipf := count("$zipf(100,1.5)", 100)
+	if zipf[0] < zipf[1] || zipf[1] < zipf[10] || zipf[0] < 3000 {
+		t.Fatalf("$zipf: expecting the lower keys to be the hottest, got %v", zipf[:11])
+	}
+
+	hotspot := count("$hotspot(100,90,5)", 100)
+	var hot int
+	for _, c := range hotspot[:5] {
+		hot += c
+	}
+	if hot < 8800 || hot > 9200 {
+		t.Fatalf("$hotspot: expecting 90%% of the keys to be hot, got %d", hot)
+	}
+}
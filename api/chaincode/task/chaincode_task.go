@@ -1,21 +1,28 @@
 package task
 
 import (
-	"errors"
+	"context"
 	"fmt"
+	"time"
 
 	"github.com/hyperledger/fabric-protos-go/peer"
 	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel"
 	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel/invoke"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/retry"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/pkg/errors"
 	"github.com/zhcppy/fabricli/logger"
+	"github.com/zhcppy/fabricli/stats"
 )
 
 type Task interface {
 	// Invoke invokes the task
 	Invoke()
 
+	// InvokeContext invokes the task, which returns as soon as possible once the context is done
+	InvokeContext(ctx context.Context)
+
 	// Attempts returns the number of invocation attempts that were made
 	// in order to achieve a successful response
 	Attempts() int
@@ -33,14 +40,23 @@ type ChaincodeTask struct {
 	args          ArgStruct
 	retryOpts     retry.Opts
 	startedCB     func()
-	completedCB   func(err error)
+	completedCB   func(result *stats.Result)
 	attempt       int
+	retried       []string
 	lastErr       error
 	isQuery       bool
+	tracker       *TxTracker
+	prepared      *prepared
+}
+
+// prepared is the request of a task whose args were evaluated ahead of the invocation
+type prepared struct {
+	request channel.Request
+	err     error
 }
 
 func NewCCTask(ctxt Context, id string, channelClient *channel.Client, targets []fab.Peer, chaincodeID string,
-	args ArgStruct, retryOpts retry.Opts, startedCB func(), completedCB func(err error), isQuery bool) *ChaincodeTask {
+	args ArgStruct, retryOpts retry.Opts, startedCB func(), completedCB func(result *stats.Result), isQuery bool) *ChaincodeTask {
 	return &ChaincodeTask{
 		ctxt:          ctxt,
 		id:            id,
@@ -56,45 +72,174 @@ func NewCCTask(ctxt Context, id string, channelClient *channel.Client, targets [
 	}
 }
 
+// SetTxTracker makes the task submit the transaction asynchronously, it completes once the orderer
+// accepts the transaction and the tracker reports the result once the transaction is committed
+func (t *ChaincodeTask) SetTxTracker(tracker *TxTracker) {
+	t.tracker = tracker
+}
+
 func (t *ChaincodeTask) Invoke() {
+	t.InvokeContext(context.Background())
+}
+
+// InvokeContext invokes the task with a request context derived from ctx. A task whose context
+// is done before it starts is skipped and not reported to the callbacks
+func (t *ChaincodeTask) InvokeContext(ctx context.Context) {
+	if err := ctx.Err(); err != nil {
+		logger.L().Debugf("(%s) - Skipping task: %s\n", t.id, err)
+		return
+	}
 	if t.isQuery {
-		t.Query()
+		t.doQuery(ctx)
+	} else if t.tracker != nil {
+		t.doInvokeAsync(ctx)
 	} else {
-		t.doInvoke()
+		t.doInvoke(ctx)
 	}
 }
 
-func (t *ChaincodeTask) doInvoke() {
-	t.startedCB()
-	logger.L().Debugf("(%s) - Invoking chaincode: %s, function: %s, args: %+v. Attempt #%d...\n",
-		t.id, t.chaincodeID, t.args.Func, t.args.Args, t.attempt)
+// timer records the start of the attempt and the end of its endorsement and ordering phases
+type timer struct {
+	start    time.Time
+	endorsed time.Time
+	ordered  time.Time
+}
+
+// Handle is the invoke.Handler that marks the end of the endorsement phase
+func (tm *timer) Handle(requestContext *invoke.RequestContext, clientContext *invoke.ClientContext) {
+	tm.endorsed = time.Now()
+}
 
+// endorsedHandler calls the timer once the endorsements are validated, then the next handler
+type endorsedHandler struct {
+	timer *timer
+	next  invoke.Handler
+}
+
+func (h *endorsedHandler) Handle(requestContext *invoke.RequestContext, clientContext *invoke.ClientContext) {
+	h.timer.Handle(requestContext, clientContext)
+	if h.next != nil {
+		h.next.Handle(requestContext, clientContext)
+	}
+}
+
+func (t *ChaincodeTask) requestOptions(ctx context.Context, tm *timer) []channel.RequestOption {
 	var opts []channel.RequestOption
+	opts = append(opts, channel.WithParentContext(ctx))
 	opts = append(opts, channel.WithRetry(t.retryOpts))
 	opts = append(opts, channel.WithBeforeRetry(func(err error) {
-		t.attempt++
+		t.beforeRetry(tm, err)
 	}))
 	if len(t.targets) > 0 {
 		opts = append(opts, channel.WithTargets(t.targets...))
 	}
+	return opts
+}
+
+// beforeRetry records the code of the failed attempt, e.g. a read conflict succeeding on retry, and restarts the timer
+func (t *ChaincodeTask) beforeRetry(tm *timer, err error) {
+	t.retried = append(t.retried, ErrorCodeOf(err))
+	t.attempt++
+	tm.start, tm.endorsed, tm.ordered = time.Now(), time.Time{}, time.Time{}
+}
 
-	response, err := t.channelClient.Execute(
-		channel.Request{
-			ChaincodeID: t.chaincodeID,
-			Fcn:         t.args.Func,
-			Args:        ArgToBytes(t.ctxt, t.args.Args),
+func (t *ChaincodeTask) newResult(start time.Time, tm *timer, response channel.Response) *stats.Result {
+	end := time.Now()
+	result := &stats.Result{
+		TaskID:   t.id,
+		TxID:     string(response.TransactionID),
+		Func:     t.args.Func,
+		Start:    start,
+		Latency:  end.Sub(start),
+		Attempts: t.attempt,
+	}
+	for _, code := range t.retried {
+		result.AddRetriedAttempt(code)
+	}
+	if !tm.endorsed.IsZero() {
+		result.Endorsement = tm.endorsed.Sub(tm.start)
+		if !tm.ordered.IsZero() {
+			result.Ordering = tm.ordered.Sub(tm.endorsed)
+		} else if !t.isQuery && t.tracker == nil {
+			result.Commit = end.Sub(tm.endorsed)
+		}
+	}
+	for _, resp := range response.Responses {
+		result.Endorsers = append(result.Endorsers, resp.Endorser)
+	}
+	return result
+}
+
+// Prepare evaluates the args of the task now instead of when it is invoked. Preparing the tasks in turn
+// as they are created makes the args independent of the order the workers invoke the tasks in
+func (t *ChaincodeTask) Prepare() {
+	args, transient, err := t.args.Evaluate(t.ctxt)
+	t.prepared = &prepared{
+		request: channel.Request{
+			ChaincodeID:  t.chaincodeID,
+			Fcn:          t.args.Func,
+			Args:         args,
+			TransientMap: transient,
 		},
-		opts...,
+		err: err,
+	}
+}
+
+// request returns the request of the task, evaluating its args unless the task is prepared. The task fails
+// with INVALID_ARGS if any of them cannot be evaluated, it is not retried since the same args would fail again
+func (t *ChaincodeTask) request() (channel.Request, bool) {
+	if t.prepared == nil {
+		t.Prepare()
+	}
+	if err := t.prepared.err; err != nil {
+		logger.L().Errorf("(%s) - Error evaluating args: %s\n", t.id, err)
+		t.lastErr = Wrapf(PersistentError, err, "invalid args")
+		t.completedCB(&stats.Result{
+			TaskID:   t.id,
+			Func:     t.args.Func,
+			Start:    time.Now(),
+			Attempts: t.attempt,
+			Code:     "INVALID_ARGS",
+			Error:    t.lastErr.Error(),
+		})
+		return channel.Request{}, false
+	}
+	return t.prepared.request, true
+}
+
+func (t *ChaincodeTask) doInvoke(ctx context.Context) {
+	t.startedCB()
+	logger.L().Debugf("(%s) - Invoking chaincode: %s, function: %s, args: %+v. Attempt #%d...\n",
+		t.id, t.chaincodeID, t.args.Func, t.args.Args, t.attempt)
+
+	request, ok := t.request()
+	if !ok {
+		return
+	}
+
+	start := time.Now()
+	tm := &timer{start: start}
+	response, err := t.channelClient.InvokeHandler(
+		invoke.NewSelectAndEndorseHandler(
+			invoke.NewEndorsementValidationHandler(
+				invoke.NewSignatureValidationHandler(&endorsedHandler{timer: tm, next: invoke.NewCommitHandler()}),
+			),
+		),
+		request,
+		t.requestOptions(ctx, tm)...,
 	)
-	if err != nil {
+	result := t.newResult(start, tm, response)
+	if err != nil && response.TxValidationCode == peer.TxValidationCode_VALID {
 		t.lastErr = Errorf(TransientError, "SendTransactionProposal return error: %v", err)
-		t.completedCB(err)
+		result.Code, result.Error = errorCode(ctx, err), err.Error()
+		t.completedCB(result)
 		return
 	}
 
 	fmt.Println(string(response.TransactionID))
 
-	switch peer.TxValidationCode(response.TxValidationCode) {
+	result.Code = response.TxValidationCode.String()
+	switch response.TxValidationCode {
 	case peer.TxValidationCode_VALID:
 		logger.L().Debugf("(%s) - Successfully committed transaction [%s] ...\n", t.id, response.TransactionID)
 	case peer.TxValidationCode_DUPLICATE_TXID, peer.TxValidationCode_MVCC_READ_CONFLICT, peer.TxValidationCode_PHANTOM_READ_CONFLICT:
@@ -107,48 +252,139 @@ func (t *ChaincodeTask) doInvoke() {
 
 	if err != nil {
 		t.lastErr = err
-		t.completedCB(err)
+		result.Error = err.Error()
+		t.completedCB(result)
 	} else {
 		logger.L().Debugf("(%s) - Successfully invoked chaincode\n", t.id)
-		t.completedCB(nil)
+		t.completedCB(result)
+	}
+}
+
+// submitHandler sends the endorsed transaction to the orderer without waiting for it to be committed,
+// the transaction is registered with the tracker first
+type submitHandler struct {
+	tracker *TxTracker
+	timer   *timer
+	tracked *TrackedTx
+}
+
+func (h *submitHandler) Handle(requestContext *invoke.RequestContext, clientContext *invoke.ClientContext) {
+	h.tracked = nil
+	tracked, err := h.tracker.Track(string(requestContext.Response.TransactionID))
+	if err != nil {
+		requestContext.Error = err
+		return
+	}
+	tx, err := clientContext.Transactor.CreateTransaction(fab.TransactionRequest{
+		Proposal:          requestContext.Response.Proposal,
+		ProposalResponses: requestContext.Response.Responses,
+	})
+	if err != nil {
+		tracked.Cancel()
+		requestContext.Error = errors.Wrap(err, "CreateTransaction failed")
+		return
+	}
+	if _, err = clientContext.Transactor.SendTransaction(tx); err != nil {
+		tracked.Cancel()
+		requestContext.Error = errors.Wrap(err, "SendTransaction failed")
+		return
 	}
+	h.timer.ordered = time.Now()
+	tracked.ordered = h.timer.ordered
+	h.tracked = tracked
 }
 
-func (t *ChaincodeTask) Query() {
+func (t *ChaincodeTask) doInvokeAsync(ctx context.Context) {
 	t.startedCB()
+	logger.L().Debugf("(%s) - Submitting chaincode: %s, function: %s, args: %+v. Attempt #%d...\n",
+		t.id, t.chaincodeID, t.args.Func, t.args.Args, t.attempt)
 
-	var opts []channel.RequestOption
-	opts = append(opts, channel.WithRetry(t.retryOpts))
-	opts = append(opts, channel.WithBeforeRetry(func(err error) {
-		t.attempt++
-	}))
-	if len(t.targets) > 0 {
-		opts = append(opts, channel.WithTargets(t.targets...))
+	request, ok := t.request()
+	if !ok {
+		return
 	}
 
-	request := channel.Request{
-		ChaincodeID: t.chaincodeID,
-		Fcn:         t.args.Func,
-		Args:        ArgToBytes(t.ctxt, t.args.Args),
+	start := time.Now()
+	tm := &timer{start: start}
+	submit := &submitHandler{tracker: t.tracker, timer: tm}
+	response, err := t.channelClient.InvokeHandler(
+		invoke.NewSelectAndEndorseHandler(
+			invoke.NewEndorsementValidationHandler(
+				invoke.NewSignatureValidationHandler(&endorsedHandler{timer: tm, next: submit}),
+			),
+		),
+		request,
+		t.requestOptions(ctx, tm)...,
+	)
+	result := t.newResult(start, tm, response)
+	if err != nil {
+		t.lastErr = Errorf(TransientError, "submitting transaction returned error: %v", err)
+		result.Code, result.Error = errorCode(ctx, err), err.Error()
+		t.completedCB(result)
+		return
+	}
+
+	fmt.Println(string(response.TransactionID))
+	logger.L().Debugf("(%s) - Successfully submitted transaction [%s] ...\n", t.id, response.TransactionID)
+	submit.tracked.Submitted(result)
+}
+
+func (t *ChaincodeTask) doQuery(ctx context.Context) {
+	t.startedCB()
+
+	request, ok := t.request()
+	if !ok {
+		return
 	}
 
+	start := time.Now()
+	tm := &timer{start: start}
 	response, err := t.channelClient.InvokeHandler(
 		invoke.NewProposalProcessorHandler(
 			invoke.NewEndorsementHandler(
 				// Add the validation handlers
-				[]invoke.Handler{invoke.NewEndorsementValidationHandler(invoke.NewSignatureValidationHandler())}...,
+				[]invoke.Handler{invoke.NewEndorsementValidationHandler(invoke.NewSignatureValidationHandler(tm))}...,
 			),
 		),
-		request, opts...)
+		request, t.requestOptions(ctx, tm)...)
+	result := t.newResult(start, tm, response)
 	if err != nil {
 		logger.L().Debugf("(%s) - Error querying chaincode: %s\n", t.id, err)
 		t.lastErr = err
-		t.completedCB(err)
+		result.Code, result.Error = errorCode(ctx, err), err.Error()
+		t.completedCB(result)
 	} else {
 		logger.L().Debugf("(%s) - Chaincode query was successful\n", t.id)
 
 		fmt.Println(response)
-		t.completedCB(nil)
+		result.Code = "OK"
+		t.completedCB(result)
+	}
+}
+
+// ErrorCodeOf returns the code of the error returned by the channel client, e.g. ENDORSER_SERVER_STATUS(500)
+// or the validation code of the transaction
+func ErrorCodeOf(err error) string {
+	s, ok := status.FromError(err)
+	if !ok {
+		return status.UnknownStatus.String()
+	}
+	if s.Group == status.EventServerStatus {
+		return status.ToTransactionValidationCode(s.Code).String()
+	}
+	return fmt.Sprintf("%s(%d)", s.Group, s.Code)
+}
+
+// errorCode returns CANCELED or TIMEOUT when the error is due to the context being done,
+// the code of the error otherwise
+func errorCode(ctx context.Context, err error) string {
+	switch ctx.Err() {
+	case context.Canceled:
+		return "CANCELED"
+	case context.DeadlineExceeded:
+		return "TIMEOUT"
+	default:
+		return ErrorCodeOf(err)
 	}
 }
 
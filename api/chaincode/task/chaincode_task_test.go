@@ -0,0 +1,34 @@
+package task
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/retry"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
+	"github.com/zhcppy/fabricli/stats"
+)
+
+func TestChaincodeTask_RetriedConflicts(t *testing.T) {
+	recorder := stats.NewRecorder()
+	ccTask := NewCCTask(NewContext(), "1", nil, nil, "mycc", ArgStruct{Func: "move"}, retry.Opts{}, func() {}, recorder.Add, false)
+
+	// the first attempt fails with an MVCC conflict and a phantom read, the third one commits
+	start := time.Now()
+	tm := &timer{start: start}
+	ccTask.beforeRetry(tm, status.New(status.EventServerStatus, int32(peer.TxValidationCode_MVCC_READ_CONFLICT), "mvcc", nil))
+	ccTask.beforeRetry(tm, status.New(status.EventServerStatus, int32(peer.TxValidationCode_PHANTOM_READ_CONFLICT), "phantom", nil))
+	result := ccTask.newResult(start, tm, channel.Response{TxValidationCode: peer.TxValidationCode_VALID})
+	result.Code = peer.TxValidationCode_VALID.String()
+	recorder.Add(result)
+
+	summary := recorder.Summary()
+	if summary.Success != 1 || summary.Attempts != 3 {
+		t.Fatalf("expecting 1 successful invocation after 3 attempts, got %+v", summary)
+	}
+	if summary.MVCCConflicts != 1 || summary.PhantomConflicts != 1 {
+		t.Fatalf("expecting the conflicts of the retried attempts, got %+v", summary)
+	}
+}
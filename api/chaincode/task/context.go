@@ -5,26 +5,81 @@
 */
 package task
 
+import (
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Context is the scope of a run: the variables set by the args, the random values and the sequence.
+// A context is safe for concurrent use
 type Context interface {
 	SetVar(name, value string)
 	GetVar(name string) (string, bool)
+
+	// Rand returns the source of the random values of the args
+	Rand() *rand.Rand
+	// NextSeq returns the next value of the sequence, starting at 1
+	NextSeq() uint64
 }
 
+// NewContext returns a context with a random seed
 func NewContext() Context {
+	return NewContextWithSeed(time.Now().UTC().UnixNano())
+}
+
+// NewContextWithSeed returns a context whose random values are generated from the seed,
+// the args evaluated in the same order with the same seed are identical
+func NewContextWithSeed(seed int64) Context {
 	return &defaultContext{
 		vars: make(map[string]string),
+		rand: rand.New(&lockedSource{src: rand.NewSource(seed)}),
 	}
 }
 
 type defaultContext struct {
-	vars map[string]string
+	mutex    sync.RWMutex
+	vars     map[string]string
+	rand     *rand.Rand
+	sequence uint64
 }
 
 func (c *defaultContext) SetVar(k, v string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
 	c.vars[k] = v
 }
 
 func (c *defaultContext) GetVar(k string) (string, bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
 	value, ok := c.vars[k]
 	return value, ok
 }
+
+func (c *defaultContext) Rand() *rand.Rand {
+	return c.rand
+}
+
+func (c *defaultContext) NextSeq() uint64 {
+	return atomic.AddUint64(&c.sequence, 1)
+}
+
+// lockedSource makes a rand.Source safe for concurrent use
+type lockedSource struct {
+	mutex sync.Mutex
+	src   rand.Source
+}
+
+func (s *lockedSource) Int63() int64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.src.Int63()
+}
+
+func (s *lockedSource) Seed(seed int64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.src.Seed(seed)
+}
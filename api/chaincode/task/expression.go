@@ -0,0 +1,196 @@
+package task
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// node is a part of a parsed arg expression
+type node interface {
+	eval(e *evaluator) (string, error)
+}
+
+// text is literal text
+type text string
+
+func (t text) eval(e *evaluator) (string, error) {
+	return string(t), nil
+}
+
+// variable is a reference to a variable, ${name}
+type variable string
+
+func (v variable) eval(e *evaluator) (string, error) {
+	value, ok := e.ctxt.GetVar(string(v))
+	if !ok {
+		return "", errors.Errorf("variable [%s] not set", string(v))
+	}
+	return value, nil
+}
+
+// call is a function call, $name(arg,...), each arg is an expression itself
+type call struct {
+	name string
+	args []expression
+}
+
+func (c *call) eval(e *evaluator) (string, error) {
+	fn, ok := functions[c.name]
+	if !ok {
+		return "", errors.Errorf("unknown function $%s", c.name)
+	}
+	args := make([]string, len(c.args))
+	for i, arg := range c.args {
+		value, err := arg.eval(e)
+		if err != nil {
+			return "", err
+		}
+		args[i] = value
+	}
+	if err := fn.checkArgs(len(args)); err != nil {
+		return "", errors.WithMessagef(err, "$%s", c.name)
+	}
+	value, err := fn.eval(e, args)
+	if err != nil {
+		return "", errors.WithMessagef(err, "$%s", c.name)
+	}
+	return value, nil
+}
+
+// expression is a sequence of text, variables and function calls
+type expression []node
+
+func (x expression) eval(e *evaluator) (string, error) {
+	var sb strings.Builder
+	for _, n := range x {
+		value, err := n.eval(e)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString(value)
+	}
+	return sb.String(), nil
+}
+
+// parser is a recursive descent parser of arg expressions:
+//
+//	expression = { text | "${" name "}" | "$" name "(" [ expression { "," expression } ] ")" }
+//
+// A backslash escapes the characters of the grammar: \$ \( \) \, and \\. The other backslashes are
+// literal, e.g. in "a\"b" or C:\dir. Within the arguments of a function, parentheses that are not
+// part of a call must be balanced, otherwise they have to be escaped
+type parser struct {
+	input string
+	pos   int
+}
+
+// escaped are the characters a backslash escapes
+const escaped = "$(),\\"
+
+func parseExpression(input string) (expression, error) {
+	p := &parser{input: input}
+	x, err := p.parse(false)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.input) {
+		return nil, errors.Errorf("unexpected '%c' at position %d in '%s'", p.input[p.pos], p.pos, p.input)
+	}
+	return x, nil
+}
+
+// parse parses an expression, within the arguments of a function it stops at a top level ',' or ')'
+func (p *parser) parse(inArgs bool) (expression, error) {
+	var x expression
+	var sb strings.Builder
+	flush := func() {
+		if sb.Len() > 0 {
+			x = append(x, text(sb.String()))
+			sb.Reset()
+		}
+	}
+	depth := 0
+	for p.pos < len(p.input) {
+		c := p.input[p.pos]
+		switch {
+		case c == '\\' && p.pos+1 < len(p.input) && strings.IndexByte(escaped, p.input[p.pos+1]) >= 0:
+			sb.WriteByte(p.input[p.pos+1])
+			p.pos += 2
+			continue
+		case c == '$':
+			n, err := p.parseDollar()
+			if err != nil {
+				return nil, err
+			}
+			if n != nil {
+				flush()
+				x = append(x, n)
+				continue
+			}
+		case inArgs && c == '(':
+			depth++
+		case inArgs && c == ')':
+			if depth == 0 {
+				flush()
+				return x, nil
+			}
+			depth--
+		case inArgs && c == ',' && depth == 0:
+			flush()
+			return x, nil
+		}
+		sb.WriteByte(c)
+		p.pos++
+	}
+	flush()
+	return x, nil
+}
+
+// parseDollar parses a variable or a function call at the current position,
+// nil is returned when the '$' is literal, e.g. "$5"
+func (p *parser) parseDollar() (node, error) {
+	start := p.pos
+	rest := p.input[p.pos+1:]
+	if strings.HasPrefix(rest, "{") {
+		end := strings.IndexByte(rest, '}')
+		if end == -1 {
+			return nil, errors.Errorf("expecting '}' in '%s'", p.input[start:])
+		}
+		p.pos += end + 2
+		return variable(rest[1:end]), nil
+	}
+
+	name := 0
+	for name < len(rest) && isNameChar(rest[name]) {
+		name++
+	}
+	if name == 0 || name == len(rest) || rest[name] != '(' {
+		return nil, nil
+	}
+	c := &call{name: rest[:name]}
+	p.pos += name + 2
+	for {
+		arg, err := p.parse(true)
+		if err != nil {
+			return nil, err
+		}
+		if p.pos >= len(p.input) {
+			return nil, errors.Errorf("expecting ')' in '%s'", p.input[start:])
+		}
+		c.args = append(c.args, arg)
+		p.pos++
+		if p.input[p.pos-1] == ')' {
+			break
+		}
+	}
+	// $f() is a call without arguments
+	if len(c.args) == 1 && len(c.args[0]) == 0 {
+		c.args = nil
+	}
+	return c, nil
+}
+
+func isNameChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_'
+}
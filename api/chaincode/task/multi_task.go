@@ -1,5 +1,7 @@
 package task
 
+import "context"
+
 // MultiTask contains a set of Tasks to be invoked synchronously
 type MultiTask struct {
 	tasks       []Task
@@ -20,10 +22,18 @@ func (m *MultiTask) Add(task Task) {
 
 // Invoke invokes the task
 func (m *MultiTask) Invoke() {
+	m.InvokeContext(context.Background())
+}
+
+// InvokeContext invokes the tasks in turn until the context is done
+func (m *MultiTask) InvokeContext(ctx context.Context) {
 	defer m.completedCB()
 
 	for _, task := range m.tasks {
-		task.Invoke()
+		if ctx.Err() != nil {
+			return
+		}
+		task.InvokeContext(ctx)
 	}
 }
 
@@ -0,0 +1,98 @@
+package task
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/pkg/errors"
+	"github.com/zhcppy/fabricli/logger"
+	"github.com/zhcppy/fabricli/stats"
+)
+
+// TxTracker tracks the commit status of the transactions submitted asynchronously. The result of
+// a transaction is completed and reported to the callback once its status event is received
+type TxTracker struct {
+	ctx         context.Context
+	events      fab.EventService
+	timeout     time.Duration
+	completedCB func(result *stats.Result)
+	pending     int64
+	wg          sync.WaitGroup
+}
+
+// NewTxTracker creates a tracker that waits up to timeout for the status of each transaction,
+// the transactions still pending once the context is done are reported as canceled
+func NewTxTracker(ctx context.Context, events fab.EventService, timeout time.Duration, completedCB func(result *stats.Result)) *TxTracker {
+	return &TxTracker{ctx: ctx, events: events, timeout: timeout, completedCB: completedCB}
+}
+
+// TrackedTx is a transaction registered with the tracker
+type TrackedTx struct {
+	tracker  *TxTracker
+	txID     string
+	reg      fab.Registration
+	statusCh <-chan *fab.TxStatusEvent
+	ordered  time.Time
+}
+
+// Track registers for the status of the transaction, it must be called before the transaction
+// is sent to the orderer so that the status event cannot be missed
+func (t *TxTracker) Track(txID string) (*TrackedTx, error) {
+	reg, statusCh, err := t.events.RegisterTxStatusEvent(txID)
+	if err != nil {
+		return nil, errors.Wrap(err, "error registering for TxStatus event")
+	}
+	return &TrackedTx{tracker: t, txID: txID, reg: reg, statusCh: statusCh}, nil
+}
+
+// Cancel stops tracking a transaction that could not be sent
+func (tx *TrackedTx) Cancel() {
+	tx.tracker.events.Unregister(tx.reg)
+}
+
+// Submitted waits in the background for the status of the transaction accepted by the orderer
+// and then completes the result with the commit latency and the validation code
+func (tx *TrackedTx) Submitted(result *stats.Result) {
+	t := tx.tracker
+	atomic.AddInt64(&t.pending, 1)
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		defer atomic.AddInt64(&t.pending, -1)
+		defer t.events.Unregister(tx.reg)
+
+		timeout := time.NewTimer(t.timeout)
+		defer timeout.Stop()
+		select {
+		case txStatus := <-tx.statusCh:
+			result.Code = txStatus.TxValidationCode.String()
+			if txStatus.TxValidationCode != peer.TxValidationCode_VALID {
+				result.Error = "transaction [" + tx.txID + "] is invalid: " + result.Code
+			}
+		case <-timeout.C:
+			result.Code, result.Error = "TIMEOUT", "no status received for transaction ["+tx.txID+"] within "+t.timeout.String()
+		case <-t.ctx.Done():
+			result.Code, result.Error = "CANCELED", "stopped waiting for the status of transaction ["+tx.txID+"]"
+		}
+		now := time.Now()
+		result.Commit = now.Sub(tx.ordered)
+		result.Latency = now.Sub(result.Start)
+		logger.L().Debugf("Transaction [%s] completed with code %s\n", tx.txID, result.Code)
+		t.completedCB(result)
+	}()
+}
+
+// Pending returns the number of transactions waiting for their status
+func (t *TxTracker) Pending() int {
+	return int(atomic.LoadInt64(&t.pending))
+}
+
+// Wait waits until the status of all the submitted transactions is received, has timed out
+// or the context is done
+func (t *TxTracker) Wait() {
+	t.wg.Wait()
+}
@@ -0,0 +1,78 @@
+package task
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/zhcppy/fabricli/stats"
+)
+
+type fakeEventService struct {
+	fab.EventService
+	mutex        sync.Mutex
+	channels     map[string]chan *fab.TxStatusEvent
+	unregistered int
+}
+
+func (s *fakeEventService) RegisterTxStatusEvent(txID string) (fab.Registration, <-chan *fab.TxStatusEvent, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	ch := make(chan *fab.TxStatusEvent, 1)
+	s.channels[txID] = ch
+	return txID, ch, nil
+}
+
+func (s *fakeEventService) Unregister(reg fab.Registration) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.unregistered++
+}
+
+func (s *fakeEventService) commit(txID string, code peer.TxValidationCode) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.channels[txID] <- &fab.TxStatusEvent{TxID: txID, TxValidationCode: code}
+}
+
+func TestTxTracker(t *testing.T) {
+	events := &fakeEventService{channels: map[string]chan *fab.TxStatusEvent{}}
+	recorder := stats.NewRecorder()
+	tracker := NewTxTracker(context.Background(), events, 100*time.Millisecond, recorder.Add)
+
+	for _, txID := range []string{"tx1", "tx2", "tx3", "tx4"} {
+		tx, err := tracker.Track(txID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if txID == "tx4" {
+			tx.Cancel()
+			continue
+		}
+		tx.ordered = time.Now()
+		tx.Submitted(&stats.Result{TxID: txID, Start: time.Now()})
+	}
+	if tracker.Pending() != 3 {
+		t.Fatalf("expecting 3 pending transactions, got %d", tracker.Pending())
+	}
+	events.commit("tx1", peer.TxValidationCode_VALID)
+	events.commit("tx2", peer.TxValidationCode_MVCC_READ_CONFLICT)
+	tracker.Wait()
+
+	codes := map[string]string{}
+	for _, result := range recorder.Results() {
+		codes[result.TxID] = result.Code
+	}
+	if len(codes) != 3 || codes["tx1"] != "VALID" || codes["tx2"] != "MVCC_READ_CONFLICT" || codes["tx3"] != "TIMEOUT" {
+		t.Fatalf("unexpected codes %v", codes)
+	}
+	if success, failed := recorder.Counts(); success != 1 || failed != 2 {
+		t.Fatalf("expecting 1 success and 2 failures, got %d and %d", success, failed)
+	}
+	if tracker.Pending() != 0 || events.unregistered != 4 {
+		t.Fatalf("expecting all the registrations released, %d pending, %d unregistered", tracker.Pending(), events.unregistered)
+	}
+}
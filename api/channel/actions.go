@@ -7,6 +7,8 @@ package channel
 
 import (
 	"fmt"
+	"os"
+	"sort"
 
 	"github.com/hyperledger/fabric-sdk-go/pkg/client/resmgmt"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
@@ -21,10 +23,12 @@ type Channel struct {
 	OrgID     string
 	ChannelID string
 
-	user    msp.SigningIdentity
-	orderer fab.Orderer
-	client  *resmgmt.Client
-	action  *actions.Action
+	username string
+	orgIDs   []string
+	user     msp.SigningIdentity
+	orderer  fab.Orderer
+	client   *resmgmt.Client
+	action   *actions.Action
 }
 
 func NewChannelAction(c *api.Config) (*Channel, error) {
@@ -32,7 +36,7 @@ func NewChannelAction(c *api.Config) (*Channel, error) {
 	if err != nil {
 		return nil, err
 	}
-	user, err := action.User(c.OrgID, c.PeerUrl, c.Username)
+	user, err := action.User(c.DefaultOrgID(), c.DefaultPeerURL(), c.Username)
 	if err != nil {
 		return nil, err
 	}
@@ -48,16 +52,50 @@ func NewChannelAction(c *api.Config) (*Channel, error) {
 	}
 
 	return &Channel{
-		action:  action,
-		client:  client,
-		orderer: orderer,
+		OrgID:     c.DefaultOrgID(),
+		ChannelID: c.ChannelID,
+		username:  c.Username,
+		orgIDs:    c.OrgIDs(),
+		user:      user,
+		action:    action,
+		client:    client,
+		orderer:   orderer,
 	}, nil
 }
 
-func (c *Channel) Create() (txID fab.TransactionID, err error) {
+// signers returns the user of each org, the orgs of the config by default
+func (c *Channel) signers(orgIDs []string) ([]msp.SigningIdentity, error) {
+	if len(orgIDs) == 0 {
+		orgIDs = c.orgIDs
+	}
+	if len(orgIDs) == 0 {
+		return []msp.SigningIdentity{c.user}, nil
+	}
+	var signers []msp.SigningIdentity
+	for _, orgID := range orgIDs {
+		user, err := c.action.UserByOrg(orgID, c.username)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "failed to load the signer of org [%s]", orgID)
+		}
+		signers = append(signers, user)
+	}
+	return signers, nil
+}
+
+// Create creates the channel, or updates it, with the channel config transaction of the file generated
+// by configtxgen (channel.tx). The transaction is signed by the user of each of the orgs, by default the
+// orgs of the config, so that it satisfies the channel creation policy of the consortium
+func (c *Channel) Create(txFile string, orgIDs ...string) (fab.TransactionID, error) {
 	logger.L().Infof("Attempting to create/update channel: %s", c.ChannelID)
+	if _, err := os.Stat(txFile); err != nil {
+		return "", errors.Wrap(err, "invalid channel tx file")
+	}
+	signers, err := c.signers(orgIDs)
+	if err != nil {
+		return "", err
+	}
 
-	req := resmgmt.SaveChannelRequest{ChannelID: c.ChannelID, SigningIdentities: []msp.SigningIdentity{c.user}}
+	req := resmgmt.SaveChannelRequest{ChannelID: c.ChannelID, ChannelConfigPath: txFile, SigningIdentities: signers}
 	resp, err := c.client.SaveChannel(req, resmgmt.WithOrderer(c.orderer))
 	if err != nil {
 		return "", errors.Errorf("Error from save channel: %s", err.Error())
@@ -65,6 +103,50 @@ func (c *Channel) Create() (txID fab.TransactionID, err error) {
 	return resp.TransactionID, nil
 }
 
+// AnchorUpdate is the anchor peer update transaction of an org
+type AnchorUpdate struct {
+	OrgID  string
+	TxFile string
+	TxID   fab.TransactionID
+}
+
+// UpdateAnchors submits the anchor peer update transaction file of each org generated by
+// configtxgen (Org1MSPanchors.tx), each one signed and submitted by the user of its org.
+// The updates are submitted in the order of the orgs and stop at the first error
+func (c *Channel) UpdateAnchors(txFiles map[string]string) ([]AnchorUpdate, error) {
+	if len(txFiles) == 0 {
+		return nil, errors.New("no anchor peer update given")
+	}
+	var updates []AnchorUpdate
+	for orgID, txFile := range txFiles {
+		if _, err := os.Stat(txFile); err != nil {
+			return nil, errors.Wrapf(err, "invalid anchor peer update file of org [%s]", orgID)
+		}
+		updates = append(updates, AnchorUpdate{OrgID: orgID, TxFile: txFile})
+	}
+	sort.Slice(updates, func(i, j int) bool { return updates[i].OrgID < updates[j].OrgID })
+
+	for i := range updates {
+		update := &updates[i]
+		logger.L().Infof("Updating the anchor peers of org [%s] on channel: %s", update.OrgID, c.ChannelID)
+		user, err := c.action.UserByOrg(update.OrgID, c.username)
+		if err != nil {
+			return updates[:i], errors.WithMessagef(err, "failed to load the user of org [%s]", update.OrgID)
+		}
+		client, err := c.action.ResourceMgmtClient(user)
+		if err != nil {
+			return updates[:i], err
+		}
+		req := resmgmt.SaveChannelRequest{ChannelID: c.ChannelID, ChannelConfigPath: update.TxFile, SigningIdentities: []msp.SigningIdentity{user}}
+		resp, err := client.SaveChannel(req, resmgmt.WithOrderer(c.orderer))
+		if err != nil {
+			return updates[:i], errors.WithMessagef(err, "failed to update the anchor peers of org [%s]", update.OrgID)
+		}
+		update.TxID = resp.TransactionID
+	}
+	return updates, nil
+}
+
 func (c *Channel) Join() error {
 	logger.L().Debugf("Attempting to join channel: %s\n", c.ChannelID)
 
@@ -2,15 +2,13 @@ package channel
 
 import (
 	"fmt"
+	"strings"
 
-	"github.com/zhcppy/fabricli/api"
-
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
-)
-
-const (
-	create = "create"
-	join   = "join"
+	"github.com/zhcppy/fabricli/api"
+	"github.com/zhcppy/fabricli/cmd"
+	"github.com/zhcppy/fabricli/printer"
 )
 
 func NewCmd() *cobra.Command {
@@ -22,28 +20,271 @@ func NewCmd() *cobra.Command {
 		},
 	}
 
-	channelCmd.AddCommand(&cobra.Command{Use: create, Run: channelRun})
-	channelCmd.AddCommand(&cobra.Command{Use: join, Run: channelRun})
+	channelCmd.AddCommand(newCreateCmd())
+	channelCmd.AddCommand(newJoinCmd())
+	channelCmd.AddCommand(newUpdateAnchorsCmd())
+	channelCmd.AddCommand(newConfigCmd())
+	channelCmd.AddCommand(newAddOrgCmd())
 	return channelCmd
 }
 
-func channelRun(cmd *cobra.Command, args []string) {
-	config := api.GetConfig()
-	channel, err := NewChannelAction(config)
-	if err != nil {
-		panic(err)
+func newCreateCmd() *cobra.Command {
+	createCmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create or update a channel from a channel config transaction file",
+		Long: "Create or update a channel from the channel config transaction file generated by configtxgen, " +
+			"the transaction is signed by the user of each org of --orgid",
+		Example: "channel create --cid mychannel --txfile ./channel-artifacts/channel.tx --orgid Org1,Org2",
+		RunE: func(c *cobra.Command, args []string) error {
+			txFile, _ := c.Flags().GetString(cmd.TxFileFlag)
+			if txFile == "" {
+				return errors.New("the channel tx file is required")
+			}
+			channel, err := NewChannelAction(api.ConfigFlags(c.Flags()))
+			if err != nil {
+				return err
+			}
+			defer channel.Close()
+			txID, err := channel.Create(txFile)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Success to create channel [%s], TX [%s]\n", channel.ChannelID, txID)
+			return nil
+		},
 	}
-	if cmd.Use == create {
-		tx, err := channel.Create()
-		if err != nil {
-			panic(err)
-		}
-		fmt.Printf("Success to create channel [%s], TX [%s]", channel.ChannelID, tx)
+	cmd.InitTxFile(createCmd.Flags())
+	return createCmd
+}
+
+func newJoinCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:     "join",
+		Short:   "Join the peers of the org to a channel",
+		Example: "channel join --cid mychannel --orgid Org1",
+		RunE: func(c *cobra.Command, args []string) error {
+			channel, err := NewChannelAction(api.ConfigFlags(c.Flags()))
+			if err != nil {
+				return err
+			}
+			defer channel.Close()
+			return channel.Join()
+		},
+	}
+}
+
+func newUpdateAnchorsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "update-anchors ORG=FILE...",
+		Short: "Update the anchor peers of orgs from their anchor peer update transaction files",
+		Long: "Submit the anchor peer update transaction file of each org generated by configtxgen, " +
+			"each one is signed and submitted by the user of its org",
+		Example: "channel update-anchors Org1=./channel-artifacts/Org1MSPanchors.tx Org2=./channel-artifacts/Org2MSPanchors.tx --cid mychannel",
+		Args:    cobra.MinimumNArgs(1),
+		RunE: func(c *cobra.Command, args []string) error {
+			txFiles, err := parseTxFiles(args)
+			if err != nil {
+				return err
+			}
+			channel, err := NewChannelAction(api.ConfigFlags(c.Flags()))
+			if err != nil {
+				return err
+			}
+			defer channel.Close()
+			updates, err := channel.UpdateAnchors(txFiles)
+			var rows [][]string
+			for _, update := range updates {
+				rows = append(rows, []string{update.OrgID, update.TxFile, string(update.TxID)})
+			}
+			if len(rows) > 0 {
+				printer.Table([]string{"ORG", "FILE", "TX"}, rows)
+			}
+			return err
+		},
 	}
-	if cmd.Use == join {
-		err := channel.Join()
-		if err != nil {
-			panic(err)
+}
+
+func newConfigCmd() *cobra.Command {
+	configCmd := &cobra.Command{
+		Use:   "config",
+		Short: "Update the config of a channel",
+		Long: "Update the config of a channel: fetch the config as JSON, edit it, compute the config update, " +
+			"collect the signatures of the orgs offline and submit it",
+		Example: "channel config fetch --cid mychannel --out config.json\n" +
+			"channel config update --cid mychannel --original config.json --updated modified_config.json --out config_update.pb\n" +
+			"channel config sign --txfile config_update.pb --orgid Org1\n" +
+			"channel config submit --cid mychannel --txfile config_update.pb --orgid Org2",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.HelpFunc()(cmd, args)
+		},
+	}
+	configCmd.AddCommand(newConfigFetchCmd())
+	configCmd.AddCommand(newConfigUpdateCmd())
+	configCmd.AddCommand(newConfigSignCmd())
+	configCmd.AddCommand(newConfigSubmitCmd())
+	return configCmd
+}
+
+func newConfigFetchCmd() *cobra.Command {
+	fetchCmd := &cobra.Command{
+		Use:     "fetch",
+		Short:   "Write the config of the latest config block of a channel as editable JSON",
+		Example: "channel config fetch --cid mychannel --out config.json",
+		RunE: func(c *cobra.Command, args []string) error {
+			out, _ := c.Flags().GetString(cmd.OutputFileFlag)
+			channel, err := NewChannelAction(api.ConfigFlags(c.Flags()))
+			if err != nil {
+				return err
+			}
+			defer channel.Close()
+			number, err := channel.FetchConfig(out)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Wrote the config of block [%d] of channel [%s] to %s\n", number, channel.ChannelID, out)
+			return nil
+		},
+	}
+	cmd.InitOutputFile(fetchCmd.Flags(), "config.json")
+	return fetchCmd
+}
+
+func newConfigUpdateCmd() *cobra.Command {
+	updateCmd := &cobra.Command{
+		Use:   "update",
+		Short: "Compute the config update from the original JSON config to the edited one",
+		Long: "Compute the config update from the original JSON config to the edited one as configtxlator compute_update does, " +
+			"the config update is written in an envelope to be signed and submitted. It does not connect to the network",
+		Example: "channel config update --cid mychannel --original config.json --updated modified_config.json --out config_update.pb",
+		RunE: func(c *cobra.Command, args []string) error {
+			original, _ := c.Flags().GetString(cmd.OriginalConfigFlag)
+			updated, _ := c.Flags().GetString(cmd.UpdatedConfigFlag)
+			out, _ := c.Flags().GetString(cmd.OutputFileFlag)
+			if original == "" || updated == "" {
+				return errors.New("the original and the updated config are required")
+			}
+			config := api.ConfigFlags(c.Flags())
+			if config.ChannelID == "" {
+				return errors.New("the channel ID is required")
+			}
+			if err := ComputeUpdate(config.ChannelID, original, updated, out); err != nil {
+				return err
+			}
+			fmt.Printf("Wrote the config update of channel [%s] to %s\n", config.ChannelID, out)
+			return nil
+		},
+	}
+	cmd.InitOriginalConfig(updateCmd.Flags())
+	cmd.InitUpdatedConfig(updateCmd.Flags())
+	cmd.InitOutputFile(updateCmd.Flags(), "config_update.pb")
+	return updateCmd
+}
+
+func newConfigSignCmd() *cobra.Command {
+	signCmd := &cobra.Command{
+		Use:   "sign",
+		Short: "Sign a config update as the user of the org",
+		Long: "Add the signature of the user of the org to the config update file, in place unless --out is given. " +
+			"Each org signs the file in turn before it is submitted",
+		Example: "channel config sign --txfile config_update.pb --orgid Org1",
+		RunE: func(c *cobra.Command, args []string) error {
+			txFile, _ := c.Flags().GetString(cmd.TxFileFlag)
+			out, _ := c.Flags().GetString(cmd.OutputFileFlag)
+			if txFile == "" {
+				return errors.New("the config update file is required")
+			}
+			channel, err := NewChannelAction(api.ConfigFlags(c.Flags()))
+			if err != nil {
+				return err
+			}
+			defer channel.Close()
+			signers, err := channel.SignConfigUpdate(txFile, out)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Signed the config update as [%s], signed by %s\n", channel.OrgID, strings.Join(signers, ", "))
+			return nil
+		},
+	}
+	cmd.InitTxFile(signCmd.Flags(), "", "The path of the config update file")
+	cmd.InitOutputFile(signCmd.Flags(), "", "The path of the signed config update file, the config update file by default")
+	return signCmd
+}
+
+func newConfigSubmitCmd() *cobra.Command {
+	submitCmd := &cobra.Command{
+		Use:     "submit",
+		Short:   "Sign and submit a config update with the signatures collected in the file",
+		Example: "channel config submit --cid mychannel --txfile config_update.pb --orgid Org2",
+		RunE: func(c *cobra.Command, args []string) error {
+			txFile, _ := c.Flags().GetString(cmd.TxFileFlag)
+			if txFile == "" {
+				return errors.New("the config update file is required")
+			}
+			channel, err := NewChannelAction(api.ConfigFlags(c.Flags()))
+			if err != nil {
+				return err
+			}
+			defer channel.Close()
+			txID, err := channel.SubmitConfigUpdate(txFile)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Success to update the config of channel [%s], TX [%s]\n", channel.ChannelID, txID)
+			return nil
+		},
+	}
+	cmd.InitTxFile(submitCmd.Flags(), "", "The path of the config update file")
+	return submitCmd
+}
+
+func newAddOrgCmd() *cobra.Command {
+	addOrgCmd := &cobra.Command{
+		Use:   "add-org",
+		Short: "Generate the config update adding an org to a channel from its MSP directory",
+		Long: "Generate the config update adding the org of the MSP directory to the Application group of the channel, " +
+			"with the standard Readers, Writers and Admins policies, signed by the user of the org of --orgid. " +
+			"The other orgs sign it with channel config sign before it is submitted with channel config submit",
+		Example: "channel add-org --cid mychannel --msp-dir ./crypto-config/peerOrganizations/org3.example.com/msp --mspid Org3MSP --orgid Org1 --out org3_update.pb",
+		RunE: func(c *cobra.Command, args []string) error {
+			mspDir, _ := c.Flags().GetString(cmd.MSPDirFlag)
+			mspID, _ := c.Flags().GetString(cmd.MSPIDFlag)
+			out, _ := c.Flags().GetString(cmd.OutputFileFlag)
+			if mspDir == "" || mspID == "" {
+				return errors.New("the MSP directory and the MSP ID are required")
+			}
+			channel, err := NewChannelAction(api.ConfigFlags(c.Flags()))
+			if err != nil {
+				return err
+			}
+			defer channel.Close()
+			signers, err := channel.AddOrg(mspDir, mspID, out)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Wrote the config update adding org [%s] to channel [%s] to %s, signed by %s\n",
+				mspID, channel.ChannelID, out, strings.Join(signers, ", "))
+			return nil
+		},
+	}
+	cmd.InitMSPDir(addOrgCmd.Flags())
+	cmd.InitMSPID(addOrgCmd.Flags())
+	cmd.InitOutputFile(addOrgCmd.Flags(), "config_update.pb")
+	return addOrgCmd
+}
+
+// parseTxFiles parses the ORG=FILE args
+func parseTxFiles(args []string) (map[string]string, error) {
+	txFiles := map[string]string{}
+	for _, arg := range args {
+		kv := strings.SplitN(arg, "=", 2)
+		if len(kv) != 2 || kv[0] == "" || kv[1] == "" {
+			return nil, errors.Errorf("invalid arg [%s], expecting ORG=FILE", arg)
+		}
+		if _, ok := txFiles[kv[0]]; ok {
+			return nil, errors.Errorf("duplicate org [%s]", kv[0])
 		}
+		txFiles[kv[0]] = kv[1]
 	}
+	return txFiles, nil
 }
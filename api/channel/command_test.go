@@ -0,0 +1,31 @@
+package channel
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseTxFiles(t *testing.T) {
+	txFiles, err := parseTxFiles([]string{"Org1=./Org1MSPanchors.tx", "Org2=a=b.tx"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := map[string]string{"Org1": "./Org1MSPanchors.tx", "Org2": "a=b.tx"}; !reflect.DeepEqual(txFiles, expected) {
+		t.Fatalf("expecting %v, got %v", expected, txFiles)
+	}
+
+	for _, tc := range []struct {
+		args []string
+		err  string
+	}{
+		{[]string{"Org1"}, "expecting ORG=FILE"},
+		{[]string{"=Org1MSPanchors.tx"}, "expecting ORG=FILE"},
+		{[]string{"Org1="}, "expecting ORG=FILE"},
+		{[]string{"Org1=a.tx", "Org1=b.tx"}, "duplicate org [Org1]"},
+	} {
+		if _, err := parseTxFiles(tc.args); err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Fatalf("args %v: expecting error [%s], got %v", tc.args, tc.err, err)
+		}
+	}
+}
@@ -0,0 +1,301 @@
+package channel
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/common"
+	mspProto "github.com/hyperledger/fabric-protos-go/msp"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/resmgmt"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/resource"
+	"github.com/hyperledger/fabric-sdk-go/pkg/util/protolator"
+	"github.com/pkg/errors"
+	"github.com/zhcppy/fabricli/logger"
+)
+
+// FetchConfig writes the channel config of the latest config block as JSON to the file, the same JSON as
+// configtxlator proto_decode, to be edited and turned into a config update. It returns the config block number
+func (c *Channel) FetchConfig(file string) (uint64, error) {
+	config, number, err := c.config()
+	if err != nil {
+		return 0, err
+	}
+	if err := writeConfig(file, config); err != nil {
+		return 0, err
+	}
+	return number, nil
+}
+
+// config returns the channel config of the latest config block and the block number
+func (c *Channel) config() (*common.Config, uint64, error) {
+	client, err := c.action.LedgerClient(c.ChannelID, c.user)
+	if err != nil {
+		return nil, 0, err
+	}
+	block, err := client.QueryConfigBlock()
+	if err != nil {
+		return nil, 0, errors.WithMessagef(err, "failed to query the config block of channel [%s]", c.ChannelID)
+	}
+	config, err := configFromBlock(block)
+	if err != nil {
+		return nil, 0, err
+	}
+	return config, block.Header.Number, nil
+}
+
+// ComputeUpdate computes the config update from the original JSON config to the edited one as configtxlator
+// compute_update does, and writes it to the tx file in an envelope which is signed and submitted as is
+func (c *Channel) ComputeUpdate(originalFile, updatedFile, txFile string) error {
+	return ComputeUpdate(c.ChannelID, originalFile, updatedFile, txFile)
+}
+
+// ComputeUpdate computes the config update of the channel from the JSON config files to the tx file, offline
+func ComputeUpdate(channelID, originalFile, updatedFile, txFile string) error {
+	original, err := readConfig(originalFile)
+	if err != nil {
+		return err
+	}
+	updated, err := readConfig(updatedFile)
+	if err != nil {
+		return err
+	}
+	envelope, err := computeUpdate(channelID, original, updated)
+	if err != nil {
+		return err
+	}
+	return writeUpdateTx(txFile, channelID, envelope)
+}
+
+// computeUpdate returns the unsigned config update envelope from the original config to the updated one
+func computeUpdate(channelID string, original, updated *common.Config) (*common.ConfigUpdateEnvelope, error) {
+	update, err := resmgmt.CalculateConfigUpdate(channelID, original, updated)
+	if err != nil {
+		return nil, err
+	}
+	data, err := proto.Marshal(update)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal the config update")
+	}
+	return &common.ConfigUpdateEnvelope{ConfigUpdate: data}, nil
+}
+
+// SignConfigUpdate adds the signature of the user to the config update of the tx file, replacing a previous
+// signature of the same user, and writes it to the out file, the tx file by default. The signatures are
+// collected offline by passing the file from org to org, as peer channel signconfigtx does.
+// It returns the MSP IDs of the signers
+func (c *Channel) SignConfigUpdate(txFile, outFile string) ([]string, error) {
+	channelID, envelope, err := readUpdateTx(txFile)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := c.signConfigUpdate(channelID, envelope)
+	if err != nil {
+		return nil, err
+	}
+	if err := addSignature(envelope, signature); err != nil {
+		return nil, err
+	}
+	if outFile == "" {
+		outFile = txFile
+	}
+	if err := writeUpdateTx(outFile, channelID, envelope); err != nil {
+		return nil, err
+	}
+	return signerMSPIDs(envelope)
+}
+
+// SubmitConfigUpdate signs the config update of the tx file too and submits it to the orderer
+// with the signatures collected in the file
+func (c *Channel) SubmitConfigUpdate(txFile string) (fab.TransactionID, error) {
+	channelID, envelope, err := readUpdateTx(txFile)
+	if err != nil {
+		return "", err
+	}
+	if channelID != c.ChannelID {
+		return "", errors.Errorf("the config update is for channel [%s], not [%s]", channelID, c.ChannelID)
+	}
+	signature, err := c.signConfigUpdate(channelID, envelope)
+	if err != nil {
+		return "", err
+	}
+	if err := addSignature(envelope, signature); err != nil {
+		return "", err
+	}
+	data, err := marshalUpdateTx(channelID, envelope)
+	if err != nil {
+		return "", err
+	}
+
+	logger.L().Infof("Submitting the config update of channel [%s] with %d signature(s)", c.ChannelID, len(envelope.Signatures))
+	req := resmgmt.SaveChannelRequest{ChannelID: c.ChannelID, ChannelConfig: bytes.NewReader(data)}
+	resp, err := c.client.SaveChannel(req, resmgmt.WithConfigSignatures(envelope.Signatures...), resmgmt.WithOrderer(c.orderer))
+	if err != nil {
+		return "", errors.WithMessagef(err, "failed to submit the config update of channel [%s]", c.ChannelID)
+	}
+	return resp.TransactionID, nil
+}
+
+func (c *Channel) signConfigUpdate(channelID string, envelope *common.ConfigUpdateEnvelope) (*common.ConfigSignature, error) {
+	data, err := marshalUpdateTx(channelID, envelope)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := c.client.CreateConfigSignatureFromReader(c.user, bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to sign the config update")
+	}
+	return signature, nil
+}
+
+// configFromBlock returns the channel config of the config block
+func configFromBlock(block *common.Block) (*common.Config, error) {
+	if block.GetData() == nil || len(block.Data.Data) == 0 {
+		return nil, errors.New("the config block has no transaction")
+	}
+	envelope, err := resource.CreateConfigEnvelope(block.Data.Data[0])
+	if err != nil {
+		return nil, err
+	}
+	if envelope.Config == nil {
+		return nil, errors.New("the config block has no channel config")
+	}
+	return envelope.Config, nil
+}
+
+func readConfig(file string) (*common.Config, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open the channel config")
+	}
+	defer f.Close()
+	config := &common.Config{}
+	if err := protolator.DeepUnmarshalJSON(f, config); err != nil {
+		return nil, errors.Wrapf(err, "invalid channel config %s", file)
+	}
+	return config, nil
+}
+
+func writeConfig(file string, config *common.Config) error {
+	var buf bytes.Buffer
+	if err := protolator.DeepMarshalJSON(&buf, config); err != nil {
+		return errors.Wrap(err, "failed to encode the channel config")
+	}
+	return errors.Wrap(ioutil.WriteFile(file, buf.Bytes(), 0644), "failed to write the channel config")
+}
+
+// marshalUpdateTx wraps the config update in the envelope of a CONFIG_UPDATE transaction,
+// the format of the config tx files of configtxgen and of peer channel update
+func marshalUpdateTx(channelID string, envelope *common.ConfigUpdateEnvelope) ([]byte, error) {
+	data, err := proto.Marshal(envelope)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal the config update envelope")
+	}
+	channelHeader, err := proto.Marshal(&common.ChannelHeader{Type: int32(common.HeaderType_CONFIG_UPDATE), ChannelId: channelID})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal the channel header")
+	}
+	payload, err := proto.Marshal(&common.Payload{Header: &common.Header{ChannelHeader: channelHeader}, Data: data})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal the payload")
+	}
+	return proto.Marshal(&common.Envelope{Payload: payload})
+}
+
+func writeUpdateTx(file, channelID string, envelope *common.ConfigUpdateEnvelope) error {
+	data, err := marshalUpdateTx(channelID, envelope)
+	if err != nil {
+		return err
+	}
+	return errors.Wrap(ioutil.WriteFile(file, data, 0644), "failed to write the config update")
+}
+
+// readUpdateTx returns the channel ID and the config update envelope of the config tx file
+func readUpdateTx(file string) (string, *common.ConfigUpdateEnvelope, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return "", nil, errors.Wrap(err, "failed to read the config update")
+	}
+	envelope, err := unmarshalUpdateTx(data)
+	if err != nil {
+		return "", nil, errors.WithMessagef(err, "invalid config update %s", file)
+	}
+	update := &common.ConfigUpdate{}
+	if err := proto.Unmarshal(envelope.ConfigUpdate, update); err != nil {
+		return "", nil, errors.Wrapf(err, "invalid config update %s", file)
+	}
+	return update.ChannelId, envelope, nil
+}
+
+func unmarshalUpdateTx(data []byte) (*common.ConfigUpdateEnvelope, error) {
+	envelope := &common.Envelope{}
+	if err := proto.Unmarshal(data, envelope); err != nil {
+		return nil, errors.Wrap(err, "unmarshal envelope failed")
+	}
+	payload := &common.Payload{}
+	if err := proto.Unmarshal(envelope.Payload, payload); err != nil {
+		return nil, errors.Wrap(err, "unmarshal payload failed")
+	}
+	if payload.Header == nil {
+		return nil, errors.New("no payload header")
+	}
+	channelHeader := &common.ChannelHeader{}
+	if err := proto.Unmarshal(payload.Header.ChannelHeader, channelHeader); err != nil {
+		return nil, errors.Wrap(err, "unmarshal channel header failed")
+	}
+	if common.HeaderType(channelHeader.Type) != common.HeaderType_CONFIG_UPDATE {
+		return nil, errors.Errorf("expecting a CONFIG_UPDATE transaction, got %s", common.HeaderType(channelHeader.Type))
+	}
+	updateEnvelope := &common.ConfigUpdateEnvelope{}
+	if err := proto.Unmarshal(payload.Data, updateEnvelope); err != nil {
+		return nil, errors.Wrap(err, "unmarshal config update envelope failed")
+	}
+	return updateEnvelope, nil
+}
+
+// addSignature adds the signature to the envelope, replacing the signature of the same creator
+func addSignature(envelope *common.ConfigUpdateEnvelope, signature *common.ConfigSignature) error {
+	creator, err := signatureCreator(signature)
+	if err != nil {
+		return err
+	}
+	for i, s := range envelope.Signatures {
+		c, err := signatureCreator(s)
+		if err != nil {
+			return err
+		}
+		if bytes.Equal(c, creator) {
+			envelope.Signatures[i] = signature
+			return nil
+		}
+	}
+	envelope.Signatures = append(envelope.Signatures, signature)
+	return nil
+}
+
+func signatureCreator(signature *common.ConfigSignature) ([]byte, error) {
+	header := &common.SignatureHeader{}
+	if err := proto.Unmarshal(signature.SignatureHeader, header); err != nil {
+		return nil, errors.Wrap(err, "invalid signature header")
+	}
+	return header.Creator, nil
+}
+
+// signerMSPIDs returns the MSP ID of the creator of each signature of the envelope
+func signerMSPIDs(envelope *common.ConfigUpdateEnvelope) ([]string, error) {
+	var mspIDs []string
+	for _, signature := range envelope.Signatures {
+		creator, err := signatureCreator(signature)
+		if err != nil {
+			return nil, err
+		}
+		identity := &mspProto.SerializedIdentity{}
+		if err := proto.Unmarshal(creator, identity); err != nil {
+			return nil, errors.Wrap(err, "invalid signature creator")
+		}
+		mspIDs = append(mspIDs, identity.Mspid)
+	}
+	return mspIDs, nil
+}
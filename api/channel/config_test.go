@@ -0,0 +1,128 @@
+package channel
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/common"
+	mspProto "github.com/hyperledger/fabric-protos-go/msp"
+	"github.com/hyperledger/fabric-protos-go/orderer"
+)
+
+func mustMarshal(t *testing.T, msg proto.Message) []byte {
+	bytes, err := proto.Marshal(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bytes
+}
+
+func newTestConfig(t *testing.T, maxMessageCount uint32) *common.Config {
+	return &common.Config{Sequence: 3, ChannelGroup: &common.ConfigGroup{
+		Version: 1,
+		Groups: map[string]*common.ConfigGroup{"Orderer": {
+			Version: 2,
+			Values: map[string]*common.ConfigValue{"BatchSize": {
+				Version:   4,
+				ModPolicy: "Admins",
+				Value:     mustMarshal(t, &orderer.BatchSize{MaxMessageCount: maxMessageCount}),
+			}},
+		}},
+	}}
+}
+
+func newTestSignature(t *testing.T, mspID string, signature string) *common.ConfigSignature {
+	creator := mustMarshal(t, &mspProto.SerializedIdentity{Mspid: mspID, IdBytes: []byte(mspID + "-cert")})
+	return &common.ConfigSignature{
+		SignatureHeader: mustMarshal(t, &common.SignatureHeader{Creator: creator, Nonce: []byte(signature)}),
+		Signature:       []byte(signature),
+	}
+}
+
+func TestComputeUpdate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	original, updated, txFile := filepath.Join(dir, "config.json"), filepath.Join(dir, "modified_config.json"), filepath.Join(dir, "update.pb")
+	if err := writeConfig(original, newTestConfig(t, 10)); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeConfig(updated, newTestConfig(t, 20)); err != nil {
+		t.Fatal(err)
+	}
+	if config, err := readConfig(original); err != nil || !proto.Equal(config, newTestConfig(t, 10)) {
+		t.Fatalf("expecting the config to round trip through JSON, got %v, %v", config, err)
+	}
+
+	if err := ComputeUpdate("mychannel", original, updated, txFile); err != nil {
+		t.Fatal(err)
+	}
+	channelID, envelope, err := readUpdateTx(txFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if channelID != "mychannel" || len(envelope.Signatures) != 0 {
+		t.Fatalf("unexpected config update of channel [%s] with %d signature(s)", channelID, len(envelope.Signatures))
+	}
+	update := &common.ConfigUpdate{}
+	if err := proto.Unmarshal(envelope.ConfigUpdate, update); err != nil {
+		t.Fatal(err)
+	}
+	// the read set holds the versions of the modified groups, the write set the new version of the value
+	if version := update.ReadSet.Groups["Orderer"].Version; version != 2 {
+		t.Fatalf("expecting the Orderer group version 2 in the read set, got %d", version)
+	}
+	value := update.WriteSet.Groups["Orderer"].Values["BatchSize"]
+	batchSize := &orderer.BatchSize{}
+	if err := proto.Unmarshal(value.Value, batchSize); err != nil {
+		t.Fatal(err)
+	}
+	if value.Version != 5 || value.ModPolicy != "Admins" || batchSize.MaxMessageCount != 20 {
+		t.Fatalf("unexpected BatchSize in the write set %+v, %+v", value, batchSize)
+	}
+
+	if err := ComputeUpdate("mychannel", original, original, txFile); err == nil {
+		t.Fatal("expecting an error for a config without changes")
+	}
+	if _, _, err := readUpdateTx(original); err == nil {
+		t.Fatal("expecting an error for a file which is not a config update")
+	}
+}
+
+func TestAddSignature(t *testing.T) {
+	envelope := &common.ConfigUpdateEnvelope{}
+	for _, signature := range []*common.ConfigSignature{
+		newTestSignature(t, "Org1MSP", "sig1"),
+		newTestSignature(t, "Org2MSP", "sig2"),
+		newTestSignature(t, "Org1MSP", "sig3"),
+	} {
+		if err := addSignature(envelope, signature); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the second signature of Org1 replaces the first one
+	if len(envelope.Signatures) != 2 || string(envelope.Signatures[0].Signature) != "sig3" {
+		t.Fatalf("unexpected signatures %v", envelope.Signatures)
+	}
+	mspIDs, err := signerMSPIDs(envelope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"Org1MSP", "Org2MSP"}; !reflect.DeepEqual(mspIDs, expected) {
+		t.Fatalf("expecting signers %v, got %v", expected, mspIDs)
+	}
+
+	data, err := marshalUpdateTx("mychannel", envelope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if unmarshalled, err := unmarshalUpdateTx(data); err != nil || !proto.Equal(unmarshalled, envelope) {
+		t.Fatalf("expecting the signatures to round trip, got %v, %v", unmarshalled, err)
+	}
+}
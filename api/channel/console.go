@@ -0,0 +1,66 @@
+package channel
+
+import (
+	"reflect"
+
+	"github.com/zhcppy/fabricli/api"
+	"github.com/zhcppy/fabricli/console"
+)
+
+// Namespace returns the ch namespace of the console, calling the methods of the channel action
+func Namespace(c *api.Config) console.Namespace {
+	return console.Namespace{
+		Name: "ch",
+		Type: reflect.TypeOf(&Channel{}),
+		New: func() (interface{}, error) {
+			return NewChannelAction(c)
+		},
+		Docs: docs,
+	}
+}
+
+var docs = map[string]console.Doc{
+	"Create": {
+		Params:      []string{"txFile", "orgIDs"},
+		Description: "Creates or updates the channel of the config from the channel tx file, signed by the user of each org",
+		Example:     "ch.Create(./channel-artifacts/channel.tx, Org1, Org2)",
+	},
+	"UpdateAnchors": {
+		Params:      []string{"txFiles"},
+		Description: "Submits the anchor peer update tx file of each org, signed and submitted by the user of the org",
+		Example:     `ch.UpdateAnchors({"Org1":"./channel-artifacts/Org1MSPanchors.tx"})`,
+	},
+	"FetchConfig": {
+		Params:      []string{"file"},
+		Description: "Writes the config of the latest config block of the channel as editable JSON to the file, returns the block number",
+		Example:     "ch.FetchConfig(config.json)",
+	},
+	"ComputeUpdate": {
+		Params:      []string{"originalFile", "updatedFile", "txFile"},
+		Description: "Computes the config update from the original JSON config to the edited one and writes it to the tx file",
+		Example:     "ch.ComputeUpdate(config.json, modified_config.json, config_update.pb)",
+	},
+	"SignConfigUpdate": {
+		Params:      []string{"txFile", "outFile"},
+		Description: "Adds the signature of the user to the config update of the tx file, written to the out file or in place when empty. Returns the MSP IDs of the signers",
+		Example:     `ch.SignConfigUpdate(config_update.pb, "")`,
+	},
+	"SubmitConfigUpdate": {
+		Params:      []string{"txFile"},
+		Description: "Signs the config update of the tx file and submits it with the signatures collected in the file",
+		Example:     "ch.SubmitConfigUpdate(config_update.pb)",
+	},
+	"AddOrg": {
+		Params:      []string{"mspDir", "mspID", "txFile"},
+		Description: "Writes the config update adding the org of the MSP directory to the channel to the tx file, signed by the user. Returns the MSP IDs of the signers",
+		Example:     "ch.AddOrg(./crypto-config/peerOrganizations/org3.example.com/msp, Org3MSP, org3_update.pb)",
+	},
+	"Join": {
+		Description: "Joins the peers of the org of the config to the channel of the config",
+		Example:     "ch.Join()",
+	},
+}
+
+func (c *Channel) Close() {
+	c.action.Close()
+}
@@ -0,0 +1,267 @@
+package channel
+
+import (
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/common"
+	mspProto "github.com/hyperledger/fabric-protos-go/msp"
+	"github.com/pkg/errors"
+	"github.com/zhcppy/fabricli/api/chaincode/cauthdsl"
+	"github.com/zhcppy/fabricli/logger"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	applicationGroup = "Application"
+	adminsPolicy     = "Admins"
+)
+
+// AddOrg writes the config update adding the org of the MSP directory to the Application group of the channel
+// to the tx file, signed by the user. The other orgs sign it with SignConfigUpdate before it is submitted.
+// It returns the MSP IDs of the signers
+func (c *Channel) AddOrg(mspDir, mspID, txFile string) ([]string, error) {
+	org, err := NewOrgGroup(mspDir, mspID)
+	if err != nil {
+		return nil, err
+	}
+	original, _, err := c.config()
+	if err != nil {
+		return nil, err
+	}
+	updated, err := addOrg(original, mspID, org)
+	if err != nil {
+		return nil, err
+	}
+	envelope, err := computeUpdate(c.ChannelID, original, updated)
+	if err != nil {
+		return nil, err
+	}
+	logger.L().Infof("Signing the config update adding org [%s] to channel [%s]", mspID, c.ChannelID)
+	signature, err := c.signConfigUpdate(c.ChannelID, envelope)
+	if err != nil {
+		return nil, err
+	}
+	if err := addSignature(envelope, signature); err != nil {
+		return nil, err
+	}
+	if err := writeUpdateTx(txFile, c.ChannelID, envelope); err != nil {
+		return nil, err
+	}
+	return signerMSPIDs(envelope)
+}
+
+// addOrg returns a copy of the config with the org group added to the Application group
+func addOrg(config *common.Config, mspID string, org *common.ConfigGroup) (*common.Config, error) {
+	updated := proto.Clone(config).(*common.Config)
+	application := updated.GetChannelGroup().GetGroups()[applicationGroup]
+	if application == nil {
+		return nil, errors.New("the channel config has no Application group")
+	}
+	if _, ok := application.Groups[mspID]; ok {
+		return nil, errors.Errorf("org [%s] is already a member of the channel", mspID)
+	}
+	if application.Groups == nil {
+		application.Groups = map[string]*common.ConfigGroup{}
+	}
+	application.Groups[mspID] = org
+	return updated, nil
+}
+
+// NewOrgGroup returns the config group of the org of the MSP directory with the standard policies, as
+// configtxgen -printOrg does: Readers and Writers are the admins, peers and clients of the org when
+// config.yaml enables the node OUs, the members of the org otherwise, Admins are the admins of the org
+func NewOrgGroup(mspDir, mspID string) (*common.ConfigGroup, error) {
+	if mspID == "" {
+		return nil, errors.New("the MSP ID is required")
+	}
+	fabricMSPConfig, err := newMSPConfig(mspDir, mspID)
+	if err != nil {
+		return nil, err
+	}
+	config, err := proto.Marshal(fabricMSPConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal the MSP config")
+	}
+	mspConfig, err := proto.Marshal(&mspProto.MSPConfig{Config: config})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal the MSP config")
+	}
+
+	rules := map[string]string{
+		"Readers":    fmt.Sprintf("OR('%s.member')", mspID),
+		"Writers":    fmt.Sprintf("OR('%s.member')", mspID),
+		adminsPolicy: fmt.Sprintf("OR('%s.admin')", mspID),
+	}
+	if fabricMSPConfig.FabricNodeOus.GetEnable() {
+		rules["Readers"] = fmt.Sprintf("OR('%[1]s.admin', '%[1]s.peer', '%[1]s.client')", mspID)
+		rules["Writers"] = fmt.Sprintf("OR('%[1]s.admin', '%[1]s.client')", mspID)
+	}
+	policies := map[string]*common.ConfigPolicy{}
+	for name, rule := range rules {
+		envelope, err := cauthdsl.FromString(rule)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "invalid policy [%s] %s", name, rule)
+		}
+		value, err := proto.Marshal(envelope)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to marshal the policy [%s]", name)
+		}
+		policies[name] = &common.ConfigPolicy{
+			ModPolicy: adminsPolicy,
+			Policy:    &common.Policy{Type: int32(common.Policy_SIGNATURE), Value: value},
+		}
+	}
+
+	return &common.ConfigGroup{
+		ModPolicy: adminsPolicy,
+		Values:    map[string]*common.ConfigValue{"MSP": {ModPolicy: adminsPolicy, Value: mspConfig}},
+		Policies:  policies,
+		Groups:    map[string]*common.ConfigGroup{},
+	}, nil
+}
+
+// mspConfigYAML is the config.yaml of an MSP directory
+type mspConfigYAML struct {
+	OrganizationalUnitIdentifiers []ouIdentifierYAML `yaml:"OrganizationalUnitIdentifiers"`
+	NodeOUs                       *struct {
+		Enable              bool              `yaml:"Enable"`
+		ClientOUIdentifier  *ouIdentifierYAML `yaml:"ClientOUIdentifier"`
+		PeerOUIdentifier    *ouIdentifierYAML `yaml:"PeerOUIdentifier"`
+		AdminOUIdentifier   *ouIdentifierYAML `yaml:"AdminOUIdentifier"`
+		OrdererOUIdentifier *ouIdentifierYAML `yaml:"OrdererOUIdentifier"`
+	} `yaml:"NodeOUs"`
+}
+
+type ouIdentifierYAML struct {
+	Certificate                  string `yaml:"Certificate"`
+	OrganizationalUnitIdentifier string `yaml:"OrganizationalUnitIdentifier"`
+}
+
+// newMSPConfig reads the verifying MSP config of the MSP directory: the certificates of cacerts,
+// intermediatecerts, admincerts, tlscacerts, tlsintermediatecerts, the CRLs of crls and the OUs of config.yaml.
+// The signing identity is left out, the channel config only verifies the identities of the org
+func newMSPConfig(dir, mspID string) (*mspProto.FabricMSPConfig, error) {
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return nil, errors.Errorf("invalid MSP directory %s", dir)
+	}
+	rootCerts, err := readPEMs(filepath.Join(dir, "cacerts"))
+	if err != nil {
+		return nil, err
+	}
+	if len(rootCerts) == 0 {
+		return nil, errors.Errorf("no CA certificate in %s", filepath.Join(dir, "cacerts"))
+	}
+	config := &mspProto.FabricMSPConfig{
+		Name:      mspID,
+		RootCerts: rootCerts,
+		CryptoConfig: &mspProto.FabricCryptoConfig{
+			SignatureHashFamily:            "SHA2",
+			IdentityIdentifierHashFunction: "SHA256",
+		},
+	}
+	for folder, certs := range map[string]*[][]byte{
+		"intermediatecerts":    &config.IntermediateCerts,
+		"admincerts":           &config.Admins,
+		"tlscacerts":           &config.TlsRootCerts,
+		"tlsintermediatecerts": &config.TlsIntermediateCerts,
+		"crls":                 &config.RevocationList,
+	} {
+		if *certs, err = readPEMs(filepath.Join(dir, folder)); err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "config.yaml"))
+	if os.IsNotExist(err) {
+		return config, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed to read the MSP config.yaml")
+	}
+	configYAML := &mspConfigYAML{}
+	if err := yaml.Unmarshal(data, configYAML); err != nil {
+		return nil, errors.Wrap(err, "invalid MSP config.yaml")
+	}
+	for _, ou := range configYAML.OrganizationalUnitIdentifiers {
+		identifier, err := ou.identifier(dir)
+		if err != nil {
+			return nil, err
+		}
+		config.OrganizationalUnitIdentifiers = append(config.OrganizationalUnitIdentifiers, identifier)
+	}
+	if nodeOUs := configYAML.NodeOUs; nodeOUs != nil {
+		config.FabricNodeOus = &mspProto.FabricNodeOUs{Enable: nodeOUs.Enable}
+		for _, node := range []struct {
+			ou         *ouIdentifierYAML
+			identifier **mspProto.FabricOUIdentifier
+		}{
+			{nodeOUs.ClientOUIdentifier, &config.FabricNodeOus.ClientOuIdentifier},
+			{nodeOUs.PeerOUIdentifier, &config.FabricNodeOus.PeerOuIdentifier},
+			{nodeOUs.AdminOUIdentifier, &config.FabricNodeOus.AdminOuIdentifier},
+			{nodeOUs.OrdererOUIdentifier, &config.FabricNodeOus.OrdererOuIdentifier},
+		} {
+			if node.ou == nil {
+				continue
+			}
+			if *node.identifier, err = node.ou.identifier(dir); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return config, nil
+}
+
+// identifier reads the certificate of the OU identifier, relative to the MSP directory
+func (ou ouIdentifierYAML) identifier(dir string) (*mspProto.FabricOUIdentifier, error) {
+	identifier := &mspProto.FabricOUIdentifier{OrganizationalUnitIdentifier: ou.OrganizationalUnitIdentifier}
+	if ou.Certificate == "" {
+		return identifier, nil
+	}
+	file := ou.Certificate
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(dir, file)
+	}
+	cert, err := readPEM(file)
+	if err != nil {
+		return nil, err
+	}
+	identifier.Certificate = cert
+	return identifier, nil
+}
+
+// readPEMs reads the PEM files of the directory, sorted by name, a missing directory has none
+func readPEMs(dir string) ([][]byte, error) {
+	files, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrapf(err, "failed to read %s", dir)
+	}
+	var pems [][]byte
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+		data, err := readPEM(filepath.Join(dir, file.Name()))
+		if err != nil {
+			return nil, err
+		}
+		pems = append(pems, data)
+	}
+	return pems, nil
+}
+
+func readPEM(file string) ([]byte, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read %s", file)
+	}
+	if block, _ := pem.Decode(data); block == nil {
+		return nil, errors.Errorf("no PEM data in %s", file)
+	}
+	return data, nil
+}
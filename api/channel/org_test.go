@@ -0,0 +1,165 @@
+package channel
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/common"
+	mspProto "github.com/hyperledger/fabric-protos-go/msp"
+)
+
+func newTestCert(t *testing.T, cn string) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func writeTestFile(t *testing.T, file string, data []byte) {
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(file, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+const testMSPConfigYAML = `
+NodeOUs:
+  Enable: true
+  ClientOUIdentifier:
+    Certificate: cacerts/ca.org3.example.com-cert.pem
+    OrganizationalUnitIdentifier: client
+  PeerOUIdentifier:
+    Certificate: cacerts/ca.org3.example.com-cert.pem
+    OrganizationalUnitIdentifier: peer
+`
+
+func TestNewOrgGroup(t *testing.T) {
+	dir, err := ioutil.TempDir("", "msp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	ca, tlsCA, admin := newTestCert(t, "ca.org3.example.com"), newTestCert(t, "tlsca.org3.example.com"), newTestCert(t, "Admin@org3.example.com")
+	writeTestFile(t, filepath.Join(dir, "cacerts", "ca.org3.example.com-cert.pem"), ca)
+	writeTestFile(t, filepath.Join(dir, "tlscacerts", "tlsca.org3.example.com-cert.pem"), tlsCA)
+	writeTestFile(t, filepath.Join(dir, "admincerts", "Admin@org3.example.com-cert.pem"), admin)
+
+	// without config.yaml the members of the org read and write
+	group, err := NewOrgGroup(dir, "Org3MSP")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if policy := testSignaturePolicy(t, group, "Writers"); len(policy.Identities) != 1 {
+		t.Fatalf("expecting the Writers policy of the members, got %v", policy)
+	}
+
+	writeTestFile(t, filepath.Join(dir, "config.yaml"), []byte(testMSPConfigYAML))
+	group, err = NewOrgGroup(dir, "Org3MSP")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mspConfig := &mspProto.MSPConfig{}
+	if err := proto.Unmarshal(group.Values["MSP"].Value, mspConfig); err != nil {
+		t.Fatal(err)
+	}
+	config := &mspProto.FabricMSPConfig{}
+	if err := proto.Unmarshal(mspConfig.Config, config); err != nil {
+		t.Fatal(err)
+	}
+	if config.Name != "Org3MSP" || len(config.RootCerts) != 1 || string(config.RootCerts[0]) != string(ca) {
+		t.Fatalf("unexpected MSP config %+v", config)
+	}
+	if len(config.TlsRootCerts) != 1 || len(config.Admins) != 1 || string(config.Admins[0]) != string(admin) {
+		t.Fatalf("unexpected MSP config %+v", config)
+	}
+	if ous := config.FabricNodeOus; !ous.GetEnable() || ous.PeerOuIdentifier.OrganizationalUnitIdentifier != "peer" || string(ous.ClientOuIdentifier.Certificate) != string(ca) {
+		t.Fatalf("unexpected node OUs %+v", ous)
+	}
+	// with the node OUs the admins, peers and clients read, the admins and clients write
+	for name, identities := range map[string]int{"Readers": 3, "Writers": 2, "Admins": 1} {
+		if policy := testSignaturePolicy(t, group, name); len(policy.Identities) != identities {
+			t.Fatalf("expecting %d identities in policy [%s], got %v", identities, name, policy)
+		}
+	}
+
+	if _, err := NewOrgGroup(filepath.Join(dir, "tlscacerts"), "Org3MSP"); err == nil {
+		t.Fatal("expecting an error for an MSP directory without cacerts")
+	}
+	writeTestFile(t, filepath.Join(dir, "admincerts", "README"), []byte("not a certificate"))
+	if _, err := NewOrgGroup(dir, "Org3MSP"); err == nil {
+		t.Fatal("expecting an error for a file which is not PEM")
+	}
+}
+
+func testSignaturePolicy(t *testing.T, group *common.ConfigGroup, name string) *common.SignaturePolicyEnvelope {
+	policy, ok := group.Policies[name]
+	if !ok || policy.ModPolicy != "Admins" || policy.Policy.Type != int32(common.Policy_SIGNATURE) {
+		t.Fatalf("unexpected policy [%s] %v", name, policy)
+	}
+	envelope := &common.SignaturePolicyEnvelope{}
+	if err := proto.Unmarshal(policy.Policy.Value, envelope); err != nil {
+		t.Fatal(err)
+	}
+	return envelope
+}
+
+func TestAddOrg(t *testing.T) {
+	config := newTestConfig(t, 10)
+	config.ChannelGroup.Groups["Application"] = &common.ConfigGroup{
+		Version:   1,
+		ModPolicy: "Admins",
+		Groups:    map[string]*common.ConfigGroup{"Org1MSP": {Version: 2}},
+	}
+	org := &common.ConfigGroup{ModPolicy: "Admins", Values: map[string]*common.ConfigValue{"MSP": {ModPolicy: "Admins", Value: []byte("msp")}}}
+
+	updated, err := addOrg(config, "Org3MSP", org)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := config.ChannelGroup.Groups["Application"].Groups["Org3MSP"]; ok {
+		t.Fatal("expecting the original config to be left unchanged")
+	}
+	envelope, err := computeUpdate("mychannel", config, updated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	update := &common.ConfigUpdate{}
+	if err := proto.Unmarshal(envelope.ConfigUpdate, update); err != nil {
+		t.Fatal(err)
+	}
+	application := update.WriteSet.Groups["Application"]
+	if application.Version != 2 || application.Groups["Org3MSP"] == nil || application.Groups["Org1MSP"].Version != 2 {
+		t.Fatalf("unexpected Application group in the write set %v", application)
+	}
+
+	if _, err := addOrg(config, "Org1MSP", org); err == nil {
+		t.Fatal("expecting an error for an org already in the channel")
+	}
+	if _, err := addOrg(newTestConfig(t, 10), "Org3MSP", org); err == nil {
+		t.Fatal("expecting an error for a config without Application group")
+	}
+}
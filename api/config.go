@@ -8,6 +8,7 @@ package api
 import (
 	"fmt"
 	"os"
+	"strings"
 	"sync"
 
 	"github.com/fsnotify/fsnotify"
@@ -30,16 +31,26 @@ const (
 	PeerUrlTag              = "PeerUrl"
 	OrdererUrlTag           = "OrdererUrl"
 	SelectionProviderTag    = "SelectionProvider"
-	CollectionConfigFileTag = "CollectionConfigFile"
-	ChaincodeArgsTag        = "ChaincodeArgs"
-	ChaincodeIDTag          = "ChaincodeId"
-	ChaincodePathTag        = "ChaincodePath"
-	ChaincodeVersionTag     = "ChaincodeVersion"
-	ChaincodeEventTag       = "ChaincodeEvent"
-	ChaincodePolicyTag      = "ChaincodePolicy"
-	GoPathTag               = "GoPath"
+	CCodeInfoTag            = "CCodeInfo"
+	CollectionConfigFileTag = CCodeInfoTag + ".CollectionConfigFile"
+	ChaincodeArgsTag        = CCodeInfoTag + ".ChaincodeArgs"
+	ChaincodeIDTag          = CCodeInfoTag + ".ChaincodeId"
+	ChaincodePathTag        = CCodeInfoTag + ".ChaincodePath"
+	ChaincodeVersionTag     = CCodeInfoTag + ".ChaincodeVersion"
+	ChaincodeEventTag       = CCodeInfoTag + ".ChaincodeEvent"
+	ChaincodePolicyTag      = CCodeInfoTag + ".ChaincodePolicy"
+	GoPathTag               = CCodeInfoTag + ".GoPath"
+	ChaincodeLangTag        = CCodeInfoTag + ".ChaincodeLang"
 )
 
+// flagTags maps a command-line flag name to the config tag it overrides
+var flagTags = map[string]string{}
+
+// RegisterFlag registers the flag as an override of the given config tag
+func RegisterFlag(flagName, tag string) {
+	flagTags[flagName] = tag
+}
+
 func init() {
 	_ = os.Setenv(ProjectHome, "github.com/zhcppy/fabricli")
 	viper.SetConfigName("fabricli")
@@ -64,6 +75,7 @@ var defConfig = &Config{
 	SelectionProvider: "auto",
 	CCodeInfo: CCodeInfo{
 		ChaincodeVersion: "v0",
+		ChaincodeLang:    "golang",
 	},
 }
 var once = &sync.Once{}
@@ -88,6 +100,7 @@ type CCodeInfo struct {
 	ChaincodeEvent       string `json:"ChaincodeEvent"`
 	ChaincodePolicy      string `json:"ChaincodePolicy"`
 	GoPath               string `json:"GoPath"`
+	ChaincodeLang        string `json:"ChaincodeLang"`
 }
 
 func (c *Config) check() *Config {
@@ -107,6 +120,41 @@ func (c *Config) check() *Config {
 	panic(err.Error())
 }
 
+// OrgIDs returns the organization IDs, OrgID may be a comma-separated list
+func (c *Config) OrgIDs() []string {
+	return splitList(c.OrgID)
+}
+
+// PeerURLs returns the peer URLs, PeerUrl may be a comma-separated list
+func (c *Config) PeerURLs() []string {
+	return splitList(c.PeerUrl)
+}
+
+// DefaultOrgID returns the first organization ID, it is used to load the user
+func (c *Config) DefaultOrgID() string {
+	if orgIDs := c.OrgIDs(); len(orgIDs) > 0 {
+		return orgIDs[0]
+	}
+	return ""
+}
+
+// DefaultPeerURL returns the first peer URL, it is used to load the user
+func (c *Config) DefaultPeerURL() string {
+	if peerURLs := c.PeerURLs(); len(peerURLs) > 0 {
+		return peerURLs[0]
+	}
+	return ""
+}
+
+func splitList(list string) (items []string) {
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 func (c *Config) onchange(e fsnotify.Event) {
 	fmt.Println("Config file changed:", e.String())
 }
@@ -119,6 +167,10 @@ func GetConfig() *Config {
 func ConfigFlags(flags *pflag.FlagSet) *Config {
 	once.Do(func() {
 		flags.Visit(func(flag *pflag.Flag) {
+			if tag, ok := flagTags[flag.Name]; ok {
+				viper.BindPFlag(tag, flag)
+				return
+			}
 			viper.BindPFlag(flag.Name, flag)
 		})
 		viperCfg()
@@ -16,3 +16,16 @@ func TestGetConfig(t *testing.T) {
 	GetConfig()
 	fmt.Println(filepath.SplitList(build.Default.GOPATH))
 }
+
+func TestConfig_OrgIDs(t *testing.T) {
+	c := &Config{OrgID: "Org1, org2,,", PeerUrl: "localhost:7051"}
+	if ids := c.OrgIDs(); len(ids) != 2 || ids[0] != "Org1" || ids[1] != "org2" {
+		t.Fatal("unexpected org IDs", ids)
+	}
+	if c.DefaultOrgID() != "Org1" || c.DefaultPeerURL() != "localhost:7051" {
+		t.Fatal("unexpected defaults", c.DefaultOrgID(), c.DefaultPeerURL())
+	}
+	if urls := (&Config{}).PeerURLs(); len(urls) != 0 {
+		t.Fatal("unexpected peer URLs", urls)
+	}
+}
@@ -0,0 +1,45 @@
+package event
+
+import (
+	"reflect"
+
+	"github.com/zhcppy/fabricli/api"
+	"github.com/zhcppy/fabricli/console"
+)
+
+// Namespace returns the ev namespace of the console, calling the methods of the event action
+func Namespace(c *api.Config) console.Namespace {
+	return console.Namespace{
+		Name: "ev",
+		Type: reflect.TypeOf(&Event{}),
+		New: func() (interface{}, error) {
+			return NewEventAction(c)
+		},
+		Docs: docs,
+	}
+}
+
+var docs = map[string]console.Doc{
+	"ListenBlock": {
+		Description: "Prints the number of the blocks committed until <enter> is pressed",
+		Example:     "ev.ListenBlock()",
+	},
+	"ListenFilteredBlock": {
+		Description: "Prints the filtered blocks committed until <enter> is pressed",
+		Example:     "ev.ListenFilteredBlock()",
+	},
+	"ListenTx": {
+		Params:      []string{"txID"},
+		Description: "Waits for the status of the transaction until <enter> is pressed",
+		Example:     "ev.ListenTx(<txID>)",
+	},
+	"ListenChaincode": {
+		Params:      []string{"chaincodeID", "eventFilter"},
+		Description: "Prints the events of the chaincode matching the filter, a regular expression, until <enter> is pressed",
+		Example:     `ev.ListenChaincode(mycc, ".*")`,
+	},
+}
+
+func (e *Event) Close() {
+	e.action.Close()
+}
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/zhcppy/fabricli/logger"
+	"gopkg.in/yaml.v2"
+)
+
+// Profile holds the names of the connection profile of the SDK config file
+type Profile struct {
+	ChannelIDs []string
+	// ChaincodeIDs are the chaincodes listed by the channels, without their version
+	ChaincodeIDs []string
+	PeerURLs     []string
+	OrgIDs       []string
+}
+
+// LoadProfile reads the channels, chaincodes, peers and organizations of the connection profile
+func LoadProfile(file string) (*Profile, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read config file [%s]", file)
+	}
+	return parseProfile(data)
+}
+
+func parseProfile(data []byte) (*Profile, error) {
+	var config struct {
+		Channels map[string]struct {
+			Chaincodes []string `yaml:"chaincodes"`
+		} `yaml:"channels"`
+		Organizations map[string]interface{} `yaml:"organizations"`
+		Peers         map[string]struct {
+			URL string `yaml:"url"`
+		} `yaml:"peers"`
+	}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, errors.Wrap(err, "failed to parse connection profile")
+	}
+
+	profile := &Profile{}
+	chaincodeIDs := map[string]bool{}
+	for channelID, channel := range config.Channels {
+		profile.ChannelIDs = append(profile.ChannelIDs, channelID)
+		for _, chaincode := range channel.Chaincodes {
+			chaincodeIDs[strings.SplitN(chaincode, ":", 2)[0]] = true
+		}
+	}
+	for chaincodeID := range chaincodeIDs {
+		profile.ChaincodeIDs = append(profile.ChaincodeIDs, chaincodeID)
+	}
+	for orgID := range config.Organizations {
+		profile.OrgIDs = append(profile.OrgIDs, orgID)
+	}
+	for _, peer := range config.Peers {
+		if peer.URL != "" {
+			profile.PeerURLs = append(profile.PeerURLs, peer.URL)
+		}
+	}
+	for _, names := range [][]string{profile.ChannelIDs, profile.ChaincodeIDs, profile.OrgIDs, profile.PeerURLs} {
+		sort.Strings(names)
+	}
+	return profile, nil
+}
+
+// CompletionValues returns the values completed in the console for the parameters named
+// channelID, chaincodeID, peerURL and orgID, from the connection profile and the config.
+// The plural names such as peerURLs complete the elements of their string slice
+func (c *Config) CompletionValues() map[string][]string {
+	profile, err := LoadProfile(c.ConfigFile)
+	if err != nil {
+		logger.L().Debugf("no completion from the connection profile: %s", err)
+		profile = &Profile{}
+	}
+	return map[string][]string{
+		"channelID":   appendMissing(profile.ChannelIDs, c.ChannelID),
+		"chaincodeID": appendMissing(profile.ChaincodeIDs, c.CCodeInfo.ChaincodeID),
+		"peerURL":     profile.PeerURLs,
+		"orgID":       profile.OrgIDs,
+	}
+}
+
+func appendMissing(names []string, name string) []string {
+	if name == "" {
+		return names
+	}
+	for _, n := range names {
+		if n == name {
+			return names
+		}
+	}
+	return append(names, name)
+}
@@ -0,0 +1,41 @@
+package api
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseProfile(t *testing.T) {
+	profile, err := parseProfile([]byte(`
+channels:
+  mychannel:
+    chaincodes:
+      - mycc:v0
+      - marbles:v1
+  otherchannel:
+    chaincodes:
+      - mycc:v1
+organizations:
+  Org2:
+    mspid: Org2MSP
+  Org1:
+    mspid: Org1MSP
+peers:
+  peer0.org1.example.com:
+    url: grpc://localhost:7051
+  peer0.org2.example.com:
+    url: grpc://localhost:9051
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &Profile{
+		ChannelIDs:   []string{"mychannel", "otherchannel"},
+		ChaincodeIDs: []string{"marbles", "mycc"},
+		PeerURLs:     []string{"grpc://localhost:7051", "grpc://localhost:9051"},
+		OrgIDs:       []string{"Org1", "Org2"},
+	}
+	if !reflect.DeepEqual(profile, expected) {
+		t.Fatalf("expecting profile %+v, got %+v", expected, profile)
+	}
+}
@@ -14,7 +14,6 @@ import (
 	"encoding/hex"
 	"fmt"
 	"math/big"
-	"strconv"
 	"strings"
 	"time"
 
@@ -50,6 +49,7 @@ func NewQueryAction(c *api.Config) (*Query, error) {
 
 	ledgerClient, err := action.LedgerClient(c.ChannelID, user)
 	if err != nil {
+		return nil, err
 	}
 	return &Query{
 		ChannelID: c.ChannelID,
@@ -90,12 +90,8 @@ func (q *Query) QueryBlockByTxID(txID string) (*common.Block, error) {
 	return block, err
 }
 
-func (q *Query) QueryBlock(blockNumber string) (*common.Block, error) {
-	number, err := strconv.ParseUint(blockNumber, 10, 64)
-	if err != nil {
-		return nil, err
-	}
-	block, err := q.Client.QueryBlock(number)
+func (q *Query) QueryBlock(blockNumber uint64) (*common.Block, error) {
+	block, err := q.Client.QueryBlock(blockNumber)
 	if err != nil {
 		return nil, err
 	}
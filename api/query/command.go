@@ -16,7 +16,7 @@ func NewCmd() *cobra.Command {
 		ValidArgs: []string{"info"},
 		Run: func(cmd *cobra.Command, args []string) {
 			config := api.ConfigFlags(cmd.Flags())
-			c, err := console.New(consoler{Config: config}, console.WithPrompt("> QueryAction."))
+			c, err := console.New(console.Namespaces{Namespace(config)}.WithValues(config.CompletionValues()), console.WithPrompt("> QueryAction."))
 			if err != nil {
 				fmt.Println("console error:", err.Error())
 				return
@@ -6,57 +6,83 @@
 package query
 
 import (
-	"fmt"
 	"reflect"
-	"strings"
 
 	"github.com/zhcppy/fabricli/api"
-
-	"github.com/zhcppy/fabricli/jsonp"
-
-	"github.com/hyperledger/fabric-sdk-go/pkg/client/ledger"
-	"github.com/pkg/errors"
 	"github.com/zhcppy/fabricli/console"
 )
 
-type consoler struct {
-	*api.Config
-}
-
-func (c consoler) NewHandler() (handler console.Handler, err error) {
-	if c.Config == nil {
-		return nil, errors.New("please init config")
-	}
-	return NewQueryAction(c.Config)
-}
-
-func (c consoler) WordCompleter() (word []string) {
-	clientType := reflect.TypeOf(&ledger.Client{})
-	for i := 0; i < clientType.NumMethod(); i++ {
-		if clientType.Method(i).Type.Kind() != reflect.Func {
-			continue
-		}
-		word = append(word, clientType.Method(i).Name+"()")
+// Namespace returns the q namespace of the console, calling the methods of the query action
+func Namespace(c *api.Config) console.Namespace {
+	return console.Namespace{
+		Name: "q",
+		Type: reflect.TypeOf(&Query{}),
+		New: func() (interface{}, error) {
+			return NewQueryAction(c)
+		},
+		Docs: docs,
 	}
-	word = append(word, "BlockHeight()", "QueryTx()", "QueryPeers()", "QueryLocalPeers()",
-		"QueryInstalled()", "QueryChannels()")
-	return
 }
 
-func (q *Query) RunCommand(input string) (err error) {
-	_, method, params := console.ParseInputData(input)
-	if strings.ToLower(method) == "runcommand" || (len(method) > 0 && (method[0] < 65 || method[0] > 90)) {
-		return errors.New("method name error")
-	}
-	values := reflect.ValueOf(q).MethodByName(method).Call(params)
-	if err := values[len(values)-1]; !err.IsNil() {
-		return err.Interface().(error)
-	}
-	for i := 0; i < len(values)-1; i++ {
-		bytes, _ := jsonp.Marshal(values[i].Interface())
-		fmt.Printf("%02d - %s:\n%s\n", i+1, reflect.Indirect(values[i]).Type(), string(bytes))
-	}
-	return nil
+var docs = map[string]console.Doc{
+	"BlockHeight": {
+		Description: "Returns the height of the channel ledger, the number of the last block plus one",
+		Example:     "$h = q.BlockHeight()",
+	},
+	"QueryBlock": {
+		Params:      []string{"blockNumber"},
+		Description: "Returns the block of the number",
+		Example:     "$b = q.QueryBlock(1)",
+	},
+	"QueryBlockByHash": {
+		Params:      []string{"blockHash"},
+		Description: "Returns the block of the hash encoded in hex",
+		Example:     "q.QueryBlockByHash(<hex block hash>)",
+	},
+	"QueryBlockByTxID": {
+		Params:      []string{"txID"},
+		Description: "Returns the block containing the transaction",
+		Example:     "q.QueryBlockByTxID(<txID>)",
+	},
+	"QueryTransaction": {
+		Params:      []string{"txID"},
+		Description: "Returns the processed transaction with its validation code",
+		Example:     "q.QueryTransaction(<txID>)",
+	},
+	"QueryTx": {
+		Params:      []string{"txID"},
+		Description: "Returns the decoded transaction",
+		Example:     "$tx = q.QueryTx(<txID>)",
+	},
+	"QueryConfig": {
+		Description: "Returns the MSPs, anchor peers, orderers and versions of the channel config",
+		Example:     "q.QueryConfig()",
+	},
+	"QueryConfigBlock": {
+		Description: "Returns the latest config block of the channel",
+		Example:     "q.QueryConfigBlock()",
+	},
+	"QueryInfo": {
+		Description: "Returns the height and the hashes of the last blocks of the channel ledger",
+		Example:     "q.QueryInfo()",
+	},
+	"QueryChannels": {
+		Description: "Returns the channels joined by the peer",
+		Example:     "q.QueryChannels()",
+	},
+	"QueryInstalled": {
+		Description: "Returns the chaincodes installed on the peer",
+		Example:     "q.QueryInstalled()",
+	},
+	"QueryLocalPeers": {
+		Description: "Returns the peers of the org from the local discovery service",
+		Example:     "q.QueryLocalPeers()",
+	},
+	"QueryPeers": {
+		Params:      []string{"channelIDs"},
+		Description: "Returns the peers of the channel from the discovery service, the channel of the config by default",
+		Example:     "q.QueryPeers(mychannel)",
+	},
 }
 
 func (q *Query) Close() {
@@ -0,0 +1,135 @@
+package query
+
+import (
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/common"
+	msp2 "github.com/hyperledger/fabric-protos-go/msp"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/pkg/errors"
+)
+
+// ChaincodeCall is a chaincode invocation recorded in an endorser transaction of a block
+type ChaincodeCall struct {
+	BlockNumber    uint64
+	TxID           string
+	Timestamp      time.Time
+	MspID          string
+	ValidationCode string
+	ChaincodeID    string
+	// Args is the input of the chaincode, the first arg is the function
+	Args [][]byte
+}
+
+// ScanChaincodeCalls calls fn with the chaincode calls of the blocks from the block number to the block number included,
+// in the order they were committed. The scan stops at the first error returned by fn
+func (q *Query) ScanChaincodeCalls(from, to uint64, fn func(call ChaincodeCall) error) error {
+	if from > to {
+		return errors.Errorf("invalid block range %d to %d", from, to)
+	}
+	for number := from; number <= to; number++ {
+		block, err := q.Client.QueryBlock(number)
+		if err != nil {
+			return errors.WithMessagef(err, "failed to query block %d", number)
+		}
+		calls, err := DecodeChaincodeCalls(block)
+		if err != nil {
+			return errors.WithMessagef(err, "failed to decode block %d", number)
+		}
+		for _, call := range calls {
+			if err := fn(call); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// DecodeChaincodeCalls returns the chaincode calls of the endorser transactions of the block,
+// the other transactions such as config updates are skipped
+func DecodeChaincodeCalls(block *common.Block) ([]ChaincodeCall, error) {
+	if block.Header == nil || block.Data == nil {
+		return nil, errors.New("block has no header or data")
+	}
+	var txFilter []byte
+	if block.Metadata != nil && len(block.Metadata.Metadata) > int(common.BlockMetadataIndex_TRANSACTIONS_FILTER) {
+		txFilter = block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER]
+	}
+
+	var calls []ChaincodeCall
+	for i, envBytes := range block.Data.Data {
+		envelope := &common.Envelope{}
+		if err := proto.Unmarshal(envBytes, envelope); err != nil {
+			return nil, errors.Wrapf(err, "invalid envelope of transaction %d", i)
+		}
+		payload := &common.Payload{}
+		if err := proto.Unmarshal(envelope.Payload, payload); err != nil {
+			return nil, errors.Wrapf(err, "invalid payload of transaction %d", i)
+		}
+		if payload.Header == nil {
+			return nil, errors.Errorf("transaction %d has no header", i)
+		}
+		channelHeader := &common.ChannelHeader{}
+		if err := proto.Unmarshal(payload.Header.ChannelHeader, channelHeader); err != nil {
+			return nil, errors.Wrapf(err, "invalid channel header of transaction %d", i)
+		}
+		if common.HeaderType(channelHeader.Type) != common.HeaderType_ENDORSER_TRANSACTION {
+			continue
+		}
+
+		call := ChaincodeCall{BlockNumber: block.Header.Number, TxID: channelHeader.TxId}
+		if channelHeader.Timestamp != nil {
+			call.Timestamp = time.Unix(channelHeader.Timestamp.Seconds, int64(channelHeader.Timestamp.Nanos)).UTC()
+		}
+		if i < len(txFilter) {
+			call.ValidationCode = peer.TxValidationCode(txFilter[i]).String()
+		}
+		signatureHeader := &common.SignatureHeader{}
+		if err := proto.Unmarshal(payload.Header.SignatureHeader, signatureHeader); err != nil {
+			return nil, errors.Wrapf(err, "invalid signature header of transaction %d", i)
+		}
+		creator := &msp2.SerializedIdentity{}
+		if err := proto.Unmarshal(signatureHeader.Creator, creator); err != nil {
+			return nil, errors.Wrapf(err, "invalid creator of transaction %d", i)
+		}
+		call.MspID = creator.Mspid
+
+		tx := &peer.Transaction{}
+		if err := proto.Unmarshal(payload.Data, tx); err != nil {
+			return nil, errors.Wrapf(err, "invalid transaction %d", i)
+		}
+		for _, action := range tx.Actions {
+			spec, err := invocationSpec(action)
+			if err != nil {
+				return nil, errors.WithMessagef(err, "transaction %d", i)
+			}
+			if spec.ChaincodeSpec == nil || spec.ChaincodeSpec.ChaincodeId == nil {
+				continue
+			}
+			call.ChaincodeID = spec.ChaincodeSpec.ChaincodeId.Name
+			call.Args = nil
+			if spec.ChaincodeSpec.Input != nil {
+				call.Args = spec.ChaincodeSpec.Input.Args
+			}
+			calls = append(calls, call)
+		}
+	}
+	return calls, nil
+}
+
+func invocationSpec(action *peer.TransactionAction) (*peer.ChaincodeInvocationSpec, error) {
+	actionPayload := &peer.ChaincodeActionPayload{}
+	if err := proto.Unmarshal(action.Payload, actionPayload); err != nil {
+		return nil, errors.Wrap(err, "invalid chaincode action payload")
+	}
+	proposalPayload := &peer.ChaincodeProposalPayload{}
+	if err := proto.Unmarshal(actionPayload.ChaincodeProposalPayload, proposalPayload); err != nil {
+		return nil, errors.Wrap(err, "invalid chaincode proposal payload")
+	}
+	spec := &peer.ChaincodeInvocationSpec{}
+	if err := proto.Unmarshal(proposalPayload.Input, spec); err != nil {
+		return nil, errors.Wrap(err, "invalid chaincode invocation spec")
+	}
+	return spec, nil
+}
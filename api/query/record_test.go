@@ -0,0 +1,82 @@
+package query
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/hyperledger/fabric-protos-go/common"
+	msp2 "github.com/hyperledger/fabric-protos-go/msp"
+	"github.com/hyperledger/fabric-protos-go/peer"
+)
+
+func mustMarshal(t *testing.T, msg proto.Message) []byte {
+	bytes, err := proto.Marshal(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bytes
+}
+
+func newTestEnvelope(t *testing.T, headerType common.HeaderType, txID string, data []byte) []byte {
+	payload := &common.Payload{
+		Header: &common.Header{
+			ChannelHeader: mustMarshal(t, &common.ChannelHeader{
+				Type:      int32(headerType),
+				TxId:      txID,
+				Timestamp: &timestamp.Timestamp{Seconds: 1571000000, Nanos: 500},
+			}),
+			SignatureHeader: mustMarshal(t, &common.SignatureHeader{
+				Creator: mustMarshal(t, &msp2.SerializedIdentity{Mspid: "Org1MSP"}),
+			}),
+		},
+		Data: data,
+	}
+	return mustMarshal(t, &common.Envelope{Payload: mustMarshal(t, payload)})
+}
+
+func newTestTransaction(t *testing.T, chaincodeID string, args ...string) []byte {
+	spec := &peer.ChaincodeInvocationSpec{ChaincodeSpec: &peer.ChaincodeSpec{
+		ChaincodeId: &peer.ChaincodeID{Name: chaincodeID},
+		Input:       &peer.ChaincodeInput{},
+	}}
+	for _, arg := range args {
+		spec.ChaincodeSpec.Input.Args = append(spec.ChaincodeSpec.Input.Args, []byte(arg))
+	}
+	actionPayload := &peer.ChaincodeActionPayload{
+		ChaincodeProposalPayload: mustMarshal(t, &peer.ChaincodeProposalPayload{Input: mustMarshal(t, spec)}),
+	}
+	return mustMarshal(t, &peer.Transaction{Actions: []*peer.TransactionAction{{Payload: mustMarshal(t, actionPayload)}}})
+}
+
+func TestDecodeChaincodeCalls(t *testing.T) {
+	block := &common.Block{
+		Header: &common.BlockHeader{Number: 7},
+		Data: &common.BlockData{Data: [][]byte{
+			newTestEnvelope(t, common.HeaderType_CONFIG, "", nil),
+			newTestEnvelope(t, common.HeaderType_ENDORSER_TRANSACTION, "tx1", newTestTransaction(t, "mycc", "move", "A", "B", "1")),
+			newTestEnvelope(t, common.HeaderType_ENDORSER_TRANSACTION, "tx2", newTestTransaction(t, "mycc", "query", "A")),
+		}},
+		Metadata: &common.BlockMetadata{Metadata: [][]byte{{}, {}, {
+			byte(peer.TxValidationCode_VALID), byte(peer.TxValidationCode_VALID), byte(peer.TxValidationCode_MVCC_READ_CONFLICT),
+		}}},
+	}
+	calls, err := DecodeChaincodeCalls(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 2 {
+		t.Fatalf("expecting the 2 endorser transactions, got %+v", calls)
+	}
+	call := calls[0]
+	if call.BlockNumber != 7 || call.TxID != "tx1" || call.MspID != "Org1MSP" || call.ChaincodeID != "mycc" || call.ValidationCode != "VALID" {
+		t.Fatalf("unexpected call %+v", call)
+	}
+	if !call.Timestamp.Equal(time.Unix(1571000000, 500)) || len(call.Args) != 4 || string(call.Args[0]) != "move" {
+		t.Fatalf("unexpected call %+v", call)
+	}
+	if calls[1].ValidationCode != "MVCC_READ_CONFLICT" {
+		t.Fatalf("unexpected validation code %s", calls[1].ValidationCode)
+	}
+}
@@ -47,7 +47,7 @@ func TestNewQueryAction(t *testing.T) {
 	}
 	fmt.Println(response.Status, response.BCI.Height, hex.EncodeToString(response.BCI.CurrentBlockHash), hex.EncodeToString(response.BCI.PreviousBlockHash))
 
-	block, err := queryAction.QueryBlock("248")
+	block, err := queryAction.QueryBlock(248)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -10,6 +10,8 @@ import (
 	"os"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/zhcppy/fabricli/api"
 	"github.com/zhcppy/fabricli/api/chaincode"
 	"github.com/zhcppy/fabricli/api/channel"
 	"github.com/zhcppy/fabricli/api/event"
@@ -24,6 +26,9 @@ func main() {
 		Short: "Fabric Client",
 		Long:  ``,
 
+		// errors are printed by the caller of Execute
+		SilenceErrors: true,
+		SilenceUsage:  true,
 		Run: func(cmd *cobra.Command, args []string) {
 			cmd.HelpFunc()(cmd, args)
 		},
@@ -40,7 +45,7 @@ func main() {
 	cmd.InitSelectionProvider(flags)
 	cmd.InitOrdererTLSCertificate(flags)
 
-	rootCmd.AddCommand(console.NewCmd())
+	rootCmd.AddCommand(console.NewCmd(namespaces))
 	rootCmd.AddCommand(query.NewCmd())
 	rootCmd.AddCommand(event.NewCmd())
 	rootCmd.AddCommand(channel.NewCmd())
@@ -51,3 +56,14 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// namespaces returns the actions exposed in the console, their arguments are completed from the connection profile
+func namespaces(flags *pflag.FlagSet) console.Namespaces {
+	config := api.ConfigFlags(flags)
+	return console.Namespaces{
+		query.Namespace(config),
+		chaincode.Namespace(config),
+		channel.Namespace(config),
+		event.Namespace(config),
+	}.WithValues(config.CompletionValues())
+}
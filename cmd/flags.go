@@ -8,9 +8,9 @@ package cmd
 import (
 	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/spf13/pflag"
-	"github.com/spf13/viper"
 	"github.com/zhcppy/fabricli/api"
 )
 
@@ -27,7 +27,7 @@ func InitLoggingLevel(flags *pflag.FlagSet, defaultValueAndDescription ...string
 		fmt.Printf("Invalid number for [%s]: %s\n", loggingLevelFlag, defaultValue)
 	}
 	flags.Int(loggingLevelFlag, value, description)
-	viper.RegisterAlias(loggingLevelFlag, api.LoggerLevelTag)
+	api.RegisterFlag(loggingLevelFlag, api.LoggerLevelTag)
 	//viper.BindPFlag(api.LoggingLevelTag, flags.Lookup(loggingLevelFlag))
 }
 
@@ -40,7 +40,7 @@ func InitConfigFile(flags *pflag.FlagSet, defaultValueAndDescription ...string)
 	)
 	defaultValue, description := GetDefaultValueAndDescription(defaultConfigFile, configFileDescription, defaultValueAndDescription...)
 	flags.String(configFileFlag, defaultValue, description)
-	viper.RegisterAlias(configFileFlag, api.ConfigFileTag)
+	api.RegisterFlag(configFileFlag, api.ConfigFileTag)
 	//viper.BindPFlag(api.ConfigFileTag, flags.Lookup(configFileFlag))
 }
 
@@ -53,7 +53,7 @@ func InitUserName(flags *pflag.FlagSet, defaultValueAndDescription ...string) {
 	)
 	defaultValue, description := GetDefaultValueAndDescription(defaultUser, userDescription, defaultValueAndDescription...)
 	flags.String(userFlag, defaultValue, description)
-	viper.RegisterAlias(userFlag, api.UserTag)
+	api.RegisterFlag(userFlag, api.UserTag)
 	//viper.BindPFlag(api.UserTag, flags.Lookup(userFlag))
 }
 
@@ -66,7 +66,7 @@ func InitChannelID(flags *pflag.FlagSet, defaultValueAndDescription ...string) {
 	)
 	defaultValue, description := GetDefaultValueAndDescription(defaultChannelID, channelIDDescription, defaultValueAndDescription...)
 	flags.String(channelIDFlag, defaultValue, description)
-	viper.RegisterAlias(channelIDFlag, api.ChannelIDTag)
+	api.RegisterFlag(channelIDFlag, api.ChannelIDTag)
 	//viper.BindPFlag(api.ChannelIDTag, flags.Lookup(channelIDFlag))
 }
 
@@ -79,6 +79,7 @@ func InitOrgIDs(flags *pflag.FlagSet, defaultValueAndDescription ...string) {
 	)
 	defaultValue, description := GetDefaultValueAndDescription(defaultOrgIDs, orgIDsDescription, defaultValueAndDescription...)
 	flags.String(orgIDsFlag, defaultValue, description)
+	api.RegisterFlag(orgIDsFlag, api.OrgIdTag)
 	//viper.BindPFlag(api.OrgIdTag, flags.Lookup(orgIDsFlag))
 }
 
@@ -91,7 +92,7 @@ func InitPeerURL(flags *pflag.FlagSet, defaultValueAndDescription ...string) {
 	)
 	defaultValue, description := GetDefaultValueAndDescription(defaultPeerURL, peerURLDescription, defaultValueAndDescription...)
 	flags.String(peerURLFlag, defaultValue, description)
-	viper.RegisterAlias(peerURLFlag, api.PeerUrlTag)
+	api.RegisterFlag(peerURLFlag, api.PeerUrlTag)
 	//viper.BindPFlag(api.PeerUrlTag, flags.Lookup(peerURLFlag))
 }
 
@@ -104,7 +105,7 @@ func InitOrdererURL(flags *pflag.FlagSet, defaultValueAndDescription ...string)
 	)
 	defaultValue, description := GetDefaultValueAndDescription(defaultOrdererURL, ordererURLDescription, defaultValueAndDescription...)
 	flags.String(ordererFlag, defaultValue, description)
-	viper.RegisterAlias(ordererFlag, api.OrdererUrlTag)
+	api.RegisterFlag(ordererFlag, api.OrdererUrlTag)
 	//viper.BindPFlag(api.OrdererUrlTag, flags.Lookup(ordererFlag))
 }
 
@@ -117,7 +118,7 @@ func InitChaincodeID(flags *pflag.FlagSet, defaultValueAndDescription ...string)
 	)
 	defaultValue, description := GetDefaultValueAndDescription(defaultChaincodeID, chaincodeIDDescription, defaultValueAndDescription...)
 	flags.String(chaincodeIDFlag, defaultValue, description)
-	viper.RegisterAlias(chaincodeIDFlag, api.ChaincodeIDTag)
+	api.RegisterFlag(chaincodeIDFlag, api.ChaincodeIDTag)
 	//viper.BindPFlag(api.ChaincodeIDTag, flags.Lookup(chaincodeIDFlag))
 }
 
@@ -130,7 +131,7 @@ func InitChaincodeEvent(flags *pflag.FlagSet, defaultValueAndDescription ...stri
 	)
 	defaultValue, description := GetDefaultValueAndDescription(defaultChaincodeEvent, chaincodeEventDescription, defaultValueAndDescription...)
 	flags.String(chaincodeEventFlag, defaultValue, description)
-	viper.RegisterAlias(chaincodeEventFlag, api.ChaincodeEventTag)
+	api.RegisterFlag(chaincodeEventFlag, api.ChaincodeEventTag)
 	//viper.BindPFlag(api.ChaincodeEventTag, flags.Lookup(chaincodeEventFlag))
 }
 
@@ -143,7 +144,7 @@ func InitChaincodePath(flags *pflag.FlagSet, defaultValueAndDescription ...strin
 	)
 	defaultValue, description := GetDefaultValueAndDescription(defaultChaincodePath, chaincodePathDescription, defaultValueAndDescription...)
 	flags.String(chaincodePathFlag, defaultValue, description)
-	viper.RegisterAlias(chaincodePathFlag, api.ChaincodePathTag)
+	api.RegisterFlag(chaincodePathFlag, api.ChaincodePathTag)
 	//viper.BindPFlag(api.ChaincodePathTag, flags.Lookup(chaincodePathFlag))
 }
 
@@ -156,7 +157,7 @@ func InitChaincodePolicy(flags *pflag.FlagSet, defaultValueAndDescription ...str
 	)
 	defaultValue, description := GetDefaultValueAndDescription(defaultChaincodePolicy, chaincodePolicyDescription, defaultValueAndDescription...)
 	flags.String(chaincodePolicyFlag, defaultValue, description)
-	viper.RegisterAlias(chaincodePolicyFlag, api.ChaincodePolicyTag)
+	api.RegisterFlag(chaincodePolicyFlag, api.ChaincodePolicyTag)
 	//viper.BindPFlag(api.ChaincodePolicyTag, flags.Lookup(chaincodePolicyFlag))
 }
 
@@ -169,7 +170,7 @@ func InitChaincodeVersion(flags *pflag.FlagSet, defaultValueAndDescription ...st
 	)
 	defaultValue, description := GetDefaultValueAndDescription(defaultChaincodeVersion, chaincodeVersionDescription, defaultValueAndDescription...)
 	flags.String(chaincodeVersionFlag, defaultValue, description)
-	viper.RegisterAlias(chaincodeVersionFlag, api.ChaincodeVersionTag)
+	api.RegisterFlag(chaincodeVersionFlag, api.ChaincodeVersionTag)
 	//viper.BindPFlag(api.ChaincodeVersionTag, flags.Lookup(chaincodeVersionFlag))
 }
 
@@ -178,25 +179,26 @@ func InitChaincodeArgs(flags *pflag.FlagSet, defaultValueAndDescription ...strin
 	//Note that $rand(N) may be used anywhere within the value of the arg in order to generate a random value between 0 and N. For example {"Func":"function","Args":["arg_$rand(100)","$rand(10)"]}.
 	const (
 		chaincodeArgsFlag = "args"
-		argsDescription   = `The args in JSON format. Example: {"Func":"function","Args":["arg1","arg2"]}.`
+		argsDescription   = `The args in JSON format, with optional transient data. Example: {"Func":"function","Args":["arg1","arg2"],"Transient":{"key":"value"}}. A backslash escapes $ ( ) , and \ in the args, the other backslashes are kept as is.`
 		defaultArgsFlag   = ""
 	)
 	defaultValue, description := GetDefaultValueAndDescription(defaultArgsFlag, argsDescription, defaultValueAndDescription...)
 	flags.String(chaincodeArgsFlag, defaultValue, description)
-	viper.RegisterAlias(chaincodeArgsFlag, api.ChaincodeArgsTag)
+	api.RegisterFlag(chaincodeArgsFlag, api.ChaincodeArgsTag)
 	//viper.BindPFlag(api.ChaincodeArgsTag, flags.Lookup(chaincodeArgsFlag))
 }
 
+const TxFileFlag = "txfile"
+
 // InitTxFile initializes the path of the .tx file used to create/update a channel from the provided arguments
 func InitTxFile(flags *pflag.FlagSet, defaultValueAndDescription ...string) {
 	const (
-		txFileFlag        = "txfile"
 		txFileDescription = "The path of the channel.tx file"
 		defaultTxFile     = ""
 	)
 	defaultValue, description := GetDefaultValueAndDescription(defaultTxFile, txFileDescription, defaultValueAndDescription...)
-	flags.String(txFileFlag, defaultValue, description)
-	//viper.BindPFlag(api.tx, flags.Lookup(txFileFlag))
+	flags.String(TxFileFlag, defaultValue, description)
+	//viper.BindPFlag(api.tx, flags.Lookup(TxFileFlag))
 }
 
 const TxIDFlag = "txid"
@@ -248,7 +250,7 @@ func InitCollectionConfigFile(flags *pflag.FlagSet, defaultValueAndDescription .
 	)
 	defaultValue, description := GetDefaultValueAndDescription(defaultCollectionConfigFile, collectionConfigFileDescription, defaultValueAndDescription...)
 	flags.String(collectionConfigFileFlag, defaultValue, description)
-	viper.RegisterAlias(collectionConfigFileFlag, api.CollectionConfigFileTag)
+	api.RegisterFlag(collectionConfigFileFlag, api.CollectionConfigFileTag)
 	//viper.BindPFlag(api.CollectionConfigFileTag, flags.Lookup(collectionConfigFileFlag))
 }
 
@@ -262,7 +264,7 @@ func InitSelectionProvider(flags *pflag.FlagSet, defaultValueAndDescription ...s
 	)
 	defaultValue, description := GetDefaultValueAndDescription(defaultSelectionProvider, selectionProviderDescription, defaultValueAndDescription...)
 	flags.String(selectionProviderFlag, defaultValue, description)
-	viper.RegisterAlias(selectionProviderFlag, api.SelectionProviderTag)
+	api.RegisterFlag(selectionProviderFlag, api.SelectionProviderTag)
 	//viper.BindPFlag(api.SelectionProviderTag, flags.Lookup(selectionProviderFlag))
 }
 
@@ -287,9 +289,554 @@ func InitGoPath(flags *pflag.FlagSet, defaultValueAndDescription ...string) {
 	)
 	defaultValue, description := GetDefaultValueAndDescription(defaultGoPath, goPathDescription, defaultValueAndDescription...)
 	flags.String(goPathFlag, defaultValue, description)
+	api.RegisterFlag(goPathFlag, api.GoPathTag)
 	//viper.BindPFlag(api.GoPathTag, flags.Lookup(goPathFlag))
 }
 
+// InitChaincodeLang initializes the chaincode language from the provided arguments
+func InitChaincodeLang(flags *pflag.FlagSet, defaultValueAndDescription ...string) {
+	const (
+		chaincodeLangFlag        = "lang"
+		chaincodeLangDescription = "The language the chaincode is written in: golang, node or java"
+		defaultChaincodeLang     = ""
+	)
+	defaultValue, description := GetDefaultValueAndDescription(defaultChaincodeLang, chaincodeLangDescription, defaultValueAndDescription...)
+	flags.String(chaincodeLangFlag, defaultValue, description)
+	api.RegisterFlag(chaincodeLangFlag, api.ChaincodeLangTag)
+}
+
+const ChaincodePackageFlag = "package"
+
+// InitChaincodePackage initializes the path of the chaincode package file from the provided arguments
+func InitChaincodePackage(flags *pflag.FlagSet, defaultValueAndDescription ...string) {
+	const (
+		chaincodePackageDescription = "The path of the chaincode package (.cds/.pak) file"
+		defaultChaincodePackage     = ""
+	)
+	defaultValue, description := GetDefaultValueAndDescription(defaultChaincodePackage, chaincodePackageDescription, defaultValueAndDescription...)
+	flags.String(ChaincodePackageFlag, defaultValue, description)
+}
+
+const SignPackageFlag = "sign"
+
+// InitSignPackage initializes whether the chaincode package is signed by the user from the provided arguments
+func InitSignPackage(flags *pflag.FlagSet, defaultValueAndDescription ...string) {
+	const (
+		signPackageDescription = "Wrap the chaincode package into a signed CDS envelope endorsed by the user"
+		defaultSignPackage     = "false"
+	)
+	defaultValue, description := GetDefaultValueAndDescription(defaultSignPackage, signPackageDescription, defaultValueAndDescription...)
+	value, err := strconv.ParseBool(defaultValue)
+	if err != nil {
+		fmt.Printf("Invalid bool for [%s]: %s\n", SignPackageFlag, defaultValue)
+	}
+	flags.Bool(SignPackageFlag, value, description)
+}
+
+const InstantiatePolicyFlag = "instantiate-policy"
+
+// InitInstantiatePolicy initializes the instantiation policy of a signed chaincode package from the provided arguments
+func InitInstantiatePolicy(flags *pflag.FlagSet, defaultValueAndDescription ...string) {
+	const (
+		instantiatePolicyDescription = "The instantiation policy of the signed chaincode package, defaults to AND('<user MSPID>.admin')"
+		defaultInstantiatePolicy     = ""
+	)
+	defaultValue, description := GetDefaultValueAndDescription(defaultInstantiatePolicy, instantiatePolicyDescription, defaultValueAndDescription...)
+	flags.String(InstantiatePolicyFlag, defaultValue, description)
+}
+
+const PrivateFuncFlag = "func"
+
+// InitPrivateFunc initializes the chaincode function that reads private data from the provided arguments
+func InitPrivateFunc(flags *pflag.FlagSet, defaultValueAndDescription ...string) {
+	const (
+		privateFuncDescription = "The chaincode function that reads a key from a private data collection"
+		defaultPrivateFunc     = ""
+	)
+	defaultValue, description := GetDefaultValueAndDescription(defaultPrivateFunc, privateFuncDescription, defaultValueAndDescription...)
+	flags.String(PrivateFuncFlag, defaultValue, description)
+}
+
+const CollectionArgFlag = "collection-arg"
+
+// InitCollectionArg initializes whether the collection is passed to the private data function from the provided arguments
+func InitCollectionArg(flags *pflag.FlagSet, defaultValueAndDescription ...string) {
+	const (
+		collectionArgDescription = "Pass the collection as the first argument of the function, followed by the key. With false the key is the only argument"
+		defaultCollectionArg     = "true"
+	)
+	defaultValue, description := GetDefaultValueAndDescription(defaultCollectionArg, collectionArgDescription, defaultValueAndDescription...)
+	value, err := strconv.ParseBool(defaultValue)
+	if err != nil {
+		fmt.Printf("Invalid bool for [%s]: %s\n", CollectionArgFlag, defaultValue)
+	}
+	flags.Bool(CollectionArgFlag, value, description)
+}
+
+const TransientFlag = "transient"
+
+// InitTransient initializes the transient data sent with the proposals from the provided arguments
+func InitTransient(flags *pflag.FlagSet, defaultValueAndDescription ...string) {
+	const (
+		transientDescription = `The transient data in JSON format. Example: {"key":"value"}`
+		defaultTransient     = ""
+	)
+	defaultValue, description := GetDefaultValueAndDescription(defaultTransient, transientDescription, defaultValueAndDescription...)
+	flags.String(TransientFlag, defaultValue, description)
+}
+
+const LabelFlag = "label"
+
+// InitLabel initializes the label of the lifecycle chaincode package from the provided arguments
+func InitLabel(flags *pflag.FlagSet, defaultValueAndDescription ...string) {
+	const (
+		labelDescription = "The label of the lifecycle chaincode package, e.g. mycc_1"
+		defaultLabel     = ""
+	)
+	defaultValue, description := GetDefaultValueAndDescription(defaultLabel, labelDescription, defaultValueAndDescription...)
+	flags.String(LabelFlag, defaultValue, description)
+}
+
+const SequenceFlag = "sequence"
+
+// InitSequence initializes the sequence of the chaincode definition from the provided arguments
+func InitSequence(flags *pflag.FlagSet, defaultValueAndDescription ...string) {
+	const (
+		sequenceDescription = "The sequence number of the chaincode definition for the channel"
+		defaultSequence     = "1"
+	)
+	defaultValue, description := GetDefaultValueAndDescription(defaultSequence, sequenceDescription, defaultValueAndDescription...)
+	value, err := strconv.ParseInt(defaultValue, 10, 64)
+	if err != nil {
+		fmt.Printf("Invalid number for [%s]: %s\n", SequenceFlag, defaultValue)
+	}
+	flags.Int64(SequenceFlag, value, description)
+}
+
+const PackageIDFlag = "package-id"
+
+// InitPackageID initializes the ID of the installed lifecycle chaincode package from the provided arguments
+func InitPackageID(flags *pflag.FlagSet, defaultValueAndDescription ...string) {
+	const (
+		packageIDDescription = "The identifier of the chaincode install package, e.g. mycc_1:<hash>"
+		defaultPackageID     = ""
+	)
+	defaultValue, description := GetDefaultValueAndDescription(defaultPackageID, packageIDDescription, defaultValueAndDescription...)
+	flags.String(PackageIDFlag, defaultValue, description)
+}
+
+const InitRequiredFlag = "init-required"
+
+// InitInitRequired initializes whether the chaincode requires Init to be invoked from the provided arguments
+func InitInitRequired(flags *pflag.FlagSet, defaultValueAndDescription ...string) {
+	const (
+		initRequiredDescription = "Whether the chaincode requires invoking 'init'"
+		defaultInitRequired     = "false"
+	)
+	defaultValue, description := GetDefaultValueAndDescription(defaultInitRequired, initRequiredDescription, defaultValueAndDescription...)
+	value, err := strconv.ParseBool(defaultValue)
+	if err != nil {
+		fmt.Printf("Invalid bool for [%s]: %s\n", InitRequiredFlag, defaultValue)
+	}
+	flags.Bool(InitRequiredFlag, value, description)
+}
+
+const ChannelConfigPolicyFlag = "channel-config-policy"
+
+// InitChannelConfigPolicy initializes the channel config policy reference used as endorsement policy from the provided arguments
+func InitChannelConfigPolicy(flags *pflag.FlagSet, defaultValueAndDescription ...string) {
+	const (
+		channelConfigPolicyDescription = "The endorsement policy referenced from the channel config, e.g. /Channel/Application/Endorsement"
+		defaultChannelConfigPolicy     = ""
+	)
+	defaultValue, description := GetDefaultValueAndDescription(defaultChannelConfigPolicy, channelConfigPolicyDescription, defaultValueAndDescription...)
+	flags.String(ChannelConfigPolicyFlag, defaultValue, description)
+}
+
+const IterationsFlag = "iterations"
+
+// InitIterations initializes the number of invoke iterations from the provided arguments
+func InitIterations(flags *pflag.FlagSet, defaultValueAndDescription ...string) {
+	const (
+		iterationsDescription = "The number of times to invoke the chaincode with the whole args array"
+		defaultIterations     = "1"
+	)
+	defaultValue, description := GetDefaultValueAndDescription(defaultIterations, iterationsDescription, defaultValueAndDescription...)
+	value, err := strconv.Atoi(defaultValue)
+	if err != nil {
+		fmt.Printf("Invalid number for [%s]: %s\n", IterationsFlag, defaultValue)
+	}
+	flags.Int(IterationsFlag, value, description)
+}
+
+const ConcurrencyFlag = "concurrency"
+
+// InitConcurrency initializes the number of concurrent invocations from the provided arguments
+func InitConcurrency(flags *pflag.FlagSet, defaultValueAndDescription ...string) {
+	const (
+		concurrencyDescription = "The number of workers invoking the chaincode concurrently"
+		defaultConcurrency     = "1"
+	)
+	defaultValue, description := GetDefaultValueAndDescription(defaultConcurrency, concurrencyDescription, defaultValueAndDescription...)
+	value, err := strconv.Atoi(defaultValue)
+	if err != nil {
+		fmt.Printf("Invalid number for [%s]: %s\n", ConcurrencyFlag, defaultValue)
+	}
+	flags.Int(ConcurrencyFlag, value, description)
+}
+
+const AttemptsFlag = "attempts"
+
+// InitAttempts initializes the maximum number of retry attempts of an invocation from the provided arguments
+func InitAttempts(flags *pflag.FlagSet, defaultValueAndDescription ...string) {
+	const (
+		attemptsDescription = "The maximum number of retry attempts of an invocation that failed with a transient error"
+		defaultAttempts     = "3"
+	)
+	defaultValue, description := GetDefaultValueAndDescription(defaultAttempts, attemptsDescription, defaultValueAndDescription...)
+	value, err := strconv.Atoi(defaultValue)
+	if err != nil {
+		fmt.Printf("Invalid number for [%s]: %s\n", AttemptsFlag, defaultValue)
+	}
+	flags.Int(AttemptsFlag, value, description)
+}
+
+const InitialBackoffFlag = "backoff"
+
+// InitInitialBackoff initializes the backoff before the first retry from the provided arguments
+func InitInitialBackoff(flags *pflag.FlagSet, defaultValueAndDescription ...string) {
+	const (
+		initialBackoffDescription = "The backoff before the first retry attempt, e.g. 500ms"
+		defaultInitialBackoff     = "1s"
+	)
+	defaultValue, description := GetDefaultValueAndDescription(defaultInitialBackoff, initialBackoffDescription, defaultValueAndDescription...)
+	value, err := time.ParseDuration(defaultValue)
+	if err != nil {
+		fmt.Printf("Invalid duration for [%s]: %s\n", InitialBackoffFlag, defaultValue)
+	}
+	flags.Duration(InitialBackoffFlag, value, description)
+}
+
+const MaxBackoffFlag = "maxbackoff"
+
+// InitMaxBackoff initializes the maximum backoff between retries from the provided arguments
+func InitMaxBackoff(flags *pflag.FlagSet, defaultValueAndDescription ...string) {
+	const (
+		maxBackoffDescription = "The maximum backoff between retry attempts"
+		defaultMaxBackoff     = "5s"
+	)
+	defaultValue, description := GetDefaultValueAndDescription(defaultMaxBackoff, maxBackoffDescription, defaultValueAndDescription...)
+	value, err := time.ParseDuration(defaultValue)
+	if err != nil {
+		fmt.Printf("Invalid duration for [%s]: %s\n", MaxBackoffFlag, defaultValue)
+	}
+	flags.Duration(MaxBackoffFlag, value, description)
+}
+
+const BackoffFactorFlag = "backofffactor"
+
+// InitBackoffFactor initializes the factor the backoff grows by after each retry from the provided arguments
+func InitBackoffFactor(flags *pflag.FlagSet, defaultValueAndDescription ...string) {
+	const (
+		backoffFactorDescription = "The factor the backoff grows by after each retry attempt"
+		defaultBackoffFactor     = "2"
+	)
+	defaultValue, description := GetDefaultValueAndDescription(defaultBackoffFactor, backoffFactorDescription, defaultValueAndDescription...)
+	value, err := strconv.ParseFloat(defaultValue, 64)
+	if err != nil {
+		fmt.Printf("Invalid number for [%s]: %s\n", BackoffFactorFlag, defaultValue)
+	}
+	flags.Float64(BackoffFactorFlag, value, description)
+}
+
+const TPSFlag = "tps"
+
+// InitTPS initializes the target rate of an open-loop invoke run from the provided arguments
+func InitTPS(flags *pflag.FlagSet, defaultValueAndDescription ...string) {
+	const (
+		tpsDescription = "The target rate of iterations per second, submitted regardless of how fast they complete. 0 submits them as fast as the workers take them"
+		defaultTPS     = "0"
+	)
+	defaultValue, description := GetDefaultValueAndDescription(defaultTPS, tpsDescription, defaultValueAndDescription...)
+	value, err := strconv.ParseFloat(defaultValue, 64)
+	if err != nil {
+		fmt.Printf("Invalid number for [%s]: %s\n", TPSFlag, defaultValue)
+	}
+	flags.Float64(TPSFlag, value, description)
+}
+
+const DurationFlag = "duration"
+
+// InitDuration initializes the duration of an open-loop invoke run from the provided arguments
+func InitDuration(flags *pflag.FlagSet, defaultValueAndDescription ...string) {
+	const (
+		durationDescription = "The duration of the run at the target rate instead of a number of iterations, e.g. 5m"
+		defaultDuration     = "0"
+	)
+	defaultValue, description := GetDefaultValueAndDescription(defaultDuration, durationDescription, defaultValueAndDescription...)
+	value, err := time.ParseDuration(defaultValue)
+	if err != nil {
+		fmt.Printf("Invalid duration for [%s]: %s\n", DurationFlag, defaultValue)
+	}
+	flags.Duration(DurationFlag, value, description)
+}
+
+const RampStepsFlag = "rampup-steps"
+
+// InitRampSteps initializes the number of ramp-up steps of an open-loop invoke run from the provided arguments
+func InitRampSteps(flags *pflag.FlagSet, defaultValueAndDescription ...string) {
+	const (
+		rampStepsDescription = "The number of equal steps the rate is ramped up in before reaching the target rate"
+		defaultRampSteps     = "0"
+	)
+	defaultValue, description := GetDefaultValueAndDescription(defaultRampSteps, rampStepsDescription, defaultValueAndDescription...)
+	value, err := strconv.Atoi(defaultValue)
+	if err != nil {
+		fmt.Printf("Invalid number for [%s]: %s\n", RampStepsFlag, defaultValue)
+	}
+	flags.Int(RampStepsFlag, value, description)
+}
+
+const RampDurationFlag = "rampup-duration"
+
+// InitRampDuration initializes the total duration of the ramp-up steps from the provided arguments
+func InitRampDuration(flags *pflag.FlagSet, defaultValueAndDescription ...string) {
+	const (
+		rampDurationDescription = "The total duration of the ramp-up steps, e.g. 1m"
+		defaultRampDuration     = "0"
+	)
+	defaultValue, description := GetDefaultValueAndDescription(defaultRampDuration, rampDurationDescription, defaultValueAndDescription...)
+	value, err := time.ParseDuration(defaultValue)
+	if err != nil {
+		fmt.Printf("Invalid duration for [%s]: %s\n", RampDurationFlag, defaultValue)
+	}
+	flags.Duration(RampDurationFlag, value, description)
+}
+
+const AsyncFlag = "async"
+
+// InitAsync initializes whether the transactions are submitted asynchronously from the provided arguments
+func InitAsync(flags *pflag.FlagSet, defaultValueAndDescription ...string) {
+	const (
+		asyncDescription = "Complete an invocation once the orderer accepts the transaction and track the commit status separately"
+		defaultAsync     = "false"
+	)
+	defaultValue, description := GetDefaultValueAndDescription(defaultAsync, asyncDescription, defaultValueAndDescription...)
+	value, err := strconv.ParseBool(defaultValue)
+	if err != nil {
+		fmt.Printf("Invalid bool for [%s]: %s\n", AsyncFlag, defaultValue)
+	}
+	flags.Bool(AsyncFlag, value, description)
+}
+
+const CommitTimeoutFlag = "commit-timeout"
+
+// InitCommitTimeout initializes the time the status of an asynchronous transaction is waited for from the provided arguments
+func InitCommitTimeout(flags *pflag.FlagSet, defaultValueAndDescription ...string) {
+	const (
+		commitTimeoutDescription = "The time the commit status of a transaction submitted with --async is waited for"
+		defaultCommitTimeout     = "30s"
+	)
+	defaultValue, description := GetDefaultValueAndDescription(defaultCommitTimeout, commitTimeoutDescription, defaultValueAndDescription...)
+	value, err := time.ParseDuration(defaultValue)
+	if err != nil {
+		fmt.Printf("Invalid duration for [%s]: %s\n", CommitTimeoutFlag, defaultValue)
+	}
+	flags.Duration(CommitTimeoutFlag, value, description)
+}
+
+const DryRunFlag = "dry-run"
+
+// InitDryRun initializes whether the transactions are only endorsed from the provided arguments
+func InitDryRun(flags *pflag.FlagSet, defaultValueAndDescription ...string) {
+	const (
+		dryRunDescription = "Only endorse the proposals and print their read/write sets, payloads, events and endorsers without submitting the transactions"
+		defaultDryRun     = "false"
+	)
+	defaultValue, description := GetDefaultValueAndDescription(defaultDryRun, dryRunDescription, defaultValueAndDescription...)
+	value, err := strconv.ParseBool(defaultValue)
+	if err != nil {
+		fmt.Printf("Invalid bool for [%s]: %s\n", DryRunFlag, defaultValue)
+	}
+	flags.Bool(DryRunFlag, value, description)
+}
+
+const TaskTimeoutFlag = "task-timeout"
+
+// InitTaskTimeout initializes the time an invoke iteration is given to complete from the provided arguments
+func InitTaskTimeout(flags *pflag.FlagSet, defaultValueAndDescription ...string) {
+	const (
+		taskTimeoutDescription = "The time an iteration is given to complete including its retries, e.g. 30s. 0 means no timeout"
+		defaultTaskTimeout     = "0"
+	)
+	defaultValue, description := GetDefaultValueAndDescription(defaultTaskTimeout, taskTimeoutDescription, defaultValueAndDescription...)
+	value, err := time.ParseDuration(defaultValue)
+	if err != nil {
+		fmt.Printf("Invalid duration for [%s]: %s\n", TaskTimeoutFlag, defaultValue)
+	}
+	flags.Duration(TaskTimeoutFlag, value, description)
+}
+
+const SeedFlag = "seed"
+
+// InitSeed initializes the seed of the random values of the invoke args from the provided arguments
+func InitSeed(flags *pflag.FlagSet, defaultValueAndDescription ...string) {
+	const (
+		seedDescription = "The seed of the random values and the sequence of the args, runs with the same seed invoke the same args. 0 picks a random seed"
+		defaultSeed     = "0"
+	)
+	defaultValue, description := GetDefaultValueAndDescription(defaultSeed, seedDescription, defaultValueAndDescription...)
+	value, err := strconv.ParseInt(defaultValue, 10, 64)
+	if err != nil {
+		fmt.Printf("Invalid number for [%s]: %s\n", SeedFlag, defaultValue)
+	}
+	flags.Int64(SeedFlag, value, description)
+}
+
+const ReportFlag = "report"
+
+// InitReport initializes the format of the invoke report from the provided arguments
+func InitReport(flags *pflag.FlagSet, defaultValueAndDescription ...string) {
+	const (
+		reportDescription = "Write the results of every invocation to a report file in the given format: json or csv"
+		defaultReport     = ""
+	)
+	defaultValue, description := GetDefaultValueAndDescription(defaultReport, reportDescription, defaultValueAndDescription...)
+	flags.String(ReportFlag, defaultValue, description)
+}
+
+const ReportFileFlag = "report-file"
+
+// InitReportFile initializes the path of the invoke report file from the provided arguments
+func InitReportFile(flags *pflag.FlagSet, defaultValueAndDescription ...string) {
+	const (
+		reportFileDescription = "The path of the report file, defaults to invoke-report.<format>"
+		defaultReportFile     = ""
+	)
+	defaultValue, description := GetDefaultValueAndDescription(defaultReportFile, reportFileDescription, defaultValueAndDescription...)
+	flags.String(ReportFileFlag, defaultValue, description)
+}
+
+const FromBlockFlag = "from"
+
+// InitFromBlock initializes the first block of a block range from the provided arguments
+func InitFromBlock(flags *pflag.FlagSet, defaultValueAndDescription ...string) {
+	const (
+		fromBlockDescription = "The number of the first block of the range"
+		defaultFromBlock     = "0"
+	)
+	defaultValue, description := GetDefaultValueAndDescription(defaultFromBlock, fromBlockDescription, defaultValueAndDescription...)
+	value, err := strconv.ParseUint(defaultValue, 10, 64)
+	if err != nil {
+		fmt.Printf("Invalid number for [%s]: %s\n", FromBlockFlag, defaultValue)
+	}
+	flags.Uint64(FromBlockFlag, value, description)
+}
+
+const ToBlockFlag = "to"
+
+// InitToBlock initializes the last block of a block range from the provided arguments
+func InitToBlock(flags *pflag.FlagSet, defaultValueAndDescription ...string) {
+	const (
+		toBlockDescription = "The number of the last block of the range (included), -1 means the last block of the channel"
+		defaultToBlock     = "-1"
+	)
+	defaultValue, description := GetDefaultValueAndDescription(defaultToBlock, toBlockDescription, defaultValueAndDescription...)
+	value, err := strconv.ParseInt(defaultValue, 10, 64)
+	if err != nil {
+		fmt.Printf("Invalid number for [%s]: %s\n", ToBlockFlag, defaultValue)
+	}
+	flags.Int64(ToBlockFlag, value, description)
+}
+
+const OutputFileFlag = "out"
+
+// InitOutputFile initializes the path of the file a command writes from the provided arguments
+func InitOutputFile(flags *pflag.FlagSet, defaultValueAndDescription ...string) {
+	const (
+		outputFileDescription = "The path of the output file"
+		defaultOutputFile     = ""
+	)
+	defaultValue, description := GetDefaultValueAndDescription(defaultOutputFile, outputFileDescription, defaultValueAndDescription...)
+	flags.String(OutputFileFlag, defaultValue, description)
+}
+
+const OriginalTimingFlag = "original-timing"
+
+// InitOriginalTiming initializes whether a replay keeps the original timing of the calls from the provided arguments
+func InitOriginalTiming(flags *pflag.FlagSet, defaultValueAndDescription ...string) {
+	const (
+		originalTimingDescription = "Replay the calls at the same time offsets as they were originally committed instead of as fast as possible"
+		defaultOriginalTiming     = "false"
+	)
+	defaultValue, description := GetDefaultValueAndDescription(defaultOriginalTiming, originalTimingDescription, defaultValueAndDescription...)
+	value, err := strconv.ParseBool(defaultValue)
+	if err != nil {
+		fmt.Printf("Invalid bool for [%s]: %s\n", OriginalTimingFlag, defaultValue)
+	}
+	flags.Bool(OriginalTimingFlag, value, description)
+}
+
+const ExecFileFlag = "exec"
+
+// InitExecFile initializes the path of the console script to run from the provided arguments
+func InitExecFile(flags *pflag.FlagSet, defaultValueAndDescription ...string) {
+	const (
+		execFileDescription = "The path of a file of console commands to run non-interactively, stopping at the first error"
+		defaultExecFile     = ""
+	)
+	defaultValue, description := GetDefaultValueAndDescription(defaultExecFile, execFileDescription, defaultValueAndDescription...)
+	flags.String(ExecFileFlag, defaultValue, description)
+}
+
+const OriginalConfigFlag = "original"
+
+// InitOriginalConfig initializes the path of the JSON channel config an update is computed from the provided arguments
+func InitOriginalConfig(flags *pflag.FlagSet, defaultValueAndDescription ...string) {
+	const (
+		originalConfigDescription = "The path of the JSON channel config fetched by channel config fetch"
+		defaultOriginalConfig     = ""
+	)
+	defaultValue, description := GetDefaultValueAndDescription(defaultOriginalConfig, originalConfigDescription, defaultValueAndDescription...)
+	flags.String(OriginalConfigFlag, defaultValue, description)
+}
+
+const UpdatedConfigFlag = "updated"
+
+// InitUpdatedConfig initializes the path of the edited JSON channel config from the provided arguments
+func InitUpdatedConfig(flags *pflag.FlagSet, defaultValueAndDescription ...string) {
+	const (
+		updatedConfigDescription = "The path of the edited copy of the JSON channel config"
+		defaultUpdatedConfig     = ""
+	)
+	defaultValue, description := GetDefaultValueAndDescription(defaultUpdatedConfig, updatedConfigDescription, defaultValueAndDescription...)
+	flags.String(UpdatedConfigFlag, defaultValue, description)
+}
+
+const MSPDirFlag = "msp-dir"
+
+// InitMSPDir initializes the path of the MSP directory of an org from the provided arguments
+func InitMSPDir(flags *pflag.FlagSet, defaultValueAndDescription ...string) {
+	const (
+		mspDirDescription = "The path of the MSP directory of the org, holding cacerts, tlscacerts, admincerts and config.yaml"
+		defaultMSPDir     = ""
+	)
+	defaultValue, description := GetDefaultValueAndDescription(defaultMSPDir, mspDirDescription, defaultValueAndDescription...)
+	flags.String(MSPDirFlag, defaultValue, description)
+}
+
+const MSPIDFlag = "mspid"
+
+// InitMSPID initializes the MSP ID of an org from the provided arguments
+func InitMSPID(flags *pflag.FlagSet, defaultValueAndDescription ...string) {
+	const (
+		mspIDDescription = "The MSP ID of the org, e.g. Org3MSP"
+		defaultMSPID     = ""
+	)
+	defaultValue, description := GetDefaultValueAndDescription(defaultMSPID, mspIDDescription, defaultValueAndDescription...)
+	flags.String(MSPIDFlag, defaultValue, description)
+}
+
 func GetDefaultValueAndDescription(defaultValue string, defaultDescription string, overrides ...string) (value, description string) {
 	if len(overrides) > 0 {
 		value = overrides[0]
@@ -13,6 +13,8 @@ import (
 	"testing"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/zhcppy/fabricli/api"
 	"github.com/zhcppy/fabricli/api/chaincode"
 	"github.com/zhcppy/fabricli/api/channel"
 	"github.com/zhcppy/fabricli/api/event"
@@ -32,6 +34,9 @@ func NewRootCmd() *cobra.Command {
 		Short: "Fabric Client",
 		Long:  ``,
 
+		// errors are printed by the caller of Execute
+		SilenceErrors: true,
+		SilenceUsage:  true,
 		Run: func(cmd *cobra.Command, args []string) {
 			cmd.HelpFunc()(cmd, args)
 		},
@@ -48,7 +53,11 @@ func NewRootCmd() *cobra.Command {
 	cmd.InitSelectionProvider(flags)
 	cmd.InitOrdererTLSCertificate(flags)
 
-	rootCmd.AddCommand(console.NewCmd())
+	rootCmd.AddCommand(console.NewCmd(func(flags *pflag.FlagSet) console.Namespaces {
+		config := api.ConfigFlags(flags)
+		return console.Namespaces{query.Namespace(config), chaincode.Namespace(config), channel.Namespace(config), event.Namespace(config)}.
+			WithValues(config.CompletionValues())
+	}))
 	rootCmd.AddCommand(query.NewCmd())
 	rootCmd.AddCommand(event.NewCmd())
 	rootCmd.AddCommand(channel.NewCmd())
@@ -72,7 +81,9 @@ func run(cmd string) {
 	_ = os.Setenv("GRPC_TRACE", "all")
 	_ = os.Setenv("GRPC_VERBOSITY", "DEBUG")
 	_ = os.Setenv("GRPC_GO_LOG_SEVERITY_LEVEL", "INFO")
-	_ = NewRootCmd().Execute()
+	if err := NewRootCmd().Execute(); err != nil {
+		fmt.Println(err.Error())
+	}
 }
 
 func header(h string) {
@@ -6,17 +6,45 @@
 package console
 
 import (
+	"strings"
+
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/zhcppy/fabricli/cmd"
 )
 
-func NewCmd() *cobra.Command {
+// NewCmd returns the console command, namespaces returns the namespaces of the console for the command flags.
+// They are given by the caller since the actions exposed in the console depend on this package
+func NewCmd(namespaces func(flags *pflag.FlagSet) Namespaces) *cobra.Command {
 	consoleCmd := &cobra.Command{
 		Use:   "console",
 		Short: "Fabric command-line console implemented by Golang",
-		Long:  "",
-		Run: func(cmd *cobra.Command, args []string) {
-			cmd.HelpFunc()(cmd, args)
+		Long: "Interactive console calling the methods of the actions by namespace: " +
+			"q (query), cc (chaincode), ch (channel) and ev (event)",
+		Example: strings.Join([]string{
+			"> help",
+			"> help QueryBlock",
+			"> q.BlockHeight()",
+			"> q.QueryBlock(1)",
+			"> cc.QueryInfo(mycc, {\"Func\":\"query\",\"Args\":[\"A\"]})",
+			"> cc.Invoke(mycc, {\"Func\":\"move\",\"Args\":[\"A\",\"B\",\"1\"]})",
+			"> $h = q.BlockHeight()",
+			"> $b = q.QueryBlock(1)",
+			"> $b.Header.Number",
+			"console --exec runbook.fcli",
+		}, "\n"),
+		RunE: func(c *cobra.Command, args []string) error {
+			console, err := New(namespaces(c.Flags()))
+			if err != nil {
+				return err
+			}
+			if file, _ := c.Flags().GetString(cmd.ExecFileFlag); file != "" {
+				return console.ExecuteFile(file)
+			}
+			console.Interactive()
+			return nil
 		},
 	}
+	cmd.InitExecFile(consoleCmd.Flags())
 	return consoleCmd
 }
@@ -0,0 +1,307 @@
+package console
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Completer is implemented by the executors completing the input line themselves instead of from a list of words
+type Completer interface {
+	Complete(line string, pos int) (head string, completions []string, tail string)
+}
+
+var (
+	help           = regexp.MustCompile(`^\s*help(\s+([A-Za-z_][A-Za-z0-9_.]*))?\s*;?\s*$`)
+	helpHead       = regexp.MustCompile(`^\s*help\s+`)
+	assignmentHead = regexp.MustCompile(`^\s*\$[A-Za-z_][A-Za-z0-9_]*\s*=\s*`)
+)
+
+// Signature returns the signature of the method with the names of its parameters, e.g. q.QueryBlock(blockNumber uint64)
+func (ns Namespace) Signature(method string) string {
+	m, ok := ns.Type.MethodByName(method)
+	if !ok {
+		return ""
+	}
+	doc := ns.Docs[method]
+	var params []string
+	for i := 1; i < m.Type.NumIn(); i++ {
+		in, variadic := m.Type.In(i), m.Type.IsVariadic() && i == m.Type.NumIn()-1
+		if variadic && !convertible(in.Elem()) {
+			continue
+		}
+		typeName := in.String()
+		if variadic {
+			typeName = "..." + in.Elem().String()
+		}
+		if i-1 < len(doc.Params) {
+			typeName = doc.Params[i-1] + " " + typeName
+		}
+		params = append(params, typeName)
+	}
+	return fmt.Sprintf("%s.%s(%s)", ns.Name, method, strings.Join(params, ", "))
+}
+
+// param returns the name and the type of the nth parameter of the method, the variadic
+// parameter stands for all the parameters after it
+func (ns Namespace) param(method reflect.Method, n int) (string, reflect.Type, bool) {
+	numIn := method.Type.NumIn() - 1
+	if method.Type.IsVariadic() && n >= numIn-1 {
+		n = numIn - 1
+		if params := ns.Docs[method.Name].Params; n < len(params) {
+			return params[n], method.Type.In(n + 1).Elem(), true
+		}
+		return "", method.Type.In(n + 1).Elem(), true
+	}
+	if n >= numIn {
+		return "", nil, false
+	}
+	if params := ns.Docs[method.Name].Params; n < len(params) {
+		return params[n], method.Type.In(n + 1), true
+	}
+	return "", method.Type.In(n + 1), true
+}
+
+// values returns the values completed for the parameter, the plural names such as
+// channelIDs take the values of the singular
+func (ns Namespace) values(param string) []string {
+	if values, ok := ns.Values[param]; ok {
+		return values
+	}
+	return ns.Values[strings.TrimSuffix(param, "s")]
+}
+
+// WithValues returns the namespaces completing the arguments with the values by parameter name
+func (ns Namespaces) WithValues(values map[string][]string) Namespaces {
+	namespaces := make(Namespaces, len(ns))
+	for i, n := range ns {
+		n.Values = values
+		namespaces[i] = n
+	}
+	return namespaces
+}
+
+// Complete completes the method names, listing their signatures when several of them match,
+// then the arguments with the values of their parameter. The help built-in completes the method names
+func (ns Namespaces) Complete(line string, pos int) (head string, completions []string, tail string) {
+	runes := []rune(line)
+	if pos > len(runes) {
+		pos = len(runes)
+	}
+	before, tail := string(runes[:pos]), string(runes[pos:])
+
+	if match := helpHead.FindString(before); match != "" {
+		for _, method := range ns.match(before[len(match):]) {
+			completions = append(completions, method.name)
+		}
+		return match, completions, tail
+	}
+	if match := assignmentHead.FindString(before); match != "" {
+		head, before = match, before[len(match):]
+	}
+
+	open := strings.Index(before, "(")
+	if open < 0 {
+		prefix := strings.TrimLeft(before, " ")
+		head += before[:len(before)-len(prefix)]
+		methods := ns.match(prefix)
+		if len(methods) == 1 {
+			return head, []string{methods[0].name + "("}, tail
+		}
+		for _, method := range methods {
+			completions = append(completions, method.signature)
+		}
+		if strings.TrimSpace(head) == "" && strings.HasPrefix("help", prefix) {
+			completions = append(completions, "help")
+		}
+		return head, completions, tail
+	}
+
+	field, method := "", strings.TrimSpace(before[:open])
+	if dot := strings.Index(method, "."); dot >= 0 {
+		field, method = method[:dot], method[dot+1:]
+	}
+	n, ok := ns.namespace(field)
+	if !ok {
+		return head, nil, tail
+	}
+	m, ok := n.Type.MethodByName(method)
+	if !ok || !callable(m) {
+		return head, nil, tail
+	}
+	index, start := currentArg(before[open+1:])
+	partial := strings.TrimLeft(before[open+1+start:], " ")
+	name, in, ok := n.param(m, index)
+	if !ok {
+		return head, nil, tail
+	}
+	argHead := head + before[:len(before)-len(partial)]
+	switch {
+	case in.Kind() == reflect.String:
+		for _, value := range n.values(name) {
+			if strings.HasPrefix(value, partial) {
+				completions = append(completions, value)
+			}
+		}
+		return argHead, completions, tail
+	case in.Kind() == reflect.Slice && in.Elem().Kind() == reflect.String:
+		argHead, completions = n.completeElement(argHead, partial, name)
+		return argHead, completions, tail
+	}
+	return head, nil, tail
+}
+
+// completeElement completes the element being typed in the JSON array of a string slice parameter,
+// e.g. ["grpcs://localhost:7051", "grpcs://lo, an empty argument starts the array
+func (ns Namespace) completeElement(head, partial, param string) (string, []string) {
+	open := ""
+	if partial == "" {
+		open = "["
+	} else if !strings.HasPrefix(partial, "[") {
+		return head, nil
+	}
+	element := strings.TrimLeft(partial[elementStart(partial):], " ")
+	head += partial[:len(partial)-len(element)]
+	element = strings.TrimPrefix(element, `"`)
+
+	var completions []string
+	for _, value := range ns.values(param) {
+		if strings.HasPrefix(value, element) {
+			completions = append(completions, open+strconv.Quote(value))
+		}
+	}
+	return head, completions
+}
+
+// elementStart returns the offset of the element being typed in the JSON array,
+// after the last comma or opening bracket outside of quotes
+func elementStart(array string) (start int) {
+	var quoted, escaped bool
+	for i, c := range array {
+		switch {
+		case escaped:
+			escaped = false
+		case quoted:
+			if c == '\\' {
+				escaped = true
+			} else if c == '"' {
+				quoted = false
+			}
+		case c == '"':
+			quoted = true
+		case c == ',' || c == '[':
+			start = i + 1
+		}
+	}
+	return start
+}
+
+type methodMatch struct {
+	name, signature string
+}
+
+// match returns the methods starting with the prefix, case insensitive. The names include
+// the namespace unless there is a single namespace
+func (ns Namespaces) match(prefix string) (methods []methodMatch) {
+	prefix = strings.ToLower(prefix)
+	for _, n := range ns {
+		for _, method := range n.Methods() {
+			name := n.Name + "." + method
+			if len(ns) == 1 {
+				name = method
+			}
+			if strings.HasPrefix(strings.ToLower(name), prefix) {
+				signature := n.Signature(method)
+				if len(ns) == 1 {
+					signature = strings.TrimPrefix(signature, n.Name+".")
+				}
+				methods = append(methods, methodMatch{name: name, signature: signature})
+			}
+		}
+	}
+	return methods
+}
+
+func (ns Namespaces) namespace(field string) (Namespace, bool) {
+	for _, n := range ns {
+		if n.Name == field || (field == "" && len(ns) == 1) {
+			return n, true
+		}
+	}
+	return Namespace{}, false
+}
+
+// currentArg returns the index of the argument being typed and its offset in the args,
+// the commas inside quotes, brackets and parentheses do not separate the arguments
+func currentArg(args string) (index, start int) {
+	var depth int
+	var quote rune
+	var escaped bool
+	for i, c := range args {
+		switch {
+		case escaped:
+			escaped = false
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				escaped = true
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '(' || c == '[' || c == '{':
+			depth++
+		case c == ')' || c == ']' || c == '}':
+			depth--
+		case c == ',' && depth == 0:
+			index, start = index+1, i+1
+		}
+	}
+	return index, start
+}
+
+// printHelp prints the signature, the description and the example of the methods matching
+// the name, a method name alone matches the method in every namespace. No name lists all the methods
+func (ns Namespaces) printHelp(name string) error {
+	var found bool
+	for _, n := range ns {
+		for _, method := range n.Methods() {
+			if name != "" && name != method && name != n.Name+"."+method {
+				continue
+			}
+			found = true
+			m, _ := n.Type.MethodByName(method)
+			var results []string
+			for i := 0; i < m.Type.NumOut(); i++ {
+				results = append(results, m.Type.Out(i).String())
+			}
+			doc := n.Docs[method]
+			if name == "" {
+				fmt.Printf("%-60s %s\n", n.Signature(method), firstSentence(doc.Description))
+				continue
+			}
+			fmt.Printf("%s (%s)\n", n.Signature(method), strings.Join(results, ", "))
+			if doc.Description != "" {
+				fmt.Printf("  %s\n", doc.Description)
+			}
+			if doc.Example != "" {
+				fmt.Printf("  Example: %s\n", doc.Example)
+			}
+		}
+	}
+	if !found {
+		return errors.Errorf("unknown method [%s], enter help to list the methods", name)
+	}
+	return nil
+}
+
+func firstSentence(description string) string {
+	if i := strings.Index(description, ". "); i >= 0 {
+		return description[:i+1]
+	}
+	return description
+}
@@ -0,0 +1,83 @@
+package console
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func testDocumentedNamespaces() Namespaces {
+	namespaces := testNamespaces(new(int), &testAction{}, &testAction{})
+	for i := range namespaces {
+		namespaces[i].Docs = map[string]Doc{
+			"Echo":  {Params: []string{"channelID"}, Description: "Returns the text. It is printed", Example: "a.Echo(x)"},
+			"Add":   {Params: []string{"peerURL", "chaincodeIDs"}},
+			"Block": {Params: []string{"number"}},
+		}
+	}
+	return namespaces.WithValues(map[string][]string{
+		"channelID":   {"mychannel", "otherchannel"},
+		"peerURL":     {"grpc://localhost:7051", "grpc://localhost:9051"},
+		"chaincodeID": {"marbles", "mycc"},
+	})
+}
+
+func TestNamespace_Signature(t *testing.T) {
+	ns := testDocumentedNamespaces()[0]
+	for method, expected := range map[string]string{
+		"Echo":  "a.Echo(channelID string)",
+		"Add":   "a.Add(peerURL string, chaincodeIDs string)",
+		"Count": "a.Count(int)",
+	} {
+		if signature := ns.Signature(method); signature != expected {
+			t.Fatalf("expecting signature %s, got %s", expected, signature)
+		}
+	}
+	for _, method := range ns.Methods() {
+		if method == "Scan" {
+			t.Fatal("expecting the methods taking a function not to be callable")
+		}
+	}
+}
+
+func TestNamespaces_Complete(t *testing.T) {
+	namespaces := testDocumentedNamespaces()
+	for _, tc := range []struct {
+		line        string
+		head        string
+		completions []string
+	}{
+		{"a.E", "", []string{"a.Echo("}},
+		{"  b.bl", "  ", []string{"b.Block("}},
+		{"$x = b.Bl", "$x = ", []string{"b.Block("}},
+		{"a.", "", []string{"a.Add(peerURL string, chaincodeIDs string)", "a.Block(number uint64)", "a.Count(int)", "a.Echo(channelID string)", "a.Fail()"}},
+		{"he", "", []string{"help"}},
+		{"help b.", "help ", []string{"b.Add", "b.Block", "b.Count", "b.Echo", "b.Fail"}},
+		{"a.Echo(", "a.Echo(", []string{"mychannel", "otherchannel"}},
+		{"a.Echo( my", "a.Echo( ", []string{"mychannel"}},
+		{"a.Add(grpc://localhost:9", "a.Add(", []string{"grpc://localhost:9051"}},
+		{`a.Add("x,(y", m`, `a.Add("x,(y", `, []string{"marbles", "mycc"}},
+		{"a.Block(", "", nil},
+		{"c.Echo(", "", nil},
+	} {
+		head, completions, tail := namespaces.Complete(tc.line+")", len([]rune(tc.line)))
+		if head != tc.head || !reflect.DeepEqual(completions, tc.completions) || tail != ")" {
+			t.Fatalf("line [%s]: unexpected completion [%s] %v [%s]", tc.line, head, completions, tail)
+		}
+	}
+}
+
+func TestNamespaces_Help(t *testing.T) {
+	handler, err := testDocumentedNamespaces().NewHandler()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, input := range []string{"help", "help Echo", "help b.Echo", " help a.Count ;"} {
+		if err := handler.RunCommand(input); err != nil {
+			t.Fatalf("input [%s]: %v", input, err)
+		}
+	}
+	if err := handler.RunCommand("help Unknown"); err == nil || !strings.Contains(err.Error(), "unknown method [Unknown]") {
+		t.Fatalf("expecting an unknown method, got %v", err)
+	}
+}
@@ -1,17 +1,19 @@
 package console
 
 import (
+	"bufio"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"os/signal"
 	"path/filepath"
-	"reflect"
 	"regexp"
 	"strings"
 	"syscall"
 
 	"github.com/mitchellh/go-homedir"
+	"github.com/pkg/errors"
 )
 
 var (
@@ -72,7 +74,11 @@ func New(exec Executor, opts ...func(*Console)) (c *Console, err error) {
 		console.history = strings.Split(string(content), "\n")
 		console.prompter.SetHistory(console.history)
 	}
-	console.SetWordCompleter(exec.WordCompleter())
+	if completer, ok := exec.(Completer); ok {
+		console.prompter.SetWordCompleter(completer.Complete)
+	} else {
+		console.SetWordCompleter(exec.WordCompleter())
+	}
 	return console, nil
 }
 
@@ -157,7 +163,7 @@ func (c *Console) Interactive() {
 				continue
 			}
 			if err := c.Execute(input); err != nil {
-				fmt.Printf("input:[ %s ], execute err:%s", input, err.Error())
+				fmt.Printf("input:[ %s ], execute err:%s\n", input, err.Error())
 			}
 		}
 	}
@@ -175,11 +181,47 @@ func (c *Console) Execute(input string) error {
 			}
 		}
 	}
+	defer appendHistory()
+	return c.run(input)
+}
+
+// ExecuteFile runs the commands of the script file, see RunScript. Like Interactive, it closes the handler when done
+func (c *Console) ExecuteFile(file string) error {
+	defer c.handler.Close()
+	f, err := os.Open(file)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open script [%s]", file)
+	}
+	defer f.Close()
+	return c.RunScript(f)
+}
+
+// RunScript runs the commands read from r one per line, stopping at the first error or at exit.
+// The blank lines and the comments starting with # or // are skipped
+func (c *Console) RunScript(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		input := strings.TrimSpace(scanner.Text())
+		if onlyWhitespace.MatchString(input) || strings.HasPrefix(input, "#") || strings.HasPrefix(input, "//") {
+			continue
+		}
+		if exit.MatchString(input) {
+			return nil
+		}
+		fmt.Println(c.prompt + input)
+		if err := c.run(input); err != nil {
+			return errors.WithMessagef(err, "line %d", line)
+		}
+	}
+	return scanner.Err()
+}
+
+// run runs the command, a panic of the handler is returned as an error
+func (c *Console) run(input string) (err error) {
 	defer func() {
 		if r := recover(); r != nil {
-			fmt.Printf("input:[ %s ], fatal err: %v\n", input, r)
-		} else {
-			appendHistory()
+			err = errors.Errorf("fatal err: %v", r)
 		}
 	}()
 	return c.handler.RunCommand(input)
@@ -215,27 +257,3 @@ func (c *Console) ClearHistory() {
 		fmt.Println("history file deleted")
 	}
 }
-
-func ParseInputData(input string) (field, method string, params []reflect.Value) {
-	var t int
-
-	for i, k := range input {
-		if k == '.' {
-			field = input[t:i]
-			t = i + 1
-			continue
-		}
-		if k == '(' {
-			method = input[t:i]
-			t = i + 1
-			continue
-		}
-		if k == ',' || k == ')' {
-			if param := input[t:i]; param != "" {
-				params = append(params, reflect.ValueOf(param))
-				t = i + 1
-			}
-		}
-	}
-	return
-}
@@ -0,0 +1,40 @@
+package console
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestConsole_RunScript(t *testing.T) {
+	a := &testAction{}
+	handler, err := testNamespaces(new(int), a).NewHandler()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &Console{prompt: DefaultPrompt, handler: handler}
+
+	script := `# height of the channel
+$b = Block(3)
+
+// the commands can refer to the variables
+Echo($b.Number);
+exit
+Echo(skipped)`
+	if err := c.RunScript(strings.NewReader(script)); err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"3", "3"}; !reflect.DeepEqual(a.calls, expected) {
+		t.Fatalf("expecting calls %v, got %v", expected, a.calls)
+	}
+
+	// the script stops at the first error
+	a.calls = nil
+	err = c.RunScript(strings.NewReader("Echo(x)\nFail()\nEcho(y)"))
+	if err == nil || err.Error() != "line 2: failed" {
+		t.Fatalf("expecting the error of line 2, got %v", err)
+	}
+	if expected := []string{"x"}; !reflect.DeepEqual(a.calls, expected) {
+		t.Fatalf("expecting calls %v, got %v", expected, a.calls)
+	}
+}
@@ -0,0 +1,225 @@
+package console
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// Namespace exposes the methods of an action to the console under a short name,
+// e.g. the methods of the query action are called with q.QueryBlock(1)
+type Namespace struct {
+	Name string
+	// Type is the type of the action, its methods are completed before the action is created
+	Type reflect.Type
+	// New creates the action on the first call of one of its methods,
+	// so that the console only connects the clients it uses
+	New func() (interface{}, error)
+	// Docs documents the methods by name for the completion and the help
+	Docs map[string]Doc
+	// Values are the values completed for the arguments by parameter name, e.g. the channel IDs for channelID
+	Values map[string][]string
+}
+
+// Doc documents a method of a namespace
+type Doc struct {
+	// Params are the names of the parameters of the method
+	Params      []string
+	Description string
+	Example     string
+}
+
+// Methods returns the names of the methods of the namespace callable from the console,
+// the exported methods returning an error last
+func (ns Namespace) Methods() (methods []string) {
+	for i := 0; i < ns.Type.NumMethod(); i++ {
+		if method := ns.Type.Method(i); callable(method) {
+			methods = append(methods, method.Name)
+		}
+	}
+	sort.Strings(methods)
+	return methods
+}
+
+// callable reports whether the arguments of the console convert to the parameters of the method,
+// a variadic parameter which does not convert is left out of the calls
+func callable(method reflect.Method) bool {
+	if method.PkgPath != "" || method.Name == "Close" {
+		return false
+	}
+	numOut := method.Type.NumOut()
+	if numOut == 0 || method.Type.Out(numOut-1) != errorType {
+		return false
+	}
+	for i := 1; i < method.Type.NumIn(); i++ {
+		if !convertible(method.Type.In(i)) && !(method.Type.IsVariadic() && i == method.Type.NumIn()-1) {
+			return false
+		}
+	}
+	return true
+}
+
+// Namespaces is an Executor running each command in the namespace given by its field,
+// the field can be left out when there is a single namespace
+type Namespaces []Namespace
+
+func (ns Namespaces) NewHandler() (Handler, error) {
+	if len(ns) == 0 {
+		return nil, errors.New("no namespace given")
+	}
+	return &namespaceHandler{namespaces: ns, actions: map[string]reflect.Value{}, vars: variables{}}, nil
+}
+
+func (ns Namespaces) WordCompleter() (words []string) {
+	if len(ns) == 1 {
+		for _, method := range ns[0].Methods() {
+			words = append(words, method+"()")
+		}
+		return words
+	}
+	for _, n := range ns {
+		words = append(words, n.Name)
+		for _, method := range n.Methods() {
+			words = append(words, n.Name+"."+method+"()")
+		}
+	}
+	return words
+}
+
+func (ns Namespaces) names() (names []string) {
+	for _, n := range ns {
+		names = append(names, n.Name)
+	}
+	return names
+}
+
+type namespaceHandler struct {
+	namespaces Namespaces
+	actions    map[string]reflect.Value
+	vars       variables
+}
+
+// action returns the action of the namespace, creating it on first use
+func (h *namespaceHandler) action(field string) (Namespace, reflect.Value, error) {
+	ns, ok := h.namespaces.namespace(field)
+	if !ok {
+		if field == "" {
+			return Namespace{}, reflect.Value{}, errors.Errorf("expecting a namespace, one of %s", strings.Join(h.namespaces.names(), ", "))
+		}
+		return Namespace{}, reflect.Value{}, errors.Errorf("unknown namespace [%s], expecting one of %s", field, strings.Join(h.namespaces.names(), ", "))
+	}
+	if action, ok := h.actions[ns.Name]; ok {
+		return ns, action, nil
+	}
+	action, err := ns.New()
+	if err != nil {
+		return ns, reflect.Value{}, errors.WithMessagef(err, "failed to create the [%s] action", ns.Name)
+	}
+	h.actions[ns.Name] = reflect.ValueOf(action)
+	return ns, h.actions[ns.Name], nil
+}
+
+// RunCommand calls the method of the command, printing its results. The command can assign its first result
+// to a variable ($b = q.QueryBlock(1)), and the arguments can refer to the variables ($b.Header.Number).
+// A variable reference alone prints its value, help prints the documentation of the methods
+func (h *namespaceHandler) RunCommand(input string) error {
+	if match := help.FindStringSubmatch(input); match != nil {
+		return h.namespaces.printHelp(match[2])
+	}
+	input = strings.TrimSuffix(strings.TrimSpace(input), ";")
+	if reference.MatchString(input) {
+		value, err := h.vars.lookup(input)
+		if err != nil {
+			return err
+		}
+		printValue(1, value)
+		return nil
+	}
+	var name string
+	if match := assignment.FindStringSubmatch(input); match != nil {
+		name, input = match[1], match[2]
+	}
+
+	field, method, params, err := ParseInputData(input)
+	if err != nil {
+		return errors.WithMessage(err, "expecting a method call such as q.QueryBlock(1)")
+	}
+	ns, action, err := h.action(field)
+	if err != nil {
+		return err
+	}
+	m, ok := ns.Type.MethodByName(method)
+	if !ok || !callable(m) {
+		return errors.Errorf("unknown method [%s.%s]", ns.Name, method)
+	}
+	args, err := h.callArgs(m, params)
+	if err != nil {
+		return errors.WithMessagef(err, "%s.%s", ns.Name, method)
+	}
+	values := action.MethodByName(method).Call(args)
+	if err := values[len(values)-1]; !err.IsNil() {
+		return err.Interface().(error)
+	}
+	if name != "" {
+		if len(values) < 2 {
+			return errors.Errorf("%s.%s returns no result to assign to $%s", ns.Name, method, name)
+		}
+		h.vars[name] = values[0]
+	}
+	for i := 0; i < len(values)-1; i++ {
+		printValue(i+1, values[i])
+	}
+	return nil
+}
+
+// callArgs converts the params to the parameters of the method, the receiver excluded
+func (h *namespaceHandler) callArgs(method reflect.Method, params []Arg) ([]reflect.Value, error) {
+	methodType := method.Type
+	numIn := methodType.NumIn() - 1
+	if methodType.IsVariadic() {
+		numIn--
+	}
+	if len(params) < numIn || (!methodType.IsVariadic() && len(params) > numIn) {
+		return nil, errors.Errorf("expecting %d argument(s), got %d", numIn, len(params))
+	}
+	var args []reflect.Value
+	for i, param := range params {
+		in := methodType.In(i + 1)
+		if methodType.IsVariadic() && i >= numIn {
+			in = methodType.In(methodType.NumIn() - 1).Elem()
+			if !convertible(in) {
+				return nil, errors.Errorf("expecting %d argument(s), got %d", numIn, len(params))
+			}
+		}
+		var arg reflect.Value
+		var err error
+		if param.Kind == VarArg {
+			if arg, err = h.vars.lookup(param.Text); err == nil {
+				arg, err = convert(arg, in)
+			}
+		} else {
+			arg, err = param.Convert(in)
+		}
+		if err != nil {
+			return nil, errors.WithMessagef(err, "argument [%d]", i)
+		}
+		args = append(args, arg)
+	}
+	return args, nil
+}
+
+// Close closes the actions created by the console
+func (h *namespaceHandler) Close() {
+	for _, action := range h.actions {
+		switch closer := action.Interface().(type) {
+		case interface{ Close() }:
+			closer.Close()
+		case interface{ Close() error }:
+			closer.Close()
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package console
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type testAction struct {
+	calls  []string
+	closed bool
+}
+
+func (a *testAction) Echo(s string) (string, error) {
+	a.calls = append(a.calls, s)
+	return s, nil
+}
+
+func (a *testAction) Add(a1, a2 string) error {
+	a.calls = append(a.calls, a1+a2)
+	return nil
+}
+
+func (a *testAction) Count(n int) error {
+	return nil
+}
+
+type testBlock struct {
+	Number uint64
+	TxIDs  []string
+	Meta   map[string]string
+	next   *testBlock
+}
+
+func (a *testAction) Block(number uint64) (*testBlock, error) {
+	a.calls = append(a.calls, strconv.FormatUint(number, 10))
+	return &testBlock{Number: number, TxIDs: []string{"tx1", "tx2"}, Meta: map[string]string{"org": "Org1"}}, nil
+}
+
+func (a *testAction) Fail() error {
+	return errors.New("failed")
+}
+
+func (a *testAction) Scan(fn func(number uint64) error) error {
+	return nil
+}
+
+func (a *testAction) Done() bool {
+	return true
+}
+
+func (a *testAction) Close() {
+	a.closed = true
+}
+
+func testNamespaces(created *int, actions ...*testAction) Namespaces {
+	var namespaces Namespaces
+	for i, name := range []string{"a", "b"}[:len(actions)] {
+		action := actions[i]
+		namespaces = append(namespaces, Namespace{
+			Name: name,
+			Type: reflect.TypeOf(action),
+			New: func() (interface{}, error) {
+				*created++
+				return action, nil
+			},
+		})
+	}
+	return namespaces
+}
+
+func TestNamespaces_WordCompleter(t *testing.T) {
+	words := testNamespaces(new(int), &testAction{}, &testAction{}).WordCompleter()
+	expected := []string{"a", "a.Add()", "a.Block()", "a.Count()", "a.Echo()", "a.Fail()", "b", "b.Add()", "b.Block()", "b.Count()", "b.Echo()", "b.Fail()"}
+	if !reflect.DeepEqual(words, expected) {
+		t.Fatalf("expecting words %v, got %v", expected, words)
+	}
+	// the field is left out with a single namespace
+	words = testNamespaces(new(int), &testAction{}).WordCompleter()
+	if expected := []string{"Add()", "Block()", "Count()", "Echo()", "Fail()"}; !reflect.DeepEqual(words, expected) {
+		t.Fatalf("expecting words %v, got %v", expected, words)
+	}
+}
+
+func TestNamespaces_RunCommand(t *testing.T) {
+	var created int
+	a, b := &testAction{}, &testAction{}
+	handler, err := testNamespaces(&created, a, b).NewHandler()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, input := range []string{"a.Echo(x)", "b.Add(x,y)", "a.Echo(z)", "a.Count(1)"} {
+		if err := handler.RunCommand(input); err != nil {
+			t.Fatalf("input [%s]: %v", input, err)
+		}
+	}
+	if !reflect.DeepEqual(a.calls, []string{"x", "z"}) || !reflect.DeepEqual(b.calls, []string{"xy"}) {
+		t.Fatalf("unexpected calls %v, %v", a.calls, b.calls)
+	}
+	if created != 2 {
+		t.Fatalf("expecting the actions to be created once, created %d", created)
+	}
+
+	for _, tc := range []struct {
+		input, err string
+	}{
+		{"Echo(x)", "expecting a namespace"},
+		{"c.Echo(x)", "unknown namespace [c]"},
+		{"a.Done()", "unknown method [a.Done]"},
+		{"a.Close()", "unknown method [a.Close]"},
+		{"a.Add(x)", "expecting 2 argument(s), got 1"},
+		{"a.Count(x)", "argument [0]: cannot convert word x to int"},
+		{"a", "expecting a method call"},
+	} {
+		if err := handler.RunCommand(tc.input); err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Fatalf("input [%s]: expecting error [%s], got %v", tc.input, tc.err, err)
+		}
+	}
+
+	handler.Close()
+	if !a.closed || !b.closed {
+		t.Fatal("expecting the actions to be closed")
+	}
+}
+
+func TestNamespaces_Variables(t *testing.T) {
+	a := &testAction{}
+	handler, err := testNamespaces(new(int), a).NewHandler()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, input := range []string{
+		"$b = Block(7)",
+		"$b",
+		"$n = Echo($b.Number)",
+		"Block($n)",
+		"Echo($b.TxIDs[1])",
+		"Echo($b.Meta.org)",
+		"Echo($b.TxIDs)",
+	} {
+		if err := handler.RunCommand(input); err != nil {
+			t.Fatalf("input [%s]: %v", input, err)
+		}
+	}
+	if expected := []string{"7", "7", "7", "tx2", "Org1", `["tx1","tx2"]`}; !reflect.DeepEqual(a.calls, expected) {
+		t.Fatalf("expecting calls %v, got %v", expected, a.calls)
+	}
+
+	for _, tc := range []struct {
+		input, err string
+	}{
+		{"$x", "variable $x not set"},
+		{"Echo($x)", "argument [0]: variable $x not set"},
+		{"Echo($b.Hash)", "no field Hash"},
+		{"Echo($b.next)", "no field next"},
+		{"Echo($b.TxIDs[2])", "out of range"},
+		{"Echo($b[0])", "is not a list"},
+		{"Echo($b.Meta.peer)", "no key peer"},
+		{"Echo($b.Number.Value)", "uint64 has no fields"},
+		{"Block($b.TxIDs[0])", "cannot convert string tx1 to uint64"},
+		{"Count($b.TxIDs)", "cannot convert JSON [\"tx1\",\"tx2\"] to int"},
+		{"$c = Add(x, y)", "returns no result to assign to $c"},
+	} {
+		if err := handler.RunCommand(tc.input); err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Fatalf("input [%s]: expecting error [%s], got %v", tc.input, tc.err, err)
+		}
+	}
+}
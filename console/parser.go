@@ -0,0 +1,348 @@
+package console
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+// ArgKind is the kind of an argument of a console command
+type ArgKind int
+
+const (
+	// WordArg is an unquoted text such as mycc or key$rand(10)
+	WordArg ArgKind = iota
+	// StringArg is a text quoted with "" (Go escapes) or '' (no escapes)
+	StringArg
+	NumberArg
+	BoolArg
+	// JSONArg is a JSON object or array
+	JSONArg
+	// VarArg is a reference to the result of a previous command such as $b or $b.Header.Number
+	VarArg
+)
+
+func (k ArgKind) String() string {
+	switch k {
+	case StringArg:
+		return "string"
+	case NumberArg:
+		return "number"
+	case BoolArg:
+		return "bool"
+	case JSONArg:
+		return "JSON"
+	case VarArg:
+		return "variable"
+	default:
+		return "word"
+	}
+}
+
+// Arg is an argument of a console command, it is converted to the type of the parameter of the method
+type Arg struct {
+	Kind ArgKind
+	// Text is the unquoted text of a string, the literal text of the other kinds. The variables
+	// are resolved by the handler before the conversion
+	Text string
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// Convert converts the argument to a value of the given type. Strings take any argument as text,
+// numbers and bools take the numbers and bools, structs, maps and slices take the JSON arguments
+// or strings of JSON. Byte slices take any argument as text too
+func (arg Arg) Convert(t reflect.Type) (reflect.Value, error) {
+	value := reflect.New(t).Elem()
+	switch {
+	case t.Kind() == reflect.String:
+		value.SetString(arg.Text)
+		return value, nil
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		value.SetBytes([]byte(arg.Text))
+		return value, nil
+	case arg.Kind == StringArg || arg.Kind == JSONArg:
+		if arg.Kind == JSONArg || t.Kind() == reflect.Struct || t.Kind() == reflect.Map || t.Kind() == reflect.Slice || t.Kind() == reflect.Ptr {
+			return arg.unmarshal(t)
+		}
+		return value, arg.convertError(t)
+	}
+
+	var err error
+	switch t.Kind() {
+	case reflect.Bool:
+		if arg.Kind != BoolArg {
+			return value, arg.convertError(t)
+		}
+		value.SetBool(arg.Text == "true")
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i int64
+		if t == durationType && arg.Kind == WordArg {
+			var d time.Duration
+			d, err = time.ParseDuration(arg.Text)
+			i = int64(d)
+		} else {
+			i, err = strconv.ParseInt(arg.Text, 0, t.Bits())
+		}
+		if err == nil {
+			value.SetInt(i)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var u uint64
+		if u, err = strconv.ParseUint(arg.Text, 0, t.Bits()); err == nil {
+			value.SetUint(u)
+		}
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		if f, err = strconv.ParseFloat(arg.Text, t.Bits()); err == nil {
+			value.SetFloat(f)
+		}
+	default:
+		err = errors.New("unsupported parameter type")
+	}
+	if err != nil {
+		return value, arg.convertError(t)
+	}
+	return value, nil
+}
+
+// convertible reports whether the arguments convert to the type, the types holding
+// functions, channels or interfaces do not
+func convertible(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Func, reflect.Chan, reflect.Interface, reflect.UnsafePointer:
+		return false
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		return convertible(t.Elem())
+	case reflect.Map:
+		return convertible(t.Key()) && convertible(t.Elem())
+	}
+	return true
+}
+
+// unmarshal decodes the JSON argument, or the JSON text of a string
+func (arg Arg) unmarshal(t reflect.Type) (reflect.Value, error) {
+	ptr := reflect.New(t)
+	if err := json.Unmarshal([]byte(arg.Text), ptr.Interface()); err != nil {
+		return ptr.Elem(), errors.Wrapf(err, "cannot convert %s %s to %s", arg.Kind, arg.Text, t)
+	}
+	return ptr.Elem(), nil
+}
+
+func (arg Arg) convertError(t reflect.Type) error {
+	return errors.Errorf("cannot convert %s %s to %s", arg.Kind, arg.Text, t)
+}
+
+// ParseInputData parses a console command of the form field.Method(arg, ...), the field is optional.
+// The arguments are quoted strings, numbers, bools, JSON objects and arrays, or unquoted words which
+// end at the next comma or closing parenthesis outside of parentheses, e.g. Invoke(mycc, {"Func":"move"}, "a,b")
+func ParseInputData(input string) (field, method string, args []Arg, err error) {
+	p := &parser{input: strings.TrimSpace(input)}
+	p.input = strings.TrimSuffix(p.input, ";")
+
+	name := p.ident()
+	if name == "" {
+		return "", "", nil, p.errorf("expecting a method name")
+	}
+	if p.peek() == '.' {
+		p.pos++
+		field, name = name, p.ident()
+		if name == "" {
+			return "", "", nil, p.errorf("expecting a method name")
+		}
+	}
+	method = name
+	p.skipSpaces()
+	if p.peek() != '(' {
+		return "", "", nil, p.errorf("expecting '('")
+	}
+	p.pos++
+	if args, err = p.args(); err != nil {
+		return "", "", nil, err
+	}
+	if p.skipSpaces(); !p.eof() {
+		return "", "", nil, p.errorf("unexpected %q after ')'", p.input[p.pos:])
+	}
+	return field, method, args, nil
+}
+
+type parser struct {
+	input string
+	pos   int
+}
+
+func (p *parser) eof() bool {
+	return p.pos >= len(p.input)
+}
+
+func (p *parser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.input[p.pos]
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return errors.Errorf("column %d: %s", p.pos+1, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) skipSpaces() {
+	for !p.eof() && unicode.IsSpace(rune(p.input[p.pos])) {
+		p.pos++
+	}
+}
+
+func (p *parser) ident() string {
+	p.skipSpaces()
+	start := p.pos
+	for !p.eof() {
+		c := p.input[p.pos]
+		if c != '_' && !unicode.IsLetter(rune(c)) && !(p.pos > start && unicode.IsDigit(rune(c))) {
+			break
+		}
+		p.pos++
+	}
+	return p.input[start:p.pos]
+}
+
+// args parses the arguments up to the closing parenthesis
+func (p *parser) args() (args []Arg, err error) {
+	if p.skipSpaces(); p.peek() == ')' {
+		p.pos++
+		return nil, nil
+	}
+	for {
+		p.skipSpaces()
+		arg, err := p.arg()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+		p.skipSpaces()
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case ')':
+			p.pos++
+			return args, nil
+		case 0:
+			return nil, p.errorf("expecting ')'")
+		default:
+			return nil, p.errorf("expecting ',' or ')', got %q", p.peek())
+		}
+	}
+}
+
+func (p *parser) arg() (Arg, error) {
+	switch c := p.peek(); c {
+	case '"', '\'':
+		return p.quoted(c)
+	case '{', '[':
+		return p.json()
+	case ',', ')':
+		return Arg{}, p.errorf("expecting an argument")
+	}
+	return p.word()
+}
+
+func (p *parser) quoted(quote byte) (Arg, error) {
+	start := p.pos
+	for p.pos++; !p.eof(); p.pos++ {
+		switch p.input[p.pos] {
+		case '\\':
+			if quote == '"' {
+				p.pos++
+			}
+		case quote:
+			p.pos++
+			if quote == '\'' {
+				return Arg{Kind: StringArg, Text: p.input[start+1 : p.pos-1]}, nil
+			}
+			text, err := strconv.Unquote(p.input[start:p.pos])
+			if err != nil {
+				return Arg{}, errors.Errorf("invalid string %s", p.input[start:p.pos])
+			}
+			return Arg{Kind: StringArg, Text: text}, nil
+		}
+	}
+	p.pos = start
+	return Arg{}, p.errorf("unterminated string")
+}
+
+// json scans the JSON object or array up to its closing bracket, skipping the brackets in strings
+func (p *parser) json() (Arg, error) {
+	start := p.pos
+	var depth int
+	var inString bool
+	for ; !p.eof(); p.pos++ {
+		c := p.input[p.pos]
+		switch {
+		case inString && c == '\\':
+			p.pos++
+		case c == '"':
+			inString = !inString
+		case inString:
+		case c == '{' || c == '[':
+			depth++
+		case c == '}' || c == ']':
+			if depth--; depth == 0 {
+				p.pos++
+				text := p.input[start:p.pos]
+				if !json.Valid([]byte(text)) {
+					return Arg{}, errors.Errorf("invalid JSON %s", text)
+				}
+				return Arg{Kind: JSONArg, Text: text}, nil
+			}
+		}
+	}
+	p.pos = start
+	return Arg{}, p.errorf("unterminated JSON %s", p.input[start:])
+}
+
+// word scans an unquoted argument up to the next comma or closing parenthesis outside of parentheses
+func (p *parser) word() (Arg, error) {
+	start := p.pos
+	var depth int
+loop:
+	for ; !p.eof(); p.pos++ {
+		switch c := p.input[p.pos]; {
+		case c == '(':
+			depth++
+		case c == ')' && depth > 0:
+			depth--
+		case (c == ')' || c == ',') && depth == 0:
+			break loop
+		}
+	}
+	if depth > 0 {
+		return Arg{}, p.errorf("expecting ')'")
+	}
+	text := strings.TrimSpace(p.input[start:p.pos])
+	switch {
+	case text == "true" || text == "false":
+		return Arg{Kind: BoolArg, Text: text}, nil
+	case isNumber(text):
+		return Arg{Kind: NumberArg, Text: text}, nil
+	case reference.MatchString(text):
+		return Arg{Kind: VarArg, Text: text}, nil
+	}
+	return Arg{Kind: WordArg, Text: text}, nil
+}
+
+func isNumber(text string) bool {
+	if _, err := strconv.ParseInt(text, 0, 64); err == nil {
+		return true
+	}
+	if _, err := strconv.ParseUint(text, 0, 64); err == nil {
+		return true
+	}
+	// Inf and NaN are words
+	_, err := strconv.ParseFloat(text, 64)
+	return err == nil && !strings.ContainsAny(text, "iInN")
+}
@@ -0,0 +1,107 @@
+package console
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseInputData(t *testing.T) {
+	for _, tc := range []struct {
+		input  string
+		field  string
+		method string
+		args   []Arg
+	}{
+		{"BlockHeight()", "", "BlockHeight", nil},
+		{" q.QueryBlock( 12 ) ;", "q", "QueryBlock", []Arg{{NumberArg, "12"}}},
+		{`cc.Invoke(mycc, {"Func":"move","Args":["A","B)","1"]})`, "cc", "Invoke", []Arg{
+			{WordArg, "mycc"}, {JSONArg, `{"Func":"move","Args":["A","B)","1"]}`}}},
+		{`Echo("a,b\"c", 'd\e', true, -1.5, [1,2], key$rand(3,4))`, "", "Echo", []Arg{
+			{StringArg, `a,b"c`}, {StringArg, `d\e`}, {BoolArg, "true"}, {NumberArg, "-1.5"},
+			{JSONArg, "[1,2]"}, {WordArg, "key$rand(3,4)"}}},
+		{"Echo(NaN, 0x1f, 5m)", "", "Echo", []Arg{{WordArg, "NaN"}, {NumberArg, "0x1f"}, {WordArg, "5m"}}},
+	} {
+		field, method, args, err := ParseInputData(tc.input)
+		if err != nil {
+			t.Fatalf("input [%s]: %v", tc.input, err)
+		}
+		if field != tc.field || method != tc.method || !reflect.DeepEqual(args, tc.args) {
+			t.Fatalf("input [%s]: unexpected %s.%s %v", tc.input, field, method, args)
+		}
+	}
+}
+
+func TestParseInputData_Error(t *testing.T) {
+	for _, tc := range []struct {
+		input string
+		err   string
+	}{
+		{"", "expecting a method name"},
+		{"q.", "expecting a method name"},
+		{"BlockHeight", "expecting '('"},
+		{"Echo(a", "expecting ')'"},
+		{"Echo(a(b)", "expecting ')'"},
+		{"Echo(a,)", "expecting an argument"},
+		{`Echo("a)`, "unterminated string"},
+		{`Echo("a" b)`, "expecting ',' or ')'"},
+		{`Echo({"a":1)`, "unterminated JSON"},
+		{`Echo({a:1})`, "invalid JSON"},
+		{"Echo(a) b", "unexpected \"b\" after ')'"},
+	} {
+		if _, _, _, err := ParseInputData(tc.input); err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Fatalf("input [%s]: expecting error [%s], got %v", tc.input, tc.err, err)
+		}
+	}
+}
+
+func TestArg_Convert(t *testing.T) {
+	type options struct {
+		Iterations int
+	}
+	for _, tc := range []struct {
+		arg      Arg
+		expected interface{}
+	}{
+		{Arg{NumberArg, "12"}, "12"},
+		{Arg{JSONArg, `{"a":1}`}, `{"a":1}`},
+		{Arg{StringArg, "abc"}, []byte("abc")},
+		{Arg{NumberArg, "12"}, uint64(12)},
+		{Arg{NumberArg, "-3"}, int32(-3)},
+		{Arg{NumberArg, "0.5"}, 0.5},
+		{Arg{BoolArg, "true"}, true},
+		{Arg{WordArg, "5m"}, 5 * time.Minute},
+		{Arg{NumberArg, "1000"}, time.Duration(1000)},
+		{Arg{JSONArg, `{"Iterations":3}`}, options{Iterations: 3}},
+		{Arg{StringArg, `{"Iterations":3}`}, &options{Iterations: 3}},
+		{Arg{JSONArg, `["a","b"]`}, []string{"a", "b"}},
+		{Arg{JSONArg, `{"a":"b"}`}, map[string]string{"a": "b"}},
+	} {
+		value, err := tc.arg.Convert(reflect.TypeOf(tc.expected))
+		if err != nil {
+			t.Fatalf("arg %v: %v", tc.arg, err)
+		}
+		if !reflect.DeepEqual(value.Interface(), tc.expected) {
+			t.Fatalf("arg %v: expecting %#v, got %#v", tc.arg, tc.expected, value.Interface())
+		}
+	}
+
+	for _, tc := range []struct {
+		arg      Arg
+		expected interface{}
+		err      string
+	}{
+		{Arg{WordArg, "abc"}, uint64(0), "cannot convert word abc to uint64"},
+		{Arg{NumberArg, "-1"}, uint64(0), "cannot convert number -1 to uint64"},
+		{Arg{NumberArg, "300"}, int8(0), "cannot convert number 300 to int8"},
+		{Arg{StringArg, "12"}, 0, "cannot convert string 12 to int"},
+		{Arg{NumberArg, "1"}, false, "cannot convert number 1 to bool"},
+		{Arg{JSONArg, `{"Iterations":"3"}`}, options{}, "cannot convert JSON"},
+		{Arg{WordArg, "x"}, options{}, "cannot convert word x to console.options"},
+	} {
+		if _, err := tc.arg.Convert(reflect.TypeOf(tc.expected)); err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Fatalf("arg %v: expecting error [%s], got %v", tc.arg, tc.err, err)
+		}
+	}
+}
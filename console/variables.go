@@ -0,0 +1,123 @@
+package console
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/zhcppy/fabricli/jsonp"
+)
+
+var (
+	// reference is a variable followed by the fields and indexes of its value, e.g. $b.Header.Number or $peers[0]
+	reference = regexp.MustCompile(`^\$[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*|\[[0-9]+\])*$`)
+	// assignment assigns the result of a command to a variable, e.g. $h = q.BlockHeight()
+	assignment = regexp.MustCompile(`^\s*\$([A-Za-z_][A-Za-z0-9_]*)\s*=(.*)$`)
+	pathItem   = regexp.MustCompile(`\.?([A-Za-z_][A-Za-z0-9_]*)|\[([0-9]+)\]`)
+)
+
+// variables holds the results assigned by the console commands
+type variables map[string]reflect.Value
+
+// lookup returns the value of the reference, the fields of structs and the keys of maps
+// are given by name, the elements of slices by index
+func (vars variables) lookup(ref string) (reflect.Value, error) {
+	if !reference.MatchString(ref) {
+		return reflect.Value{}, errors.Errorf("invalid variable reference %s", ref)
+	}
+	items := pathItem.FindAllStringSubmatch(ref[1:], -1)
+	value, ok := vars[items[0][1]]
+	if !ok {
+		return reflect.Value{}, errors.Errorf("variable $%s not set", items[0][1])
+	}
+	path := "$" + items[0][1]
+	for _, item := range items[1:] {
+		for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+			if value.IsNil() {
+				return reflect.Value{}, errors.Errorf("%s is nil", path)
+			}
+			value = value.Elem()
+		}
+		name, index := item[1], item[2]
+		path += item[0]
+		switch {
+		case index != "":
+			if value.Kind() != reflect.Slice && value.Kind() != reflect.Array {
+				return reflect.Value{}, errors.Errorf("%s is not a list", path)
+			}
+			i, _ := strconv.Atoi(index)
+			if i >= value.Len() {
+				return reflect.Value{}, errors.Errorf("%s is out of range, length %d", path, value.Len())
+			}
+			value = value.Index(i)
+		case value.Kind() == reflect.Struct:
+			field, ok := value.Type().FieldByName(name)
+			if !ok || field.PkgPath != "" {
+				return reflect.Value{}, errors.Errorf("%s: no field %s in %s", path, name, value.Type())
+			}
+			value = value.FieldByIndex(field.Index)
+		case value.Kind() == reflect.Map && value.Type().Key().Kind() == reflect.String:
+			elem := value.MapIndex(reflect.ValueOf(name).Convert(value.Type().Key()))
+			if !elem.IsValid() {
+				return reflect.Value{}, errors.Errorf("%s: no key %s", path, name)
+			}
+			value = elem
+		default:
+			return reflect.Value{}, errors.Errorf("%s: %s has no fields", path, value.Type())
+		}
+	}
+	return value, nil
+}
+
+// convert converts the value of a variable to the given type, the values which are not assignable
+// are converted as the argument of their text, or of their JSON encoding
+func convert(value reflect.Value, t reflect.Type) (reflect.Value, error) {
+	if value.Type().AssignableTo(t) {
+		return value, nil
+	}
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return reflect.Zero(t), nil
+		}
+		if value = value.Elem(); value.Type().AssignableTo(t) {
+			return value, nil
+		}
+	}
+	var arg Arg
+	switch value.Kind() {
+	case reflect.String:
+		// a string holding a number or a bool converts to it
+		arg = Arg{Kind: StringArg, Text: value.String()}
+		if text := value.String(); isNumber(text) {
+			arg.Kind = NumberArg
+		} else if text == "true" || text == "false" {
+			arg.Kind = BoolArg
+		}
+	case reflect.Bool:
+		arg = Arg{Kind: BoolArg, Text: strconv.FormatBool(value.Bool())}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		arg = Arg{Kind: NumberArg, Text: fmt.Sprint(value.Interface())}
+	default:
+		bytes, err := json.Marshal(value.Interface())
+		if err != nil {
+			return reflect.Value{}, errors.Wrapf(err, "cannot convert %s to %s", value.Type(), t)
+		}
+		arg = Arg{Kind: JSONArg, Text: string(bytes)}
+	}
+	return arg.Convert(t)
+}
+
+// printValue pretty prints the nth value returned by a command
+func printValue(n int, value reflect.Value) {
+	valueType := value.Type()
+	if indirect := reflect.Indirect(value); indirect.IsValid() {
+		valueType = indirect.Type()
+	}
+	bytes, _ := jsonp.Marshal(value.Interface())
+	fmt.Printf("%02d - %s:\n%s\n", n, valueType, string(bytes))
+}
@@ -7,6 +7,7 @@ SPDX-License-Identifier: Apache-2.0
 package executor
 
 import (
+	"context"
 	"fmt"
 	"math"
 	"sync"
@@ -37,6 +38,7 @@ const (
 type Executor struct {
 	name        string
 	state       State
+	ctx         context.Context
 	tasks       chan worker.Task
 	terminating chan bool
 	pool        *worker.Pool
@@ -74,20 +76,41 @@ func New(name string, queueLength uint16, pool *worker.Pool) *Executor {
 	return &Executor{
 		name:        name,
 		state:       NEW,
+		ctx:         context.Background(),
 		terminating: make(chan bool),
 		tasks:       make(chan worker.Task, queueLength),
 		pool:        pool,
 	}
 }
 
+// SetTaskTimeout sets the time a task implementing worker.ContextTask is given to complete,
+// 0 means no timeout. It must be set before the executor is started
+func (e *Executor) SetTaskTimeout(timeout time.Duration) {
+	e.pool.SetTaskTimeout(timeout)
+}
+
+// SetEvents sets the events that are notified of the tasks executed by the workers,
+// e.g. worker.Metrics. It must be set before the executor is started
+func (e *Executor) SetEvents(events worker.Events) {
+	e.pool.SetEvents(events)
+}
+
 // Start starts the Executor
 func (e *Executor) Start() bool {
+	return e.StartContext(context.Background())
+}
+
+// StartContext starts the Executor, once the given context is done no more tasks are accepted
+// and the tasks implementing worker.ContextTask are invoked with a done context, so that
+// the running and queued tasks complete promptly
+func (e *Executor) StartContext(ctx context.Context) bool {
 	if e.state != NEW {
 		return false
 	}
 
 	// Start the worker pool
-	e.pool.Start()
+	e.ctx = ctx
+	e.pool.StartContext(ctx)
 
 	// Start the task dispatcher
 	go e.dispatch()
@@ -102,6 +125,9 @@ func (e *Executor) Submit(task worker.Task) error {
 	if e.state != STARTED {
 		return fmt.Errorf("executor [%s] is not started", e.name)
 	}
+	if err := e.ctx.Err(); err != nil {
+		return fmt.Errorf("executor [%s] is done: %s", e.name, err)
+	}
 
 	// Submit the task to the dispatcher
 	logger.L().Debugf("Submit[%s] - submitting task...\n", e.name)
@@ -112,6 +138,26 @@ func (e *Executor) Submit(task worker.Task) error {
 	return nil
 }
 
+// TrySubmit submits a new task without blocking, false is returned when the queue is full
+func (e *Executor) TrySubmit(task worker.Task) (bool, error) {
+	if e.state != STARTED {
+		return false, fmt.Errorf("executor [%s] is not started", e.name)
+	}
+	if err := e.ctx.Err(); err != nil {
+		return false, fmt.Errorf("executor [%s] is done: %s", e.name, err)
+	}
+
+	e.wg.Add(1)
+	select {
+	case e.tasks <- task:
+		return true, nil
+	default:
+		e.wg.Done()
+		logger.L().Debugf("TrySubmit[%s] - queue is full\n", e.name)
+		return false, nil
+	}
+}
+
 // SubmitDelayed submits a new task in the future
 func (e *Executor) SubmitDelayed(task worker.Task, delay time.Duration) error {
 	if e.state != STARTED {
@@ -123,8 +169,12 @@ func (e *Executor) SubmitDelayed(task worker.Task, delay time.Duration) error {
 	e.wg.Add(1)
 
 	go func() {
-		<-time.After(delay)
-		e.Submit(task)
+		select {
+		case <-time.After(delay):
+			e.Submit(task)
+		case <-e.ctx.Done():
+			logger.L().Debugf("Submit[%s] - delayed task discarded: %s\n", e.name, e.ctx.Err())
+		}
 		e.wg.Done()
 	}()
 
@@ -136,6 +186,16 @@ func (e *Executor) Wait() {
 	e.wg.Wait()
 }
 
+// Queued returns the number of submitted tasks waiting to be dispatched to a worker
+func (e *Executor) Queued() int {
+	return len(e.tasks)
+}
+
+// Done returns a channel that is closed once the context the executor was started with is done
+func (e *Executor) Done() <-chan struct{} {
+	return e.ctx.Done()
+}
+
 // Stop stops the executor.
 // - wait: If true then the call will block until all outstanding
 //   tasks have completed; otherwise the executor will shut down immediately
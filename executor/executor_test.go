@@ -0,0 +1,98 @@
+package executor
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zhcppy/fabricli/executor/worker"
+)
+
+type ctxTask struct {
+	sleep time.Duration
+	wg    *sync.WaitGroup
+	errs  *int64
+}
+
+func (t *ctxTask) Invoke() {
+	t.InvokeContext(context.Background())
+}
+
+func (t *ctxTask) InvokeContext(ctx context.Context) {
+	defer t.wg.Done()
+	select {
+	case <-time.After(t.sleep):
+	case <-ctx.Done():
+		atomic.AddInt64(t.errs, 1)
+	}
+}
+
+func TestExecutor_StartContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	exec := NewConcurrent("test", 2)
+	exec.StartContext(ctx)
+	defer exec.Stop(true)
+
+	var wg sync.WaitGroup
+	var errs int64
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		if err := exec.Submit(&ctxTask{sleep: time.Minute, wg: &wg, errs: &errs}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	wg.Wait()
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("expecting the tasks to stop once canceled, took %s", elapsed)
+	}
+	if errs != 10 {
+		t.Fatalf("expecting 10 canceled tasks, got %d", errs)
+	}
+	if err := exec.Submit(&ctxTask{}); err == nil {
+		t.Fatal("expecting error submitting to a canceled executor")
+	}
+}
+
+func TestExecutor_SetTaskTimeout(t *testing.T) {
+	exec := NewConcurrent("test", 2)
+	exec.SetTaskTimeout(50 * time.Millisecond)
+	exec.Start()
+	defer exec.Stop(true)
+
+	var wg sync.WaitGroup
+	var errs int64
+	wg.Add(2)
+	exec.Submit(&ctxTask{sleep: time.Minute, wg: &wg, errs: &errs})
+	exec.Submit(&ctxTask{sleep: time.Millisecond, wg: &wg, errs: &errs})
+	wg.Wait()
+	if errs != 1 {
+		t.Fatalf("expecting 1 timed out task, got %d", errs)
+	}
+}
+
+func TestScheduler_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	exec := NewConcurrent("test", 2)
+	exec.StartContext(ctx)
+	defer exec.Stop(true)
+
+	var wg sync.WaitGroup
+	var errs int64
+	time.AfterFunc(100*time.Millisecond, cancel)
+	results, err := NewScheduler(exec, Step{TPS: 100, Duration: time.Minute}).Run(func() worker.Task {
+		wg.Add(1)
+		return &ctxTask{sleep: time.Minute, wg: &wg, errs: &errs}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wg.Wait()
+	if r := results[0]; r.Duration > 5*time.Second || r.Offered == 0 {
+		t.Fatalf("expecting the step to stop once canceled, got %+v", r)
+	}
+}
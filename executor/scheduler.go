@@ -0,0 +1,207 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync/atomic"
+	"time"
+
+	"github.com/zhcppy/fabricli/executor/worker"
+	"github.com/zhcppy/fabricli/logger"
+)
+
+// pacing is the longest the Scheduler sleeps between two submission rounds
+const pacing = 10 * time.Millisecond
+
+// Step is a stage of an open-loop load, tasks are submitted at TPS for the duration of the step
+type Step struct {
+	TPS      float64
+	Duration time.Duration
+}
+
+// RampSteps returns the steps that ramp the rate up to tps in rampSteps equal steps over rampDuration,
+// followed by a step holding tps for duration
+func RampSteps(tps float64, duration time.Duration, rampSteps int, rampDuration time.Duration) []Step {
+	var steps []Step
+	if rampSteps > 0 && rampDuration > 0 {
+		for i := 1; i <= rampSteps; i++ {
+			steps = append(steps, Step{
+				TPS:      tps * float64(i) / float64(rampSteps+1),
+				Duration: rampDuration / time.Duration(rampSteps),
+			})
+		}
+	}
+	return append(steps, Step{TPS: tps, Duration: duration})
+}
+
+// StepResult is what happened during a step
+type StepResult struct {
+	Step
+	// Offered is the number of tasks the rate of the step asked for
+	Offered int
+	// Submitted is the number of tasks queued on the executor
+	Submitted int
+	// Dropped is the number of tasks not submitted since the queue of the executor was full
+	Dropped int
+	// Started is the number of tasks the workers started during the step
+	Started int
+	// MaxQueued is the largest number of tasks waiting for a worker during the step
+	MaxQueued int
+	// Saturated is set when the workers could not keep up with the offered rate,
+	// i.e. tasks were dropped or the queue kept growing during the step
+	Saturated bool
+}
+
+// AchievedTPS returns the rate the workers started the tasks at during the step
+func (r StepResult) AchievedTPS() float64 {
+	if r.Duration <= 0 {
+		return 0
+	}
+	return float64(r.Started) / r.Duration.Seconds()
+}
+
+// Scheduler submits tasks to an Executor at a target rate regardless of how fast
+// the tasks complete (open loop), unlike a caller that submits the next task once a worker is free
+type Scheduler struct {
+	executor *Executor
+	steps    []Step
+	queued   int64
+	started  int64
+}
+
+// NewScheduler creates a Scheduler that runs the steps in turn on the executor
+func NewScheduler(executor *Executor, steps ...Step) *Scheduler {
+	return &Scheduler{executor: executor, steps: steps}
+}
+
+type scheduledTask struct {
+	task      worker.Task
+	scheduler *Scheduler
+}
+
+func (t *scheduledTask) Invoke() {
+	t.InvokeContext(context.Background())
+}
+
+func (t *scheduledTask) InvokeContext(ctx context.Context) {
+	atomic.AddInt64(&t.scheduler.queued, -1)
+	atomic.AddInt64(&t.scheduler.started, 1)
+	if task, ok := t.task.(worker.ContextTask); ok {
+		task.InvokeContext(ctx)
+	} else {
+		t.task.Invoke()
+	}
+}
+
+// Queued returns the number of submitted tasks waiting for a worker
+func (s *Scheduler) Queued() int {
+	return int(atomic.LoadInt64(&s.queued))
+}
+
+// Run submits the tasks created by newTask at the rate of each step in turn, it stops early
+// once newTask returns nil or the context of the executor is done.
+// Run does not wait for the submitted tasks to complete
+func (s *Scheduler) Run(newTask func() worker.Task) ([]StepResult, error) {
+	var results []StepResult
+	for i, step := range s.steps {
+		if step.TPS <= 0 || step.Duration <= 0 {
+			return results, fmt.Errorf("invalid step %d: %+v", i, step)
+		}
+		result, more, err := s.runStep(step, newTask)
+		results = append(results, result)
+		if err != nil {
+			return results, err
+		}
+		if result.Saturated {
+			logger.L().Warnf("Step %d: offered %.2f/s exceeds what the workers can absorb, %d task(s) waiting, %d dropped",
+				i, step.TPS, s.Queued(), result.Dropped)
+		}
+		if !more {
+			break
+		}
+	}
+	return results, nil
+}
+
+func (s *Scheduler) runStep(step Step, newTask func() worker.Task) (result StepResult, more bool, err error) {
+	result.Step = step
+	startQueued := s.Queued()
+	startStarted := atomic.LoadInt64(&s.started)
+	start := time.Now()
+	end := start.Add(step.Duration)
+	more = true
+	for more {
+		now := time.Now()
+		if !now.Before(end) {
+			now = end
+		}
+		due := int(math.Floor(now.Sub(start).Seconds() * step.TPS))
+		for ; result.Offered < due; result.Offered++ {
+			if s.executor.ctx.Err() != nil {
+				more = false
+				break
+			}
+			task := newTask()
+			if task == nil {
+				more = false
+				break
+			}
+			atomic.AddInt64(&s.queued, 1)
+			ok, err := s.executor.TrySubmit(&scheduledTask{task: task, scheduler: s})
+			if err != nil {
+				atomic.AddInt64(&s.queued, -1)
+				if s.executor.ctx.Err() != nil {
+					// canceled since the check above, let a ContextTask complete with the done context
+					if task, ok := task.(worker.ContextTask); ok {
+						task.InvokeContext(s.executor.ctx)
+					}
+					result.Offered++
+					result.Dropped++
+					more = false
+					break
+				}
+				return result, false, err
+			}
+			if !ok {
+				atomic.AddInt64(&s.queued, -1)
+				result.Dropped++
+				continue
+			}
+			result.Submitted++
+		}
+		if queued := s.Queued(); queued > result.MaxQueued {
+			result.MaxQueued = queued
+		}
+		if !more || now == end {
+			break
+		}
+		sleep := time.Duration(float64(time.Second) / step.TPS)
+		if sleep > pacing {
+			sleep = pacing
+		}
+		if remaining := end.Sub(time.Now()); sleep > remaining {
+			sleep = remaining
+		}
+		select {
+		case <-time.After(sleep):
+		case <-s.executor.Done():
+			more = false
+		}
+	}
+	if more {
+		result.Duration = step.Duration
+	} else {
+		result.Duration = time.Since(start)
+	}
+	result.Started = int(atomic.LoadInt64(&s.started) - startStarted)
+
+	// the tasks submitted by the last round may not have reached a worker yet, and
+	// a queue growing by less than 1% of the offered tasks is jitter
+	tolerance := int(math.Ceil(step.TPS*pacing.Seconds())) + 1
+	if result.Offered/100 > tolerance {
+		tolerance = result.Offered / 100
+	}
+	result.Saturated = result.Dropped > 0 || s.Queued()-startQueued > tolerance
+	return result, more, nil
+}
@@ -0,0 +1,82 @@
+package executor
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zhcppy/fabricli/executor/worker"
+)
+
+type sleepTask struct {
+	sleep time.Duration
+	wg    *sync.WaitGroup
+}
+
+func (t *sleepTask) Invoke() {
+	defer t.wg.Done()
+	time.Sleep(t.sleep)
+}
+
+func runScheduler(t *testing.T, concurrency uint16, sleep time.Duration, max int, steps ...Step) []StepResult {
+	exec := NewConcurrent("test", concurrency)
+	exec.Start()
+	defer exec.Stop(true)
+
+	var wg sync.WaitGroup
+	var count int
+	results, err := NewScheduler(exec, steps...).Run(func() worker.Task {
+		if max > 0 && count >= max {
+			return nil
+		}
+		count++
+		wg.Add(1)
+		return &sleepTask{sleep: sleep, wg: &wg}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wg.Wait()
+	return results
+}
+
+func TestScheduler_Run(t *testing.T) {
+	results := runScheduler(t, 10, 0, 0, Step{TPS: 100, Duration: 500 * time.Millisecond})
+	if len(results) != 1 {
+		t.Fatalf("expecting 1 step result, got %d", len(results))
+	}
+	if r := results[0]; r.Offered != 50 || r.Submitted != 50 || r.Saturated {
+		t.Fatalf("unexpected step result %+v", r)
+	}
+}
+
+func TestScheduler_Saturated(t *testing.T) {
+	results := runScheduler(t, 1, 50*time.Millisecond, 0, Step{TPS: 100, Duration: 300 * time.Millisecond})
+	if r := results[0]; !r.Saturated || r.MaxQueued == 0 || r.AchievedTPS() >= 100 {
+		t.Fatalf("expecting saturated step, got %+v", r)
+	}
+}
+
+func TestScheduler_MaxTasks(t *testing.T) {
+	results := runScheduler(t, 10, 0, 10, RampSteps(100, time.Second, 2, time.Second)...)
+	var offered int
+	for _, r := range results {
+		offered += r.Offered
+	}
+	if offered != 10 {
+		t.Fatalf("expecting 10 offered tasks, got %d", offered)
+	}
+}
+
+func TestRampSteps(t *testing.T) {
+	steps := RampSteps(90, time.Minute, 2, 10*time.Second)
+	expected := []Step{{30, 5 * time.Second}, {60, 5 * time.Second}, {90, time.Minute}}
+	if len(steps) != len(expected) {
+		t.Fatalf("unexpected steps %+v", steps)
+	}
+	for i := range steps {
+		if steps[i] != expected[i] {
+			t.Fatalf("unexpected steps %+v", steps)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package worker
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Metrics are Events that count the tasks executed by the workers of a pool
+// and measure how busy the workers are
+type Metrics struct {
+	workers   int
+	start     time.Time
+	started   int64
+	completed int64
+	panicked  int64
+	inFlight  int64
+	busy      int64
+	mutex     sync.Mutex
+	running   map[*Worker]time.Time
+}
+
+// NewMetrics creates the Metrics of a pool with the given number of workers,
+// the utilization is measured from now on
+func NewMetrics(workers int) *Metrics {
+	return &Metrics{
+		workers: workers,
+		start:   time.Now(),
+		running: make(map[*Worker]time.Time),
+	}
+}
+
+// StateChange is invoked when the state of the Worker changes
+func (m *Metrics) StateChange(w *Worker, state State) {
+	// Nothing to do
+}
+
+// TaskStarted is invoked when the given Worker begins executing the given Task
+func (m *Metrics) TaskStarted(w *Worker, task Task) {
+	atomic.AddInt64(&m.started, 1)
+	atomic.AddInt64(&m.inFlight, 1)
+	m.mutex.Lock()
+	m.running[w] = time.Now()
+	m.mutex.Unlock()
+}
+
+// TaskCompleted is invoked when the given Worker completed executing the given Task
+func (m *Metrics) TaskCompleted(w *Worker, task Task, err error) {
+	m.mutex.Lock()
+	start := m.running[w]
+	delete(m.running, w)
+	m.mutex.Unlock()
+
+	atomic.AddInt64(&m.busy, int64(time.Since(start)))
+	atomic.AddInt64(&m.inFlight, -1)
+	atomic.AddInt64(&m.completed, 1)
+	if _, ok := err.(*PanicError); ok {
+		atomic.AddInt64(&m.panicked, 1)
+	}
+}
+
+// Started returns the number of tasks the workers started
+func (m *Metrics) Started() int {
+	return int(atomic.LoadInt64(&m.started))
+}
+
+// Completed returns the number of tasks the workers completed, including the ones that panicked
+func (m *Metrics) Completed() int {
+	return int(atomic.LoadInt64(&m.completed))
+}
+
+// Panicked returns the number of tasks the workers recovered from a panic in
+func (m *Metrics) Panicked() int {
+	return int(atomic.LoadInt64(&m.panicked))
+}
+
+// InFlight returns the number of tasks being executed
+func (m *Metrics) InFlight() int {
+	return int(atomic.LoadInt64(&m.inFlight))
+}
+
+// Utilization returns the fraction of the time the workers spent executing tasks, between 0 and 1
+func (m *Metrics) Utilization() float64 {
+	elapsed := time.Since(m.start)
+	if elapsed <= 0 || m.workers <= 0 {
+		return 0
+	}
+	busy := time.Duration(atomic.LoadInt64(&m.busy))
+	m.mutex.Lock()
+	for _, start := range m.running {
+		busy += time.Since(start)
+	}
+	m.mutex.Unlock()
+	utilization := busy.Seconds() / (elapsed.Seconds() * float64(m.workers))
+	if utilization > 1 {
+		utilization = 1
+	}
+	return utilization
+}
@@ -6,7 +6,13 @@ SPDX-License-Identifier: Apache-2.0
 
 package worker
 
-import "github.com/zhcppy/fabricli/logger"
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+
+	"github.com/zhcppy/fabricli/logger"
+)
 
 // State is the state of a Worker
 type State uint8
@@ -24,6 +30,14 @@ type Task interface {
 	Invoke()
 }
 
+// ContextTask is a Task that can be interrupted. The Worker invokes it with InvokeContext instead
+// of Invoke, the context is done once the task times out or the pool is forced to stop, in which
+// case the task should return as soon as possible
+type ContextTask interface {
+	Task
+	InvokeContext(ctx context.Context)
+}
+
 // Events receives event notifications from the worker
 type Events interface {
 	// StateChange indicates the new state of the worker
@@ -32,23 +46,42 @@ type Events interface {
 	// TaskStarted indicates that the given worker has started executing the given task
 	TaskStarted(w *Worker, task Task)
 
-	// TaskCompleted indicates that the given worker has completed the given task
-	TaskCompleted(w *Worker, task Task)
+	// TaskCompleted indicates that the given worker has completed the given task,
+	// err is the PanicError the task panicked with, nil otherwise
+	TaskCompleted(w *Worker, task Task, err error)
+}
+
+// PanicError is the error a Task panicked with, the worker recovers from
+// the panic and keeps serving tasks
+type PanicError struct {
+	Value interface{}
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("task panicked: %v", e.Value)
 }
 
 // Worker invokes a Task
 type Worker struct {
 	name   string
 	events Events
-	task   chan Task
+	task   chan job
 	done   chan bool
 }
 
+// job is a task together with the context it is invoked with
+type job struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	task   Task
+}
+
 func newWorker(name string, events Events) *Worker {
 	return &Worker{
 		name:   name,
 		events: events,
-		task:   make(chan Task),
+		task:   make(chan job),
 		done:   make(chan bool),
 	}
 }
@@ -60,15 +93,37 @@ func (w *Worker) Name() string {
 
 // Submit submits a task
 func (w *Worker) Submit(task Task) {
-	w.task <- task
+	w.SubmitContext(context.Background(), nil, task)
+}
+
+// SubmitContext submits a task that is invoked with the given context,
+// cancel is called once the task has completed
+func (w *Worker) SubmitContext(ctx context.Context, cancel context.CancelFunc, task Task) {
+	w.task <- job{ctx: ctx, cancel: cancel, task: task}
 }
 
-func (w *Worker) invoke(task Task) {
+func (w *Worker) invoke(j job) {
 	logger.L().Debugf("Worker[%s].invoke ...\n", w.name)
-	defer w.events.TaskCompleted(w, task)
+	var err error
+	defer func() {
+		w.events.TaskCompleted(w, j.task, err)
+	}()
+	if j.cancel != nil {
+		defer j.cancel()
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Value: r, Stack: debug.Stack()}
+			logger.L().Errorf("Worker[%s] recovered from a panic in a task: %v\n%s", w.name, r, err.(*PanicError).Stack)
+		}
+	}()
 
-	w.events.TaskStarted(w, task)
-	task.Invoke()
+	w.events.TaskStarted(w, j.task)
+	if task, ok := j.task.(ContextTask); ok {
+		task.InvokeContext(j.ctx)
+	} else {
+		j.task.Invoke()
+	}
 	logger.L().Debugf("Worker[%s].invoke done.\n", w.name)
 }
 
@@ -83,8 +138,8 @@ func (w *Worker) Start() {
 			w.events.StateChange(w, READY)
 
 			select {
-			case task := <-w.task:
-				w.invoke(task)
+			case j := <-w.task:
+				w.invoke(j)
 
 			case <-w.done:
 				w.events.StateChange(w, STOPPED)
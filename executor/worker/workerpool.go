@@ -7,8 +7,10 @@ SPDX-License-Identifier: Apache-2.0
 package worker
 
 import (
+	"context"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/zhcppy/fabricli/logger"
 )
@@ -18,6 +20,10 @@ type Pool struct {
 	name            string
 	workers         []*Worker
 	availableWorker chan *Worker
+	ctx             context.Context
+	cancel          context.CancelFunc
+	taskTimeout     time.Duration
+	events          Events
 	taskWg          sync.WaitGroup
 	wg              sync.WaitGroup
 }
@@ -28,6 +34,8 @@ func NewPool(name string, concurrency uint16) *Pool {
 		name:            name,
 		availableWorker: make(chan *Worker, concurrency),
 		workers:         make([]*Worker, concurrency),
+		ctx:             context.Background(),
+		cancel:          func() {},
 	}
 
 	// Create the workers
@@ -43,8 +51,27 @@ func (p *Pool) Name() string {
 	return p.name
 }
 
+// SetTaskTimeout sets the time a ContextTask is given to complete, 0 means no timeout.
+// It must be set before the pool is started
+func (p *Pool) SetTaskTimeout(timeout time.Duration) {
+	p.taskTimeout = timeout
+}
+
+// SetEvents sets the events that are notified of the state of the workers and
+// the tasks they execute, e.g. to collect metrics. It must be set before the pool is started
+func (p *Pool) SetEvents(events Events) {
+	p.events = events
+}
+
 // Start starts the pool
 func (p *Pool) Start() {
+	p.StartContext(context.Background())
+}
+
+// StartContext starts the pool, the ContextTasks are invoked with a context
+// that is done once the given context is done or the pool is forced to stop
+func (p *Pool) StartContext(ctx context.Context) {
+	p.ctx, p.cancel = context.WithCancel(ctx)
 	p.wg.Add(len(p.workers))
 
 	// Start the workers
@@ -63,6 +90,7 @@ func (p *Pool) Stop(wait bool) {
 		p.taskWg.Wait()
 	} else {
 		logger.L().Debugf("[%s] ... forcing all tasks to stop ...\n", p.name)
+		p.cancel()
 	}
 
 	// Shut down the workers
@@ -74,6 +102,7 @@ func (p *Pool) Stop(wait bool) {
 
 	// Wait for all of the workers to stop
 	p.wg.Wait()
+	p.cancel()
 }
 
 // Submit submits a Task for execution
@@ -88,13 +117,20 @@ func (p *Pool) Submit(task Task) {
 	logger.L().Debugf("worker pool.Submit[%s] - got worker [%s]. Submitting task...\n", p.name, w.Name())
 
 	// Submit the task to the worker
-	w.Submit(task)
+	ctx, cancel := p.ctx, context.CancelFunc(nil)
+	if p.taskTimeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, p.taskTimeout)
+	}
+	w.SubmitContext(ctx, cancel, task)
 
 	logger.L().Debugf("worker pool.Submit[%s] - submitted task to worker[%s]\n", p.name, w.Name())
 }
 
 // StateChange is invoked when the state of the Worker changes
 func (p *Pool) StateChange(w *Worker, state State) {
+	if p.events != nil {
+		p.events.StateChange(w, state)
+	}
 	switch state {
 	case READY:
 		p.availableWorker <- w
@@ -113,10 +149,15 @@ func (p *Pool) StateChange(w *Worker, state State) {
 
 // TaskStarted is invoked when the given Worker begins executing the given Task
 func (p *Pool) TaskStarted(w *Worker, task Task) {
-	// Nothing to do
+	if p.events != nil {
+		p.events.TaskStarted(w, task)
+	}
 }
 
 // TaskCompleted is invoked when the given Worker completed executing the given Task
-func (p *Pool) TaskCompleted(w *Worker, task Task) {
+func (p *Pool) TaskCompleted(w *Worker, task Task, err error) {
+	if p.events != nil {
+		p.events.TaskCompleted(w, task, err)
+	}
 	p.taskWg.Done()
 }
@@ -0,0 +1,40 @@
+package worker
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+type funcTask func()
+
+func (f funcTask) Invoke() {
+	f()
+}
+
+func TestPool_RecoverPanic(t *testing.T) {
+	pool := NewPool("test", 1)
+	metrics := NewMetrics(1)
+	pool.SetEvents(metrics)
+	pool.Start()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	pool.Submit(funcTask(func() {
+		panic("boom")
+	}))
+	pool.Submit(funcTask(func() {
+		defer wg.Done()
+		time.Sleep(10 * time.Millisecond)
+	}))
+	wg.Wait()
+	pool.Stop(true)
+
+	if metrics.Started() != 2 || metrics.Completed() != 2 || metrics.Panicked() != 1 || metrics.InFlight() != 0 {
+		t.Fatalf("unexpected metrics: started %d, completed %d, panicked %d, in flight %d",
+			metrics.Started(), metrics.Completed(), metrics.Panicked(), metrics.InFlight())
+	}
+	if u := metrics.Utilization(); u <= 0 || u > 1 {
+		t.Fatalf("unexpected utilization %f", u)
+	}
+}
@@ -7,6 +7,9 @@ package printer
 
 import (
 	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
 
 	"github.com/fatih/color"
 	"github.com/zhcppy/fabricli/jsonp"
@@ -56,6 +59,18 @@ func JSONStr(v interface{}) string {
 	return string(bytes)
 }
 
+// Table prints the rows aligned in columns under the given header
+func Table(header []string, rows [][]string) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	if err := w.Flush(); err != nil {
+		Error(err.Error())
+	}
+}
+
 type ColorFormatter struct {
 	color *color.Color
 }
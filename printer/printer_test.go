@@ -28,3 +28,7 @@ func TestError(t *testing.T) {
 func TestWarn(t *testing.T) {
 	Warn("Warn")
 }
+
+func TestTable(t *testing.T) {
+	Table([]string{"PEER", "STATUS"}, [][]string{{"peer0.org1.example.com:7051", "200"}, {"peer1:7051", "500"}})
+}
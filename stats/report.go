@@ -0,0 +1,159 @@
+package stats
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/zhcppy/fabricli/printer"
+)
+
+const (
+	JSON = "json"
+	CSV  = "csv"
+)
+
+// CheckFormat checks that the report format is supported
+func CheckFormat(format string) error {
+	switch format {
+	case JSON, CSV:
+		return nil
+	}
+	return errors.Errorf("unsupported report format [%s], expecting %s or %s", format, JSON, CSV)
+}
+
+// WriteReportFile writes the summary and the results recorded so far to the file in the given format
+func (r *Recorder) WriteReportFile(file, format string) error {
+	if err := CheckFormat(format); err != nil {
+		return err
+	}
+	f, err := os.Create(file)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create report file [%s]", file)
+	}
+	defer f.Close()
+	if err := r.WriteReport(f, format); err != nil {
+		return errors.WithMessagef(err, "failed to write report file [%s]", file)
+	}
+	return nil
+}
+
+// WriteReport writes the summary and the results recorded so far in the given format.
+// The json report holds both, the csv report holds one row per invocation
+func (r *Recorder) WriteReport(w io.Writer, format string) error {
+	switch format {
+	case JSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(struct {
+			Summary *Summary  `json:"summary"`
+			Results []*Result `json:"results"`
+		}{r.Summary(), r.Results()})
+	case CSV:
+		return writeCSV(w, r.Results())
+	}
+	return CheckFormat(format)
+}
+
+func writeCSV(w io.Writer, results []*Result) error {
+	cw := csv.NewWriter(w)
+	header := []string{"task_id", "tx_id", "func", "start", "latency_ms", "endorsement_ms", "commit_ms", "attempts", "endorsers", "code", "error", "ordering_ms"}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, result := range results {
+		err := cw.Write([]string{
+			result.TaskID,
+			result.TxID,
+			result.Func,
+			result.Start.Format(time.RFC3339Nano),
+			millis(result.Latency),
+			millis(result.Endorsement),
+			millis(result.Commit),
+			strconv.Itoa(result.Attempts),
+			strings.Join(result.Endorsers, ";"),
+			result.Code,
+			result.Error,
+			millis(result.Ordering),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func millis(d time.Duration) string {
+	return strconv.FormatFloat(float64(d)/float64(time.Millisecond), 'f', 3, 64)
+}
+
+// Print prints the summary with the latency percentiles and the per peer and per error breakdowns
+func (s *Summary) Print() {
+	fmt.Printf("\n")
+	fmt.Printf("*** ---------- Summary: ----------\n")
+	fmt.Printf("***   - Invocations:     %d\n", s.Invocations)
+	fmt.Printf("***   - Successfull:     %d\n", s.Success)
+	fmt.Printf("***   - Failed:          %d\n", s.Failed)
+	fmt.Printf("***   - MVCC conflicts:  %d (%2.2f%%)\n", s.MVCCConflicts, s.percent(s.MVCCConflicts))
+	fmt.Printf("***   - Phantom reads:   %d (%2.2f%%)\n", s.PhantomConflicts, s.percent(s.PhantomConflicts))
+	fmt.Printf("***   - Total attempts:  %d\n", s.Attempts)
+	fmt.Printf("***   - Duration:        %2.2fs\n", s.Duration.Seconds())
+	fmt.Printf("***   - Rate:            %2.2f/s\n", s.Rate)
+	fmt.Printf("*** ------------------------------\n")
+
+	header := []string{"LATENCY", "COUNT", "MIN", "AVG", "P50", "P90", "P95", "P99", "P99.9", "MAX"}
+	rows := [][]string{
+		latencyRow("total", s.Latency),
+		latencyRow("endorsement", s.Endorsement),
+	}
+	if s.Ordering.Count > 0 {
+		rows = append(rows, latencyRow("ordering", s.Ordering))
+	}
+	if s.Commit.Count > 0 {
+		rows = append(rows, latencyRow("commit", s.Commit))
+	}
+	printer.Table(header, rows)
+
+	if len(s.Peers) > 0 {
+		fmt.Printf("\n")
+		rows = nil
+		for _, peer := range s.Peers {
+			rows = append(rows, append([]string{peer.Peer, strconv.Itoa(peer.Failed)}, latencyRow("", peer.Endorsement)[1:]...))
+		}
+		printer.Table([]string{"PEER", "FAILED", "ENDORSEMENTS", "MIN", "AVG", "P50", "P90", "P95", "P99", "P99.9", "MAX"}, rows)
+	}
+
+	if len(s.Errors) > 0 {
+		fmt.Printf("\n")
+		rows = nil
+		for _, e := range s.Errors {
+			rows = append(rows, []string{e.Code, strconv.Itoa(e.Count), e.Sample})
+		}
+		printer.Table([]string{"ERROR CODE", "COUNT", "SAMPLE"}, rows)
+	}
+}
+
+// percent returns the percentage of the attempts that count represents
+func (s *Summary) percent(count int) float64 {
+	if s.Attempts == 0 {
+		return 0
+	}
+	return float64(count) * 100 / float64(s.Attempts)
+}
+
+func latencyRow(name string, l Latencies) []string {
+	return []string{name, strconv.Itoa(l.Count), Seconds(l.Min), Seconds(l.Avg), Seconds(l.P50),
+		Seconds(l.P90), Seconds(l.P95), Seconds(l.P99), Seconds(l.P999), Seconds(l.Max)}
+}
+
+// Seconds formats the duration in seconds with millisecond precision, the format of the latencies of the summaries
+func Seconds(d time.Duration) string {
+	return fmt.Sprintf("%2.3fs", d.Seconds())
+}
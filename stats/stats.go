@@ -0,0 +1,279 @@
+package stats
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// The validation codes of the transactions that failed because of a read conflict
+const (
+	MVCCReadConflict    = "MVCC_READ_CONFLICT"
+	PhantomReadConflict = "PHANTOM_READ_CONFLICT"
+)
+
+// Result is the result of a single chaincode invocation
+type Result struct {
+	TaskID string    `json:"taskId"`
+	TxID   string    `json:"txId,omitempty"`
+	Func   string    `json:"func"`
+	Start  time.Time `json:"start"`
+	// Latency is the time from the start of the first attempt until the invocation completed
+	Latency time.Duration `json:"latencyNs"`
+	// Endorsement is the time the last attempt spent collecting the endorsements
+	Endorsement time.Duration `json:"endorsementNs"`
+	// Ordering is the time the last attempt spent sending the transaction to the orderer,
+	// only measured when the transaction is submitted asynchronously
+	Ordering time.Duration `json:"orderingNs,omitempty"`
+	// Commit is the time the last attempt spent waiting for the transaction to be committed, zero for queries.
+	// It starts once the transaction is ordered when the transaction is submitted asynchronously
+	Commit    time.Duration `json:"commitNs"`
+	Attempts  int           `json:"attempts"`
+	Endorsers []string      `json:"endorsers,omitempty"`
+	// MVCCConflicts and PhantomConflicts are the number of attempts that failed because of a read conflict
+	// and were retried, the last attempt is given by Code
+	MVCCConflicts    int `json:"mvccReadConflicts,omitempty"`
+	PhantomConflicts int `json:"phantomReadConflicts,omitempty"`
+	// Code is the validation code of the transaction or the error code of a failed invocation
+	Code  string `json:"code"`
+	Error string `json:"error,omitempty"`
+}
+
+// Success returns whether the invocation succeeded
+func (r *Result) Success() bool {
+	return r.Error == ""
+}
+
+// AddRetriedAttempt counts a conflict when the code of the retried attempt is a read conflict
+func (r *Result) AddRetriedAttempt(code string) {
+	switch code {
+	case MVCCReadConflict:
+		r.MVCCConflicts++
+	case PhantomReadConflict:
+		r.PhantomConflicts++
+	}
+}
+
+// Recorder records the results of the invocations of a run
+type Recorder struct {
+	mutex   sync.RWMutex
+	results []*Result
+	success int
+	start   time.Time
+	end     time.Time
+}
+
+// NewRecorder creates a new Recorder
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// Start marks the start of the run
+func (r *Recorder) Start() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.start = time.Now()
+}
+
+// Stop marks the end of the run
+func (r *Recorder) Stop() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.end = time.Now()
+}
+
+// Add records the result of an invocation
+func (r *Recorder) Add(result *Result) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.results = append(r.results, result)
+	if result.Success() {
+		r.success++
+	}
+}
+
+// Counts returns the number of succeeded and failed invocations so far
+func (r *Recorder) Counts() (success, failed int) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.success, len(r.results) - r.success
+}
+
+// Results returns a copy of the results recorded so far
+func (r *Recorder) Results() []*Result {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return append([]*Result{}, r.results...)
+}
+
+// Latencies is the latency distribution of a set of invocations
+type Latencies struct {
+	Count int           `json:"count"`
+	Min   time.Duration `json:"minNs"`
+	Avg   time.Duration `json:"avgNs"`
+	Max   time.Duration `json:"maxNs"`
+	P50   time.Duration `json:"p50Ns"`
+	P90   time.Duration `json:"p90Ns"`
+	P95   time.Duration `json:"p95Ns"`
+	P99   time.Duration `json:"p99Ns"`
+	P999  time.Duration `json:"p999Ns"`
+}
+
+// NewLatencies computes the latency distribution of the durations
+func NewLatencies(durations []time.Duration) Latencies {
+	if len(durations) == 0 {
+		return Latencies{}
+	}
+	sorted := append([]time.Duration{}, durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var total time.Duration
+	for _, d := range sorted {
+		total += d
+	}
+	return Latencies{
+		Count: len(sorted),
+		Min:   sorted[0],
+		Avg:   total / time.Duration(len(sorted)),
+		Max:   sorted[len(sorted)-1],
+		P50:   Percentile(sorted, 50),
+		P90:   Percentile(sorted, 90),
+		P95:   Percentile(sorted, 95),
+		P99:   Percentile(sorted, 99),
+		P999:  Percentile(sorted, 99.9),
+	}
+}
+
+// Percentile returns the nearest-rank percentile of the sorted durations
+func Percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p * float64(len(sorted)) / 100))
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > len(sorted) {
+		rank = len(sorted)
+	}
+	return sorted[rank-1]
+}
+
+// PeerSummary is the breakdown of the invocations endorsed by a peer
+type PeerSummary struct {
+	Peer         string    `json:"peer"`
+	Endorsements int       `json:"endorsements"`
+	Failed       int       `json:"failed"`
+	Endorsement  Latencies `json:"endorsement"`
+}
+
+// ErrorSummary is the breakdown of the failed invocations by error code
+type ErrorSummary struct {
+	Code   string `json:"code"`
+	Count  int    `json:"count"`
+	Sample string `json:"sample"`
+}
+
+// Summary summarizes the results of a run
+type Summary struct {
+	Invocations int           `json:"invocations"`
+	Success     int           `json:"success"`
+	Failed      int           `json:"failed"`
+	Attempts    int           `json:"attempts"`
+	Duration    time.Duration `json:"durationNs"`
+	// Rate is the number of completed invocations per second
+	Rate float64 `json:"rate"`
+	// Latency is the latency of all the invocations, the phases only cover the successful ones
+	Latency     Latencies      `json:"latency"`
+	Endorsement Latencies      `json:"endorsement"`
+	Ordering    Latencies      `json:"ordering"`
+	Commit      Latencies      `json:"commit"`
+	Peers       []PeerSummary  `json:"peers"`
+	Errors      []ErrorSummary `json:"errors"`
+	// MVCCConflicts and PhantomConflicts are the number of attempts that failed because of a read conflict,
+	// including the attempts of the invocations that succeeded on retry
+	MVCCConflicts    int `json:"mvccReadConflicts"`
+	PhantomConflicts int `json:"phantomReadConflicts"`
+}
+
+// Summary summarizes the results recorded so far, the duration of an unfinished run ends now
+func (r *Recorder) Summary() *Summary {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	end := r.end
+	if end.IsZero() {
+		end = time.Now()
+	}
+	s := &Summary{Invocations: len(r.results), Success: r.success, Failed: len(r.results) - r.success}
+	if !r.start.IsZero() {
+		s.Duration = end.Sub(r.start)
+	}
+	if s.Duration > 0 {
+		s.Rate = float64(s.Invocations) / s.Duration.Seconds()
+	}
+
+	var latencies, endorsements, orderings, commits []time.Duration
+	peerEndorsements := map[string][]time.Duration{}
+	peerFailed := map[string]int{}
+	errs := map[string]*ErrorSummary{}
+	for _, result := range r.results {
+		s.Attempts += result.Attempts
+		s.MVCCConflicts += result.MVCCConflicts
+		s.PhantomConflicts += result.PhantomConflicts
+		latencies = append(latencies, result.Latency)
+		for _, peer := range result.Endorsers {
+			peerEndorsements[peer] = append(peerEndorsements[peer], result.Endorsement)
+			if !result.Success() {
+				peerFailed[peer]++
+			}
+		}
+		if !result.Success() {
+			switch result.Code {
+			case MVCCReadConflict:
+				s.MVCCConflicts++
+			case PhantomReadConflict:
+				s.PhantomConflicts++
+			}
+			e, ok := errs[result.Code]
+			if !ok {
+				e = &ErrorSummary{Code: result.Code, Sample: result.Error}
+				errs[result.Code] = e
+			}
+			e.Count++
+			continue
+		}
+		endorsements = append(endorsements, result.Endorsement)
+		if result.Ordering > 0 {
+			orderings = append(orderings, result.Ordering)
+		}
+		if result.Commit > 0 {
+			commits = append(commits, result.Commit)
+		}
+	}
+	s.Latency = NewLatencies(latencies)
+	s.Endorsement = NewLatencies(endorsements)
+	s.Ordering = NewLatencies(orderings)
+	s.Commit = NewLatencies(commits)
+
+	for peer, durations := range peerEndorsements {
+		s.Peers = append(s.Peers, PeerSummary{
+			Peer:         peer,
+			Endorsements: len(durations),
+			Failed:       peerFailed[peer],
+			Endorsement:  NewLatencies(durations),
+		})
+	}
+	sort.Slice(s.Peers, func(i, j int) bool { return s.Peers[i].Peer < s.Peers[j].Peer })
+	for _, e := range errs {
+		s.Errors = append(s.Errors, *e)
+	}
+	sort.Slice(s.Errors, func(i, j int) bool {
+		if s.Errors[i].Count != s.Errors[j].Count {
+			return s.Errors[i].Count > s.Errors[j].Count
+		}
+		return s.Errors[i].Code < s.Errors[j].Code
+	})
+	return s
+}
@@ -0,0 +1,105 @@
+package stats
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestPercentile(t *testing.T) {
+	var durations []time.Duration
+	for i := 1000; i >= 1; i-- {
+		durations = append(durations, time.Duration(i)*time.Millisecond)
+	}
+	l := NewLatencies(durations)
+	expected := Latencies{
+		Count: 1000,
+		Min:   time.Millisecond,
+		Avg:   500500 * time.Microsecond,
+		Max:   1000 * time.Millisecond,
+		P50:   500 * time.Millisecond,
+		P90:   900 * time.Millisecond,
+		P95:   950 * time.Millisecond,
+		P99:   990 * time.Millisecond,
+		P999:  999 * time.Millisecond,
+	}
+	if l != expected {
+		t.Fatalf("expected %+v, got %+v", expected, l)
+	}
+	if NewLatencies(nil) != (Latencies{}) {
+		t.Fatal("expecting empty latencies")
+	}
+	if p := Percentile([]time.Duration{time.Second}, 99.9); p != time.Second {
+		t.Fatalf("unexpected percentile %s", p)
+	}
+}
+
+func newTestRecorder() *Recorder {
+	r := NewRecorder()
+	r.Start()
+	r.Add(&Result{TaskID: "1", Func: "move", Latency: 3 * time.Second, Endorsement: time.Second, Commit: 2 * time.Second,
+		Attempts: 1, Endorsers: []string{"peer0", "peer1"}, Code: "VALID"})
+	r.Add(&Result{TaskID: "2", Func: "move", Latency: time.Second, Endorsement: time.Second, Commit: 0,
+		Attempts: 3, Endorsers: []string{"peer0", "peer1"}, Code: "MVCC_READ_CONFLICT", Error: "mvcc"})
+	r.Add(&Result{TaskID: "3", Func: "move", Latency: time.Second, Attempts: 3, Code: "ENDORSER_SERVER_STATUS(500)", Error: "endorse"})
+	r.Add(&Result{TaskID: "4", Func: "move", Latency: time.Second, Attempts: 3, Code: "ENDORSER_SERVER_STATUS(500)", Error: "endorse"})
+	r.Stop()
+	return r
+}
+
+func TestRecorder_Summary(t *testing.T) {
+	s := newTestRecorder().Summary()
+	if s.Invocations != 4 || s.Success != 1 || s.Failed != 3 || s.Attempts != 10 {
+		t.Fatalf("unexpected summary %+v", s)
+	}
+	if s.MVCCConflicts != 1 || s.PhantomConflicts != 0 {
+		t.Fatalf("unexpected conflicts %+v", s)
+	}
+	if s.Latency.Count != 4 || s.Endorsement.Count != 1 || s.Commit.P50 != 2*time.Second {
+		t.Fatalf("unexpected latencies %+v", s)
+	}
+	if len(s.Peers) != 2 || s.Peers[0].Peer != "peer0" || s.Peers[0].Endorsements != 2 || s.Peers[0].Failed != 1 {
+		t.Fatalf("unexpected peers %+v", s.Peers)
+	}
+	if len(s.Errors) != 2 || s.Errors[0].Code != "ENDORSER_SERVER_STATUS(500)" || s.Errors[0].Count != 2 {
+		t.Fatalf("unexpected errors %+v", s.Errors)
+	}
+	s.Print()
+}
+
+func TestRecorder_WriteReport(t *testing.T) {
+	r := newTestRecorder()
+
+	buf := bytes.NewBuffer(nil)
+	if err := r.WriteReport(buf, JSON); err != nil {
+		t.Fatal(err)
+	}
+	var report struct {
+		Summary Summary   `json:"summary"`
+		Results []*Result `json:"results"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+	if report.Summary.Invocations != 4 || len(report.Results) != 4 || report.Results[0].Commit != 2*time.Second {
+		t.Fatalf("unexpected json report %s", buf.String())
+	}
+
+	buf.Reset()
+	if err := r.WriteReport(buf, CSV); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 5 || records[1][4] != "3000.000" || records[1][8] != "peer0;peer1" {
+		t.Fatalf("unexpected csv report %v", records)
+	}
+
+	if err := r.WriteReport(buf, "xml"); err == nil {
+		t.Fatal("expecting unsupported format error")
+	}
+}